@@ -194,6 +194,7 @@ func (cli *Client) dispatchAppState(mutation appstate.Mutation, fullSync bool, e
 		if mutation.Index[4] != "0" {
 			evt.SenderJID, _ = types.ParseJID(mutation.Index[4])
 		}
+		cli.trackDeleteForMe(&evt, dispatchEvts)
 		eventToDispatch = &evt
 	case appstate.IndexMarkChatAsRead:
 		eventToDispatch = &events.MarkChatAsRead{
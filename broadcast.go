@@ -9,8 +9,11 @@ package whatsmeow
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
 	"github.com/pbribeiro/whatsmeow-mysql/types"
 )
 
@@ -20,7 +23,7 @@ func (cli *Client) getBroadcastListParticipants(jid types.JID) ([]types.JID, err
 	if jid == types.StatusBroadcastJID {
 		list, err = cli.getStatusBroadcastRecipients()
 	} else {
-		return nil, ErrBroadcastListUnsupported
+		list, err = cli.getStoredBroadcastListRecipients(jid)
 	}
 	if err != nil {
 		return nil, err
@@ -43,6 +46,102 @@ func (cli *Client) getBroadcastListParticipants(jid types.JID) ([]types.JID, err
 	return list, nil
 }
 
+func (cli *Client) getStoredBroadcastListRecipients(jid types.JID) ([]types.JID, error) {
+	list, err := cli.Store.BroadcastLists.GetBroadcastList(jid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast list from db: %w", err)
+	} else if list == nil {
+		return nil, ErrBroadcastListNotFound
+	}
+	return list.Recipients, nil
+}
+
+// CreateBroadcastList creates a new broadcast list with the given name and recipients.
+//
+// Broadcast lists only exist locally: they're stored in the device store and not synced to the server or other devices.
+// Messages sent to the list (using SendMessage with the returned JID) show up as normal 1:1 messages for recipients.
+func (cli *Client) CreateBroadcastList(name string, recipients []types.JID) (*store.BroadcastList, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	list := store.BroadcastList{
+		JID:        types.NewJID(strconv.FormatInt(time.Now().UnixMilli(), 10), types.BroadcastServer),
+		Name:       name,
+		Recipients: make([]types.JID, len(recipients)),
+		CreatedAt:  time.Now(),
+	}
+	for i, recipient := range recipients {
+		list.Recipients[i] = recipient.ToNonAD()
+	}
+	err := cli.Store.BroadcastLists.PutBroadcastList(list)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetBroadcastList gets the info of a broadcast list created with CreateBroadcastList.
+func (cli *Client) GetBroadcastList(jid types.JID) (*store.BroadcastList, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	list, err := cli.Store.BroadcastLists.GetBroadcastList(jid)
+	if err != nil {
+		return nil, err
+	} else if list == nil {
+		return nil, ErrBroadcastListNotFound
+	}
+	return list, nil
+}
+
+// GetAllBroadcastLists gets all broadcast lists created with CreateBroadcastList.
+func (cli *Client) GetAllBroadcastLists() ([]store.BroadcastList, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	return cli.Store.BroadcastLists.GetAllBroadcastLists()
+}
+
+// RenameBroadcastList changes the name of a broadcast list.
+func (cli *Client) RenameBroadcastList(jid types.JID, name string) error {
+	if _, err := cli.GetBroadcastList(jid); err != nil {
+		return err
+	}
+	return cli.Store.BroadcastLists.RenameBroadcastList(jid, name)
+}
+
+// UpdateBroadcastListRecipients adds and removes recipients in a broadcast list.
+func (cli *Client) UpdateBroadcastListRecipients(jid types.JID, add, remove []types.JID) error {
+	if _, err := cli.GetBroadcastList(jid); err != nil {
+		return err
+	}
+	if len(add) > 0 {
+		normalized := make([]types.JID, len(add))
+		for i, recipient := range add {
+			normalized[i] = recipient.ToNonAD()
+		}
+		err := cli.Store.BroadcastLists.AddBroadcastListRecipients(jid, normalized)
+		if err != nil {
+			return fmt.Errorf("failed to add recipients: %w", err)
+		}
+	}
+	if len(remove) > 0 {
+		err := cli.Store.BroadcastLists.RemoveBroadcastListRecipients(jid, remove)
+		if err != nil {
+			return fmt.Errorf("failed to remove recipients: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteBroadcastList deletes a broadcast list. Messages that were already sent are not affected.
+func (cli *Client) DeleteBroadcastList(jid types.JID) error {
+	if cli == nil {
+		return ErrClientIsNil
+	}
+	return cli.Store.BroadcastLists.DeleteBroadcastList(jid)
+}
+
 func (cli *Client) getStatusBroadcastRecipients() ([]types.JID, error) {
 	statusPrivacyOptions, err := cli.GetStatusPrivacy()
 	if err != nil {
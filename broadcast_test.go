@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+type memoryBroadcastListStore struct {
+	lists map[types.JID]store.BroadcastList
+}
+
+func (m *memoryBroadcastListStore) PutBroadcastList(list store.BroadcastList) error {
+	m.lists[list.JID] = list
+	return nil
+}
+
+func (m *memoryBroadcastListStore) RenameBroadcastList(jid types.JID, name string) error {
+	list := m.lists[jid]
+	list.Name = name
+	m.lists[jid] = list
+	return nil
+}
+
+func (m *memoryBroadcastListStore) DeleteBroadcastList(jid types.JID) error {
+	delete(m.lists, jid)
+	return nil
+}
+
+func (m *memoryBroadcastListStore) AddBroadcastListRecipients(jid types.JID, recipients []types.JID) error {
+	list := m.lists[jid]
+	for _, recipient := range recipients {
+		if !slices.Contains(list.Recipients, recipient) {
+			list.Recipients = append(list.Recipients, recipient)
+		}
+	}
+	m.lists[jid] = list
+	return nil
+}
+
+func (m *memoryBroadcastListStore) RemoveBroadcastListRecipients(jid types.JID, recipients []types.JID) error {
+	list := m.lists[jid]
+	list.Recipients = slices.DeleteFunc(list.Recipients, func(recipient types.JID) bool {
+		return slices.Contains(recipients, recipient)
+	})
+	m.lists[jid] = list
+	return nil
+}
+
+func (m *memoryBroadcastListStore) GetBroadcastList(jid types.JID) (*store.BroadcastList, error) {
+	list, ok := m.lists[jid]
+	if !ok {
+		return nil, nil
+	}
+	list.Recipients = slices.Clone(list.Recipients)
+	return &list, nil
+}
+
+func (m *memoryBroadcastListStore) GetAllBroadcastLists() ([]store.BroadcastList, error) {
+	lists := make([]store.BroadcastList, 0, len(m.lists))
+	for _, list := range m.lists {
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+func newBroadcastTestClient() *Client {
+	ownID := types.JID{User: "1000", Device: 2, Server: types.DefaultUserServer}
+	device := *store.NoopDevice
+	device.ID = &ownID
+	device.BroadcastLists = &memoryBroadcastListStore{lists: make(map[types.JID]store.BroadcastList)}
+	return NewClient(&device, waLog.Noop)
+}
+
+func TestBroadcastListCRUD(t *testing.T) {
+	cli := newBroadcastTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	bob := types.NewJID("2222", types.DefaultUserServer)
+	carol := types.NewJID("3333", types.DefaultUserServer)
+
+	list, err := cli.CreateBroadcastList("Friends", []types.JID{{User: "1111", Device: 5, Server: types.DefaultUserServer}, bob})
+	if err != nil {
+		t.Fatalf("failed to create list: %v", err)
+	} else if !list.JID.IsBroadcastList() {
+		t.Fatalf("expected a broadcast list JID, got %s", list.JID)
+	}
+	if err = cli.RenameBroadcastList(list.JID, "Best friends"); err != nil {
+		t.Fatalf("failed to rename list: %v", err)
+	}
+	if err = cli.UpdateBroadcastListRecipients(list.JID, []types.JID{{User: "3333", Device: 1, Server: types.DefaultUserServer}}, []types.JID{bob}); err != nil {
+		t.Fatalf("failed to update recipients: %v", err)
+	}
+	got, err := cli.GetBroadcastList(list.JID)
+	if err != nil {
+		t.Fatalf("failed to get list: %v", err)
+	} else if got.Name != "Best friends" {
+		t.Errorf("expected renamed list, got name %q", got.Name)
+	} else if expected := []types.JID{alice, carol}; !slices.Equal(got.Recipients, expected) {
+		t.Errorf("expected recipients %v without device IDs, got %v", expected, got.Recipients)
+	}
+	if all, err := cli.GetAllBroadcastLists(); err != nil || len(all) != 1 {
+		t.Errorf("expected exactly one list, got %v (error: %v)", all, err)
+	}
+
+	if err = cli.DeleteBroadcastList(list.JID); err != nil {
+		t.Fatalf("failed to delete list: %v", err)
+	}
+	if _, err = cli.GetBroadcastList(list.JID); !errors.Is(err, ErrBroadcastListNotFound) {
+		t.Errorf("expected ErrBroadcastListNotFound after deleting, got %v", err)
+	}
+	if err = cli.RenameBroadcastList(list.JID, "Gone"); !errors.Is(err, ErrBroadcastListNotFound) {
+		t.Errorf("expected renaming a deleted list to fail, got %v", err)
+	}
+}
+
+func TestSendToBroadcastListSendsDMs(t *testing.T) {
+	cli := newBroadcastTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	bob := types.NewJID("2222", types.DefaultUserServer)
+	list, err := cli.CreateBroadcastList("Friends", []types.JID{alice, bob, cli.getOwnID().ToNonAD()})
+	if err != nil {
+		t.Fatalf("failed to create list: %v", err)
+	}
+	resp, err := cli.SendMessage(context.Background(), list.JID, &waE2E.Message{Conversation: proto.String("Hello")}, SendRequestExtra{ID: "msg-1"})
+	// There's no websocket, so every DM fails, but each recipient must be attempted separately
+	if !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("expected sending to fail without a connection, got %v", err)
+	} else if resp.ID != "msg-1" {
+		t.Errorf("expected response to have the requested ID, got %q", resp.ID)
+	}
+	errs := err.(interface{ Unwrap() []error }).Unwrap()
+	if len(errs) != 2 {
+		t.Fatalf("expected one error per recipient excluding ourselves, got %v", errs)
+	}
+	for i, recipient := range []types.JID{alice, bob} {
+		if !strings.Contains(errs[i].Error(), recipient.String()) {
+			t.Errorf("expected error #%d to be for %s, got %v", i+1, recipient, errs[i])
+		}
+	}
+}
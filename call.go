@@ -7,7 +7,15 @@
 package whatsmeow
 
 import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+
 	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
 	"github.com/pbribeiro/whatsmeow-mysql/types"
 	"github.com/pbribeiro/whatsmeow-mysql/types/events"
 )
@@ -30,14 +38,28 @@ func (cli *Client) handleCallEvent(node *waBinary.Node) {
 	}
 	switch child.Tag {
 	case "offer":
-		cli.dispatchEvent(&events.CallOffer{
+		offer, encNode := parseCallOffer(&child, cli.getOwnID())
+		if encNode != nil && cli.DecryptCallKeys {
+			var err error
+			offer.CallKey, err = cli.decryptCallKey(basicMeta.From, encNode)
+			if err != nil {
+				cli.Log.Warnf("Failed to decrypt key of call %s from %s: %v", basicMeta.CallID, basicMeta.From, err)
+			}
+		}
+		evt := &events.CallOffer{
 			BasicCallMeta: basicMeta,
 			CallRemoteMeta: types.CallRemoteMeta{
 				RemotePlatform: ag.String("platform"),
 				RemoteVersion:  ag.String("version"),
 			},
-			Data: &child,
-		})
+			Data:  &child,
+			Offer: offer,
+		}
+		cli.dispatchEvent(evt)
+		// Offers that were queued while we were offline have stopped ringing already
+		if _, offline := node.Attrs["offline"]; !offline {
+			go cli.applyCallPolicy(evt)
+		}
 	case "offer_notice":
 		cli.dispatchEvent(&events.CallOfferNotice{
 			BasicCallMeta: basicMeta,
@@ -48,6 +70,7 @@ func (cli *Client) handleCallEvent(node *waBinary.Node) {
 	case "relaylatency":
 		cli.dispatchEvent(&events.CallRelayLatency{
 			BasicCallMeta: basicMeta,
+			Endpoints:     parseCallRelayEndpoints(child.GetChildrenByTag("te")),
 			Data:          &child,
 		})
 	case "accept":
@@ -110,3 +133,267 @@ func (cli *Client) RejectCall(callFrom types.JID, callID string) error {
 		}},
 	})
 }
+
+func parseCallOffer(node *waBinary.Node, ownID types.JID) (offer *types.CallOfferData, encNode *waBinary.Node) {
+	ag := node.AttrGetter()
+	offer = &types.CallOfferData{
+		Joinable: ag.OptionalString("joinable") == "1",
+		GroupJID: ag.OptionalJIDOrEmpty("group-jid"),
+	}
+	for _, child := range node.GetChildren() {
+		cag := child.AttrGetter()
+		switch child.Tag {
+		case "audio":
+			offer.Audio = append(offer.Audio, types.CallAudioCodec{
+				Encoding: cag.OptionalString("enc"),
+				Rate:     cag.OptionalInt("rate"),
+			})
+		case "video":
+			offer.Video = &types.CallVideoCodec{
+				Encoding: cag.OptionalString("enc"),
+				Decoding: cag.OptionalString("dec"),
+			}
+		case "net":
+			offer.NetMedium = cag.OptionalInt("medium")
+		case "encopt":
+			offer.KeyGenVersion = cag.OptionalInt("keygen")
+		case "capability":
+			offer.CapabilityVersion = cag.OptionalInt("ver")
+			offer.Capability, _ = child.Content.([]byte)
+		case "relay":
+			offer.Relay = parseCallRelay(&child)
+		case "enc":
+			encNode = &child
+		case "destination":
+			// Offers to multiple devices contain a separately encrypted key for each device
+			for _, to := range child.GetChildrenByTag("to") {
+				if to.AttrGetter().OptionalJIDOrEmpty("jid") != ownID {
+					continue
+				}
+				if enc, ok := to.GetOptionalChildByTag("enc"); ok {
+					encNode = &enc
+				}
+			}
+		}
+	}
+	return
+}
+
+func parseCallRelay(node *waBinary.Node) *types.CallRelay {
+	relay := &types.CallRelay{
+		UUID:       node.AttrGetter().OptionalString("uuid"),
+		Tokens:     make(map[int][]byte),
+		AuthTokens: make(map[int][]byte),
+	}
+	for _, child := range node.GetChildren() {
+		content, _ := child.Content.([]byte)
+		switch child.Tag {
+		case "key":
+			relay.Key = content
+		case "hbh_key":
+			relay.HopByHop = content
+		case "token":
+			relay.Tokens[child.AttrGetter().OptionalInt("id")] = content
+		case "auth_token":
+			relay.AuthTokens[child.AttrGetter().OptionalInt("id")] = content
+		}
+	}
+	relay.Endpoints = parseCallRelayEndpoints(node.GetChildrenByTag("te2"))
+	return relay
+}
+
+func parseCallRelayEndpoints(nodes []waBinary.Node) []types.CallRelayEndpoint {
+	endpoints := make([]types.CallRelayEndpoint, 0, len(nodes))
+	for _, node := range nodes {
+		ag := node.AttrGetter()
+		content, _ := node.Content.([]byte)
+		latency, _ := ag.GetUint64("latency", false)
+		endpoint := types.CallRelayEndpoint{
+			RelayID: ag.OptionalInt("relay_id"),
+			TokenID: ag.OptionalInt("token_id"),
+			Latency: uint32(latency),
+		}
+		var ok bool
+		endpoint.Address, ok = parseCallRelayAddress(content)
+		if !ok {
+			endpoint.RawAddress = content
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// parseCallRelayAddress parses a packed relay address, which is a 4 or 16 byte IP address followed by a 2 byte port.
+func parseCallRelayAddress(data []byte) (netip.AddrPort, bool) {
+	if len(data) != 6 && len(data) != 18 {
+		return netip.AddrPort{}, false
+	}
+	addr, ok := netip.AddrFromSlice(data[:len(data)-2])
+	if !ok {
+		return netip.AddrPort{}, false
+	}
+	return netip.AddrPortFrom(addr, binary.BigEndian.Uint16(data[len(data)-2:])), true
+}
+
+func packCallRelayAddress(addr netip.AddrPort) []byte {
+	return binary.BigEndian.AppendUint16(addr.Addr().AsSlice(), addr.Port())
+}
+
+func (cli *Client) decryptCallKey(from types.JID, encNode *waBinary.Node) ([]byte, error) {
+	encType := encNode.AttrGetter().OptionalString("type")
+	if encType != "pkmsg" && encType != "msg" {
+		return nil, fmt.Errorf("unsupported call key encryption type %q", encType)
+	}
+	plaintext, err := cli.decryptDM(encNode, from, encType == "pkmsg")
+	if err != nil {
+		return nil, err
+	}
+	var msg waE2E.Message
+	err = proto.Unmarshal(plaintext, &msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal call key message: %w", err)
+	} else if len(msg.GetCall().GetCallKey()) == 0 {
+		return nil, fmt.Errorf("call key message doesn't contain a key")
+	}
+	return msg.GetCall().GetCallKey(), nil
+}
+
+// CallMediaParams contains the media parameters sent when pre-accepting or accepting a call.
+//
+// The values depend on the media stack that will handle the call. If Audio is empty, Opus at 16 kHz and 8 kHz is used.
+type CallMediaParams struct {
+	Audio             []types.CallAudioCodec
+	Video             *types.CallVideoCodec
+	NetMedium         int
+	KeyGenVersion     int
+	Capability        []byte
+	CapabilityVersion int
+
+	// Additional child nodes to include, for any parameters not covered by the fields above.
+	Extra []waBinary.Node
+}
+
+var defaultCallAudioCodecs = []types.CallAudioCodec{{Encoding: "opus", Rate: 16000}, {Encoding: "opus", Rate: 8000}}
+
+func (params *CallMediaParams) toNodes() []waBinary.Node {
+	audio := params.Audio
+	if len(audio) == 0 {
+		audio = defaultCallAudioCodecs
+	}
+	nodes := make([]waBinary.Node, 0, len(audio)+4+len(params.Extra))
+	for _, codec := range audio {
+		nodes = append(nodes, waBinary.Node{
+			Tag:   "audio",
+			Attrs: waBinary.Attrs{"enc": codec.Encoding, "rate": strconv.Itoa(codec.Rate)},
+		})
+	}
+	if params.Video != nil {
+		nodes = append(nodes, waBinary.Node{
+			Tag:   "video",
+			Attrs: waBinary.Attrs{"enc": params.Video.Encoding, "dec": params.Video.Decoding},
+		})
+	}
+	if params.NetMedium != 0 {
+		nodes = append(nodes, waBinary.Node{
+			Tag:   "net",
+			Attrs: waBinary.Attrs{"medium": strconv.Itoa(params.NetMedium)},
+		})
+	}
+	if params.Capability != nil {
+		nodes = append(nodes, waBinary.Node{
+			Tag:     "capability",
+			Attrs:   waBinary.Attrs{"ver": strconv.Itoa(params.CapabilityVersion)},
+			Content: params.Capability,
+		})
+	}
+	if params.KeyGenVersion != 0 {
+		nodes = append(nodes, waBinary.Node{
+			Tag:   "encopt",
+			Attrs: waBinary.Attrs{"keygen": strconv.Itoa(params.KeyGenVersion)},
+		})
+	}
+	return append(nodes, params.Extra...)
+}
+
+func (cli *Client) sendCallNode(to, callCreator types.JID, callID, tag string, attrs waBinary.Attrs, content []waBinary.Node) error {
+	ownID := cli.getOwnID()
+	if ownID.IsEmpty() {
+		return ErrNotLoggedIn
+	}
+	if attrs == nil {
+		attrs = waBinary.Attrs{}
+	}
+	attrs["call-id"] = callID
+	attrs["call-creator"] = callCreator.ToNonAD()
+	var nodeContent any
+	if len(content) > 0 {
+		nodeContent = content
+	}
+	return cli.sendNode(waBinary.Node{
+		Tag:   "call",
+		Attrs: waBinary.Attrs{"id": cli.GenerateMessageID(), "from": ownID.ToNonAD(), "to": to},
+		Content: []waBinary.Node{{
+			Tag:     tag,
+			Attrs:   attrs,
+			Content: nodeContent,
+		}},
+	})
+}
+
+// PreAcceptCall tells the caller that this device received the call offer and is ringing.
+//
+// The call parameters come from the *events.CallOffer. The media parameters should match what the media stack supports.
+func (cli *Client) PreAcceptCall(callFrom types.JID, callID string, params CallMediaParams) error {
+	return cli.sendCallNode(callFrom, callFrom, callID, "preaccept", nil, params.toNodes())
+}
+
+// AcceptCall accepts an incoming call.
+//
+// The media itself isn't handled by whatsmeow: the media stack should use the call key and relays
+// from the parsed offer (events.CallOffer.Offer) to connect to the call after accepting it.
+func (cli *Client) AcceptCall(callFrom types.JID, callID string, params CallMediaParams) error {
+	return cli.sendCallNode(callFrom, callFrom, callID, "accept", nil, params.toNodes())
+}
+
+// TerminateCall ends an ongoing call.
+//
+// The call creator is the user who started the call, i.e. the other user for incoming calls and us for outgoing calls.
+// The reason is optional.
+func (cli *Client) TerminateCall(peer, callCreator types.JID, callID, reason string) error {
+	var attrs waBinary.Attrs
+	if reason != "" {
+		attrs = waBinary.Attrs{"reason": reason}
+	}
+	return cli.sendCallNode(peer, callCreator, callID, "terminate", attrs, nil)
+}
+
+// SendCallRelayLatency reports the measured latencies to the relays of a call.
+//
+// The relay and token IDs should be copied from the offered relays (types.CallRelay.Endpoints),
+// and the latencies filled in by the media stack.
+func (cli *Client) SendCallRelayLatency(peer, callCreator types.JID, callID string, endpoints []types.CallRelayEndpoint) error {
+	nodes := make([]waBinary.Node, len(endpoints))
+	for i, endpoint := range endpoints {
+		address := endpoint.RawAddress
+		if endpoint.Address.IsValid() {
+			address = packCallRelayAddress(endpoint.Address)
+		}
+		nodes[i] = waBinary.Node{
+			Tag: "te",
+			Attrs: waBinary.Attrs{
+				"latency":  strconv.FormatUint(uint64(endpoint.Latency), 10),
+				"relay_id": strconv.Itoa(endpoint.RelayID),
+				"token_id": strconv.Itoa(endpoint.TokenID),
+			},
+			Content: address,
+		}
+	}
+	return cli.sendCallNode(peer, callCreator, callID, "relaylatency", nil, nodes)
+}
+
+// SendCallTransport sends transport information of a call, such as network candidates, to the other party.
+//
+// The contents are specific to the media stack, so they're passed through as-is.
+func (cli *Client) SendCallTransport(peer, callCreator types.JID, callID string, content []waBinary.Node) error {
+	return cli.sendCallNode(peer, callCreator, callID, "transport", nil, content)
+}
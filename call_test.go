@@ -0,0 +1,208 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+
+	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+type warnRecorder struct {
+	waLog.Logger
+	warnings []string
+}
+
+func (w *warnRecorder) Warnf(msg string, args ...any) {
+	w.warnings = append(w.warnings, fmt.Sprintf(msg, args...))
+}
+
+// roundTripNode encodes and decodes the node in the binary XML format, so that attributes and contents
+// have the same types as they would when received from the server.
+func roundTripNode(t *testing.T, node waBinary.Node) *waBinary.Node {
+	t.Helper()
+	data, err := waBinary.Marshal(node)
+	if err != nil {
+		t.Fatalf("failed to marshal node: %v", err)
+	}
+	decoded, err := waBinary.Unmarshal(data[1:])
+	if err != nil {
+		t.Fatalf("failed to unmarshal node: %v", err)
+	}
+	return decoded
+}
+
+func TestParseCallOffer(t *testing.T) {
+	ownID := types.NewADJID("1234567890", 0, 2)
+	otherDevice := types.NewADJID("1234567890", 0, 0)
+	ownEnc := waBinary.Node{Tag: "enc", Attrs: waBinary.Attrs{"v": "2", "type": "pkmsg"}, Content: []byte("own key")}
+	offerNode := roundTripNode(t, waBinary.Node{
+		Tag: "offer",
+		Attrs: waBinary.Attrs{
+			"call-id":      "5B3C1D2E4F",
+			"call-creator": types.NewJID("9876543210", types.DefaultUserServer),
+		},
+		Content: []waBinary.Node{
+			{Tag: "audio", Attrs: waBinary.Attrs{"enc": "opus", "rate": "16000"}},
+			{Tag: "audio", Attrs: waBinary.Attrs{"enc": "opus", "rate": "8000"}},
+			{Tag: "video", Attrs: waBinary.Attrs{"enc": "vp8", "dec": "vp8"}},
+			{Tag: "net", Attrs: waBinary.Attrs{"medium": "3"}},
+			{Tag: "capability", Attrs: waBinary.Attrs{"ver": "1"}, Content: []byte{0x01, 0x04, 0xff}},
+			{Tag: "encopt", Attrs: waBinary.Attrs{"keygen": "2"}},
+			{Tag: "destination", Content: []waBinary.Node{
+				{Tag: "to", Attrs: waBinary.Attrs{"jid": otherDevice}, Content: []waBinary.Node{
+					{Tag: "enc", Attrs: waBinary.Attrs{"v": "2", "type": "msg"}, Content: []byte("other key")},
+				}},
+				{Tag: "to", Attrs: waBinary.Attrs{"jid": ownID}, Content: []waBinary.Node{ownEnc}},
+			}},
+			{Tag: "relay", Attrs: waBinary.Attrs{"uuid": "relay-uuid"}, Content: []waBinary.Node{
+				{Tag: "key", Content: []byte("relay key")},
+				{Tag: "hbh_key", Content: []byte("hbh key")},
+				{Tag: "token", Attrs: waBinary.Attrs{"id": "0"}, Content: []byte("token 0")},
+				{Tag: "token", Attrs: waBinary.Attrs{"id": "1"}, Content: []byte("token 1")},
+				{Tag: "auth_token", Attrs: waBinary.Attrs{"id": "0"}, Content: []byte("auth 0")},
+				{Tag: "te2", Attrs: waBinary.Attrs{"relay_id": "0", "token_id": "1"}, Content: []byte{157, 240, 1, 53, 13, 150}},
+				{Tag: "te2", Attrs: waBinary.Attrs{"relay_id": "1", "token_id": "0"}, Content: []byte{1, 2, 3}},
+			}},
+		},
+	})
+
+	offer, encNode := parseCallOffer(offerNode, ownID)
+	if encNode == nil {
+		t.Fatal("expected encrypted key node for own device")
+	} else if content, _ := encNode.Content.([]byte); !bytes.Equal(content, []byte("own key")) {
+		t.Errorf("got key node for wrong device: %q", content)
+	}
+	if len(offer.Audio) != 2 || offer.Audio[0] != (types.CallAudioCodec{Encoding: "opus", Rate: 16000}) || offer.Audio[1].Rate != 8000 {
+		t.Errorf("unexpected audio codecs: %+v", offer.Audio)
+	}
+	if !offer.IsVideo() || *offer.Video != (types.CallVideoCodec{Encoding: "vp8", Decoding: "vp8"}) {
+		t.Errorf("unexpected video codec: %+v", offer.Video)
+	}
+	if offer.NetMedium != 3 || offer.KeyGenVersion != 2 || offer.CapabilityVersion != 1 || !bytes.Equal(offer.Capability, []byte{0x01, 0x04, 0xff}) {
+		t.Errorf("unexpected media parameters: %+v", offer)
+	}
+	relay := offer.Relay
+	if relay == nil {
+		t.Fatal("expected relay to be parsed")
+	}
+	if relay.UUID != "relay-uuid" || string(relay.Key) != "relay key" || string(relay.HopByHop) != "hbh key" {
+		t.Errorf("unexpected relay metadata: %+v", relay)
+	}
+	if len(relay.Tokens) != 2 || string(relay.Tokens[1]) != "token 1" || string(relay.AuthTokens[0]) != "auth 0" {
+		t.Errorf("unexpected relay tokens: %+v / %+v", relay.Tokens, relay.AuthTokens)
+	}
+	if len(relay.Endpoints) != 2 {
+		t.Fatalf("expected 2 relay endpoints, got %d", len(relay.Endpoints))
+	}
+	if expected := netip.MustParseAddrPort("157.240.1.53:3478"); relay.Endpoints[0].Address != expected || relay.Endpoints[0].TokenID != 1 {
+		t.Errorf("unexpected first endpoint: %+v", relay.Endpoints[0])
+	}
+	if relay.Endpoints[1].Address.IsValid() || !bytes.Equal(relay.Endpoints[1].RawAddress, []byte{1, 2, 3}) {
+		t.Errorf("expected unparseable address to be kept raw: %+v", relay.Endpoints[1])
+	}
+}
+
+func TestParseCallOfferWithoutKey(t *testing.T) {
+	offerNode := roundTripNode(t, waBinary.Node{
+		Tag:     "offer",
+		Attrs:   waBinary.Attrs{"call-id": "5B3C1D2E4F", "joinable": "1"},
+		Content: []waBinary.Node{{Tag: "audio", Attrs: waBinary.Attrs{"enc": "opus", "rate": "16000"}}},
+	})
+	offer, encNode := parseCallOffer(offerNode, types.NewADJID("1234567890", 0, 2))
+	if encNode != nil {
+		t.Errorf("expected no key node, got %s", encNode.XMLString())
+	}
+	if offer.IsVideo() || offer.Relay != nil || !offer.Joinable {
+		t.Errorf("unexpected offer: %+v", offer)
+	}
+}
+
+func TestCallKeyDecryptionIsOptIn(t *testing.T) {
+	ownID := types.NewADJID("1234567890", 0, 2)
+	device := *store.NoopDevice
+	device.ID = &ownID
+	cli := NewClient(&device, waLog.Noop)
+	cli.SynchronousAck = true
+	log := &warnRecorder{Logger: waLog.Noop}
+	cli.Log = log
+	var offers []*events.CallOffer
+	cli.AddEventHandler(func(evt any) {
+		if offer, ok := evt.(*events.CallOffer); ok {
+			offers = append(offers, offer)
+		}
+	})
+	callNode := roundTripNode(t, waBinary.Node{
+		Tag:   "call",
+		Attrs: waBinary.Attrs{"from": types.NewADJID("9876543210", 0, 0), "id": "1", "t": "1700000000"},
+		Content: []waBinary.Node{{
+			Tag:     "offer",
+			Attrs:   waBinary.Attrs{"call-id": "5B3C1D2E4F", "call-creator": types.NewADJID("9876543210", 0, 0)},
+			Content: []waBinary.Node{{Tag: "enc", Attrs: waBinary.Attrs{"v": "2", "type": "msg"}, Content: []byte("not a signal message")}},
+		}},
+	})
+	countDecryptAttempts := func() (count int) {
+		for _, warning := range log.warnings {
+			if strings.HasPrefix(warning, "Failed to decrypt key") {
+				count++
+			}
+		}
+		return
+	}
+
+	cli.handleCallEvent(callNode)
+	if len(offers) != 1 || offers[0].Offer.CallKey != nil {
+		t.Fatalf("expected one offer without a key, got %+v", offers)
+	} else if attempts := countDecryptAttempts(); attempts != 0 {
+		t.Errorf("expected call key not to be decrypted by default, got %d attempts", attempts)
+	}
+	cli.DecryptCallKeys = true
+	cli.handleCallEvent(callNode)
+	if attempts := countDecryptAttempts(); attempts != 1 {
+		t.Errorf("expected call key to be decrypted when enabled, got %d attempts", attempts)
+	}
+}
+
+func TestCallRelayAddress(t *testing.T) {
+	for _, addr := range []string{"157.240.1.53:3478", "[2a03:2880:f200::1]:3480"} {
+		parsed := netip.MustParseAddrPort(addr)
+		packed := packCallRelayAddress(parsed)
+		unpacked, ok := parseCallRelayAddress(packed)
+		if !ok || unpacked != parsed {
+			t.Errorf("%s: round trip through %x resulted in %s", addr, packed, unpacked)
+		}
+	}
+}
+
+func TestCallMediaParamsNodes(t *testing.T) {
+	defaults := (&CallMediaParams{}).toNodes()
+	if len(defaults) != 2 || defaults[0].Tag != "audio" || defaults[0].Attrs["rate"] != "16000" {
+		t.Errorf("unexpected default nodes: %+v", defaults)
+	}
+	params := CallMediaParams{
+		Audio:         []types.CallAudioCodec{{Encoding: "opus", Rate: 16000}},
+		NetMedium:     3,
+		KeyGenVersion: 2,
+		Extra:         []waBinary.Node{{Tag: "custom"}},
+	}
+	var tags []string
+	for _, node := range params.toNodes() {
+		tags = append(tags, node.Tag)
+	}
+	if expected := []string{"audio", "net", "encopt", "custom"}; !slices.Equal(tags, expected) {
+		t.Errorf("expected tags %v, got %v", expected, tags)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+// DefaultCallAutoReplyInterval is the minimum time between automatic replies to the same caller
+// if CallPolicy.AutoReplyInterval is not set.
+const DefaultCallAutoReplyInterval = 1 * time.Hour
+
+// maxCallPolicyOfferAge is the maximum age of a call offer for CallPolicy to be applied.
+// Older offers have most likely stopped ringing already.
+const maxCallPolicyOfferAge = 1 * time.Minute
+
+// CallPolicy configures automatic handling of incoming calls. See Client.CallPolicy.
+type CallPolicy struct {
+	// Calls from these users are never rejected.
+	Allowlist []types.JID
+
+	// If set, calls are only rejected outside these business hours. The time zone is an IANA time zone name,
+	// and both fields are in the same format as in types.BusinessProfile, so the business profile can be used directly:
+	//
+	//	profile, _ := cli.GetBusinessProfile(ownJID)
+	//	cli.CallPolicy = &whatsmeow.CallPolicy{BusinessHours: profile.BusinessHours, BusinessHoursTimeZone: profile.BusinessHoursTimeZone}
+	BusinessHours         []types.BusinessHoursConfig
+	BusinessHoursTimeZone string
+
+	// A text message to send to the caller after rejecting a call. If empty, no message is sent.
+	AutoReplyText string
+	// The minimum time between automatic replies to the same caller. Defaults to DefaultCallAutoReplyInterval.
+	AutoReplyInterval time.Duration
+}
+
+func (cp *CallPolicy) isAllowlisted(caller types.JID) bool {
+	caller = caller.ToNonAD()
+	for _, jid := range cp.Allowlist {
+		if jid.ToNonAD() == caller {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithinBusinessHours checks if the given time is within the configured business hours.
+//
+// The open and close times of each day are minutes since midnight. If the close time is before the open time,
+// the business is open past midnight, and the hours after midnight belong to the previous day's config.
+// Days without any config are considered closed, as are appointment-only days,
+// because there's nobody to answer calls without an appointment.
+func (cp *CallPolicy) isWithinBusinessHours(now time.Time) (bool, error) {
+	if cp.BusinessHoursTimeZone != "" {
+		loc, err := time.LoadLocation(cp.BusinessHoursTimeZone)
+		if err != nil {
+			return false, fmt.Errorf("failed to load business hours time zone: %w", err)
+		}
+		now = now.In(loc)
+	}
+	today := businessHoursDay(now.Weekday())
+	yesterday := businessHoursDay((now.Weekday() + 6) % 7)
+	minutes := now.Hour()*60 + now.Minute()
+	for _, config := range cp.BusinessHours {
+		if config.DayOfWeek != today && config.DayOfWeek != yesterday {
+			continue
+		}
+		switch config.Mode {
+		case "open_24h":
+			if config.DayOfWeek == today {
+				return true, nil
+			}
+		case "specific_hours":
+			var openTime, closeTime int
+			if _, err := fmt.Sscan(config.OpenTime, &openTime); err != nil {
+				return false, fmt.Errorf("failed to parse open time %q: %w", config.OpenTime, err)
+			} else if _, err = fmt.Sscan(config.CloseTime, &closeTime); err != nil {
+				return false, fmt.Errorf("failed to parse close time %q: %w", config.CloseTime, err)
+			}
+			if config.DayOfWeek == today {
+				if minutes >= openTime && (minutes < closeTime || openTime > closeTime) {
+					return true, nil
+				}
+			} else if openTime > closeTime && minutes < closeTime {
+				// Yesterday's hours continue past midnight
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func businessHoursDay(day time.Weekday) string {
+	return strings.ToLower(day.String()[:3])
+}
+
+func (cli *Client) applyCallPolicy(offer *events.CallOffer) {
+	policy := cli.CallPolicy
+	if policy == nil {
+		return
+	} else if time.Since(offer.Timestamp) > maxCallPolicyOfferAge {
+		cli.Log.Debugf("Not applying call policy to call %s from %s: offer is from %s", offer.CallID, offer.From, offer.Timestamp)
+		return
+	}
+	caller := offer.CallCreator.ToNonAD()
+	if caller.IsEmpty() {
+		caller = offer.From.ToNonAD()
+	}
+	evt := &events.CallPolicyApplied{BasicCallMeta: offer.BasicCallMeta, Action: events.CallPolicyAllowed}
+	if policy.isAllowlisted(caller) {
+		evt.Reason = events.CallPolicyReasonAllowlisted
+		cli.dispatchEvent(evt)
+		return
+	}
+	if len(policy.BusinessHours) > 0 {
+		open, err := policy.isWithinBusinessHours(time.Now())
+		if err != nil {
+			cli.Log.Warnf("Failed to check business hours for call %s from %s: %v", offer.CallID, offer.From, err)
+			evt.Reason = events.CallPolicyReasonBusinessHours
+			evt.Error = err
+			cli.dispatchEvent(evt)
+			return
+		} else if open {
+			evt.Reason = events.CallPolicyReasonBusinessHours
+			cli.dispatchEvent(evt)
+			return
+		}
+		evt.Reason = events.CallPolicyReasonOutsideBusinessHours
+	} else {
+		evt.Reason = events.CallPolicyReasonAlways
+	}
+
+	evt.Action = events.CallPolicyRejected
+	evt.Error = cli.RejectCall(offer.From, offer.CallID)
+	if evt.Error != nil {
+		cli.Log.Warnf("Failed to reject call %s from %s: %v", offer.CallID, offer.From, evt.Error)
+	} else if policy.AutoReplyText != "" {
+		if cli.shouldSendCallAutoReply(caller, policy.AutoReplyInterval) {
+			_, evt.Error = cli.SendMessage(context.TODO(), caller, &waE2E.Message{Conversation: proto.String(policy.AutoReplyText)})
+			if evt.Error != nil {
+				cli.Log.Warnf("Failed to send automatic reply to call %s from %s: %v", offer.CallID, caller, evt.Error)
+			} else {
+				evt.Replied = true
+			}
+		} else {
+			evt.ReplyRateLimited = true
+		}
+	}
+	cli.dispatchEvent(evt)
+}
+
+// shouldSendCallAutoReply checks if enough time has passed since the last automatic reply to the given caller,
+// and marks a reply as sent if it has.
+func (cli *Client) shouldSendCallAutoReply(caller types.JID, interval time.Duration) bool {
+	if interval == 0 {
+		interval = DefaultCallAutoReplyInterval
+	}
+	cli.callAutoRepliesLock.Lock()
+	defer cli.callAutoRepliesLock.Unlock()
+	if time.Since(cli.callAutoReplies[caller]) < interval {
+		return false
+	}
+	// Forget callers whose interval has passed, so the map doesn't grow forever
+	for jid, repliedAt := range cli.callAutoReplies {
+		if time.Since(repliedAt) >= interval {
+			delete(cli.callAutoReplies, jid)
+		}
+	}
+	cli.callAutoReplies[caller] = time.Now()
+	return true
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+func TestIsWithinBusinessHours(t *testing.T) {
+	weekdays := []types.BusinessHoursConfig{
+		{DayOfWeek: "mon", Mode: "specific_hours", OpenTime: "540", CloseTime: "1020"},
+		{DayOfWeek: "tue", Mode: "open_24h"},
+		{DayOfWeek: "wed", Mode: "appointment_only"},
+		{DayOfWeek: "fri", Mode: "specific_hours", OpenTime: "1200", CloseTime: "120"},
+	}
+	// 2025-01-06 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.January, 6+day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected bool
+	}{
+		{"before opening", at(0, 8, 59), false},
+		{"at opening", at(0, 9, 0), true},
+		{"before closing", at(0, 16, 59), true},
+		{"at closing", at(0, 17, 0), false},
+		{"open 24h at midnight", at(1, 0, 0), true},
+		{"open 24h late", at(1, 23, 59), true},
+		{"open 24h doesn't continue to next day", at(2, 0, 30), false},
+		{"appointment only", at(2, 12, 0), false},
+		{"missing day", at(3, 12, 0), false},
+		{"missing day with hours the day after", at(3, 23, 0), false},
+		{"past midnight before opening", at(4, 19, 59), false},
+		{"past midnight before midnight", at(4, 23, 0), true},
+		{"past midnight after midnight", at(5, 1, 0), true},
+		{"past midnight after closing", at(5, 2, 0), false},
+		{"past midnight of missing day", at(4, 1, 0), false},
+		{"missing weekend", at(6, 12, 0), false},
+	}
+	policy := &CallPolicy{BusinessHours: weekdays}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			open, err := policy.isWithinBusinessHours(test.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if open != test.expected {
+				t.Errorf("expected open=%t at %s, got %t", test.expected, test.now.Format("Mon 15:04"), open)
+			}
+		})
+	}
+}
+
+func TestIsWithinBusinessHoursTimeZone(t *testing.T) {
+	policy := &CallPolicy{
+		BusinessHours:         []types.BusinessHoursConfig{{DayOfWeek: "mon", Mode: "specific_hours", OpenTime: "540", CloseTime: "1020"}},
+		BusinessHoursTimeZone: "Etc/GMT-3",
+	}
+	// 07:00 UTC is 10:00 in UTC+3
+	if open, err := policy.isWithinBusinessHours(time.Date(2025, time.January, 6, 7, 0, 0, 0, time.UTC)); err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	} else if !open {
+		t.Error("expected business hours to be checked in the configured time zone")
+	}
+	policy.BusinessHoursTimeZone = "Invalid/Zone"
+	if _, err := policy.isWithinBusinessHours(time.Now()); err == nil {
+		t.Error("expected error for invalid time zone")
+	}
+}
+
+func newCallPolicyTestClient() (*Client, *[]*events.CallPolicyApplied) {
+	ownID := types.JID{User: "1000", Device: 2, Server: types.DefaultUserServer}
+	device := *store.NoopDevice
+	device.ID = &ownID
+	cli := NewClient(&device, waLog.Noop)
+	cli.CallPolicy = &CallPolicy{}
+	var evts []*events.CallPolicyApplied
+	cli.AddEventHandler(func(evt any) {
+		if applied, ok := evt.(*events.CallPolicyApplied); ok {
+			evts = append(evts, applied)
+		}
+	})
+	return cli, &evts
+}
+
+func TestCallPolicyIgnoresStaleOffers(t *testing.T) {
+	cli, evts := newCallPolicyTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	cli.applyCallPolicy(&events.CallOffer{BasicCallMeta: types.BasicCallMeta{
+		From:      alice,
+		Timestamp: time.Now().Add(-5 * time.Minute),
+		CallID:    "old-call",
+	}})
+	if len(*evts) != 0 {
+		t.Fatalf("expected stale offer to be ignored, got %+v", *evts)
+	}
+	cli.applyCallPolicy(&events.CallOffer{BasicCallMeta: types.BasicCallMeta{
+		From:      alice,
+		Timestamp: time.Now(),
+		CallID:    "new-call",
+	}})
+	// There's no websocket, so rejecting the call fails, but the policy is still applied
+	if len(*evts) != 1 || (*evts)[0].CallID != "new-call" || (*evts)[0].Action != events.CallPolicyRejected {
+		t.Errorf("expected policy to be applied to the new offer, got %+v", *evts)
+	}
+}
+
+func TestCallAutoRepliesPruned(t *testing.T) {
+	cli, _ := newCallPolicyTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	bob := types.NewJID("2222", types.DefaultUserServer)
+	carol := types.NewJID("3333", types.DefaultUserServer)
+	cli.callAutoReplies[alice] = time.Now().Add(-2 * time.Hour)
+	cli.callAutoReplies[bob] = time.Now().Add(-10 * time.Minute)
+
+	if !cli.shouldSendCallAutoReply(carol, time.Hour) {
+		t.Fatal("expected reply to new caller to be allowed")
+	} else if _, ok := cli.callAutoReplies[alice]; ok {
+		t.Error("expected expired caller to be pruned")
+	} else if len(cli.callAutoReplies) != 2 {
+		t.Errorf("expected recent callers to be kept, got %+v", cli.callAutoReplies)
+	}
+	if cli.shouldSendCallAutoReply(bob, time.Hour) {
+		t.Error("expected reply to recent caller to be rate limited")
+	}
+}
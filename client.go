@@ -66,6 +66,7 @@ type Client struct {
 
 	isLoggedIn            atomic.Bool
 	expectedDisconnect    atomic.Bool
+	shuttingDown          atomic.Bool
 	EnableAutoReconnect   bool
 	LastSuccessfulConnect time.Time
 	AutoReconnectErrors   int
@@ -99,13 +100,68 @@ type Client struct {
 	mediaConnCache *MediaConn
 	mediaConnLock  sync.Mutex
 
+	mediaRetryWaiters     map[types.MessageID][]chan<- *events.MediaRetry
+	mediaRetryWaitersLock sync.Mutex
+
+	lastStatusPrune atomic.Int64
+
+	scheduledMessageWake      chan struct{}
+	scheduledMessageLocks     map[types.MessageID]*scheduledMessageLock
+	scheduledMessageLocksLock sync.Mutex
+
+	decryptionFailures             sync.Map // decryptionFailureKey -> *types.DecryptionFailure
+	decryptionFailuresLoaded       atomic.Bool
+	decryptionFailuresLoadErr      error
+	decryptionFailuresLoadFailedAt time.Time
+	decryptionStats                map[types.DecryptionFailureCause]*types.DecryptionRecoveryStats
+	decryptionFailuresLock         sync.Mutex
+
+	presenceSubscriptions map[types.JID]struct{}
+	presences             map[types.JID]types.UserPresence
+	typingStates          map[types.JID]map[types.JID]types.TypingState
+	presenceLock          sync.Mutex
+
+	callAutoReplies     map[types.JID]time.Time
+	callAutoRepliesLock sync.Mutex
+
 	responseWaiters     map[string]chan<- *waBinary.Node
 	responseWaitersLock sync.Mutex
 
-	nodeHandlers      map[string]nodeHandler
-	handlerQueue      chan *waBinary.Node
-	eventHandlers     []wrappedEventHandler
-	eventHandlersLock sync.RWMutex
+	nodeHandlers          map[string]nodeHandler
+	handlerQueue          chan *waBinary.Node
+	handlerQueueOverflows atomic.Uint64
+	pendingNodes          atomic.Int64
+	pendingAcks           atomic.Int64
+	pendingReceipts       atomic.Int64
+	nodeDispatcher        *nodeDispatcher
+	nodeDispatcherLock    sync.Mutex
+	lidMappings           map[types.JID]types.JID
+	lidMappingsList       []types.JID
+	lidMappingsPtr        int
+	lidMappingsLock       sync.RWMutex
+	trafficRecorder       atomic.Pointer[TrafficRecorder]
+	eventHandlers         []wrappedEventHandler
+	eventHandlersLock     sync.RWMutex
+
+	// ConcurrentNodeWorkers enables handling incoming nodes from different chats concurrently.
+	// Nodes within the same chat are still always handled in the order they were received,
+	// and slow handlers are never detached like in the default sequential mode.
+	//
+	// Connection state nodes (success, failure and stream errors) are handled on their own after all
+	// previously received nodes, so chat nodes never race ahead of them. Info queries from the server are handled
+	// in a separate lane that doesn't count towards the limit, so that slow chat handlers can't delay pings.
+	//
+	// The value is the maximum number of chats whose nodes are handled at the same time.
+	// Zero (the default) means all nodes are handled one at a time. Changes take effect on the next Connect.
+	ConcurrentNodeWorkers int
+
+	// RateLimiter paces outgoing messages, presences, IsOnWhatsApp and group queries if set. See NewRateLimiter for details.
+	// SendMessage waits for the rate limiter as long as its context allows, while the other methods wait for at most
+	// RateLimitConfig.MaxQueryWait and then fail with ErrRateLimited.
+	RateLimiter *RateLimiter
+	// MediaCache is used by Upload and Download to reuse uploaded and downloaded media if set.
+	// See NewFilesystemMediaCache for a default implementation.
+	MediaCache MediaCache
 
 	messageRetries     map[string]int
 	messageRetriesLock sync.Mutex
@@ -155,6 +211,30 @@ type Client struct {
 	// Should SubscribePresence return an error if no privacy token is stored for the user?
 	ErrorOnSubscribePresenceWithoutToken bool
 
+	// Should every received presence update be stored in the database? See GetPresenceHistory.
+	StorePresenceHistory bool
+	// PresenceHistoryRetention is how long stored presence updates are kept. Older updates are deleted periodically
+	// while connected. Zero disables pruning. Defaults to DefaultPresenceHistoryRetention.
+	PresenceHistoryRetention time.Duration
+
+	// MessageStatusRetention is how long the receipts of sent messages are kept for GetMessageStatus.
+	// Older statuses are deleted periodically while connected. Zero disables pruning.
+	// Defaults to DefaultMessageStatusRetention.
+	MessageStatusRetention time.Duration
+
+	// Should the call key in incoming call offers be decrypted? This is only needed by media stacks that actually
+	// answer calls, and is disabled by default, as decrypting the key advances the Signal session with the caller.
+	// If enabled, the key is available in the Offer.CallKey field of events.CallOffer.
+	DecryptCallKeys bool
+
+	// CallPolicy can be set to reject incoming calls automatically, optionally only outside business hours
+	// and with an automatic text reply. An events.CallPolicyApplied is dispatched after each call offer.
+	// Offers received while offline or more than a minute late are ignored, as they're no longer ringing.
+	CallPolicy *CallPolicy
+
+	// ViewOncePolicy configures how view-once media downloaded with DownloadViewOnce is handled.
+	ViewOncePolicy *ViewOncePolicy
+
 	phoneLinkingCache *phoneLinkingCache
 
 	uniqueID  string
@@ -223,6 +303,14 @@ func NewClient(deviceStore *store.Device, log waLog.Logger) *Client {
 		socketWait:      make(chan struct{}),
 
 		incomingRetryRequestCounter: make(map[incomingRetryKey]int),
+		mediaRetryWaiters:           make(map[types.MessageID][]chan<- *events.MediaRetry),
+		scheduledMessageWake:        make(chan struct{}, 1),
+		scheduledMessageLocks:       make(map[types.MessageID]*scheduledMessageLock),
+		decryptionStats:             make(map[types.DecryptionFailureCause]*types.DecryptionRecoveryStats),
+		presenceSubscriptions:       make(map[types.JID]struct{}),
+		presences:                   make(map[types.JID]types.UserPresence),
+		typingStates:                make(map[types.JID]map[types.JID]types.TypingState),
+		callAutoReplies:             make(map[types.JID]time.Time),
 
 		historySyncNotifications: make(chan *waE2E.HistorySyncNotification, 32),
 
@@ -236,8 +324,10 @@ func NewClient(deviceStore *store.Device, log waLog.Logger) *Client {
 
 		pendingPhoneRerequests: make(map[types.MessageID]context.CancelFunc),
 
-		EnableAutoReconnect: true,
-		AutoTrustIdentity:   true,
+		EnableAutoReconnect:      true,
+		AutoTrustIdentity:        true,
+		MessageStatusRetention:   DefaultMessageStatusRetention,
+		PresenceHistoryRetention: DefaultPresenceHistoryRetention,
 	}
 	cli.nodeHandlers = map[string]nodeHandler{
 		"message":      cli.handleEncryptedMessage,
@@ -426,6 +516,7 @@ func (cli *Client) Connect() error {
 	}
 
 	cli.resetExpectedDisconnect()
+	cli.shuttingDown.Store(false)
 	var wsDialer websocket.Dialer
 	if cli.wsDialer != nil {
 		wsDialer = *cli.wsDialer
@@ -632,6 +723,8 @@ func (cli *Client) Logout() error {
 //	func (mycli *MyClient) myEventHandler(evt interface{}) {
 //		// Handle event and access mycli.WAClient
 //	}
+//
+// If you only want events of a specific type, Subscribe provides a typed alternative with filters and buffering.
 func (cli *Client) AddEventHandler(handler EventHandler) uint32 {
 	nextID := atomic.AddUint32(&nextHandlerID, 1)
 	cli.eventHandlersLock.Lock()
@@ -693,6 +786,7 @@ func (cli *Client) handleFrame(data []byte) {
 		return
 	}
 	cli.recvLog.Debugf("%s", node.XMLString())
+	cli.recordTraffic(TrafficIncoming, node, data)
 	if node.Tag == "xmlstreamend" {
 		if !cli.isExpectedDisconnect() {
 			cli.Log.Warnf("Received stream end frame")
@@ -701,9 +795,16 @@ func (cli *Client) handleFrame(data []byte) {
 	} else if cli.receiveResponse(node) {
 		// handled
 	} else if _, ok := cli.nodeHandlers[node.Tag]; ok {
+		if cli.shuttingDown.Load() {
+			// The node isn't acknowledged, so the server will send it again after the next connect.
+			cli.Log.Debugf("Ignoring %s node received during shutdown", node.Tag)
+			return
+		}
+		cli.pendingNodes.Add(1)
 		select {
 		case cli.handlerQueue <- node:
 		default:
+			cli.handlerQueueOverflows.Add(1)
 			cli.Log.Warnf("Handler queue is full, message ordering is no longer guaranteed")
 			go func() {
 				cli.handlerQueue <- node
@@ -724,6 +825,10 @@ func stopAndDrainTimer(timer *time.Timer) {
 }
 
 func (cli *Client) handlerQueueLoop(ctx context.Context) {
+	if cli.ConcurrentNodeWorkers > 0 {
+		cli.concurrentHandlerQueueLoop(ctx)
+		return
+	}
 	timer := time.NewTimer(5 * time.Minute)
 	stopAndDrainTimer(timer)
 	cli.Log.Debugf("Starting handler queue loop")
@@ -732,13 +837,8 @@ func (cli *Client) handlerQueueLoop(ctx context.Context) {
 		case node := <-cli.handlerQueue:
 			doneChan := make(chan struct{}, 1)
 			go func() {
-				start := time.Now()
-				cli.nodeHandlers[node.Tag](node)
-				duration := time.Since(start)
+				cli.runNodeHandler(node)
 				doneChan <- struct{}{}
-				if duration > 5*time.Second {
-					cli.Log.Warnf("Node handling took %s for %s", duration, node.XMLString())
-				}
 			}()
 			timer.Reset(5 * time.Minute)
 			select {
@@ -757,6 +857,8 @@ func (cli *Client) handlerQueueLoop(ctx context.Context) {
 func (cli *Client) sendNodeAndGetData(node waBinary.Node) ([]byte, error) {
 	if cli == nil {
 		return nil, ErrClientIsNil
+	} else if cli.shuttingDown.Load() && !isAllowedDuringShutdown(&node) {
+		return nil, ErrClientShuttingDown
 	}
 	cli.socketLock.RLock()
 	sock := cli.socket
@@ -771,6 +873,7 @@ func (cli *Client) sendNodeAndGetData(node waBinary.Node) ([]byte, error) {
 	}
 
 	cli.sendLog.Debugf("%s", node.XMLString())
+	cli.recordTraffic(TrafficOutgoing, &node, payload)
 	return payload, sock.SendFrame(payload)
 }
 
@@ -843,6 +946,7 @@ func (cli *Client) ParseWebMessage(chatJID types.JID, webMsg *waWeb.WebMessageIn
 		Info:         info,
 	}
 	evt.UnwrapRaw()
+	markViewOnceMedia(evt)
 	if evt.Message.GetProtocolMessage().GetType() == waE2E.ProtocolMessage_MESSAGE_EDIT {
 		evt.Info.ID = evt.Message.GetProtocolMessage().GetKey().GetID()
 		evt.Message = evt.Message.GetProtocolMessage().GetEditedMessage()
@@ -124,10 +124,14 @@ func (cli *Client) handleConnectFailure(node *waBinary.Node) {
 		}
 	} else if reason == events.ConnectFailureTempBanned {
 		cli.Log.Warnf("Temporary ban connect failure: %s", node.XMLString())
-		go cli.dispatchEvent(&events.TemporaryBan{
+		evt := &events.TemporaryBan{
 			Code:   events.TempBanReason(ag.Int("code")),
 			Expire: time.Duration(ag.Int("expire")) * time.Second,
-		})
+		}
+		if cli.RateLimiter != nil {
+			cli.throttleRateLimiter("temporary ban", evt.Expire+cli.RateLimiter.config.ThrottleDuration)
+		}
+		go cli.dispatchEvent(evt)
 	} else if reason == events.ConnectFailureClientOutdated {
 		cli.Log.Errorf("Client outdated (405) connect failure (client version: %s)", store.GetWAVersion().String())
 		go cli.dispatchEvent(&events.ClientOutdated{})
@@ -180,6 +184,17 @@ func (cli *Client) handleConnectSuccess(node *waBinary.Node) {
 		}
 		cli.dispatchEvent(&events.Connected{})
 		cli.closeSocketWaitChan()
+		cli.socketLock.RLock()
+		sock := cli.socket
+		cli.socketLock.RUnlock()
+		if sock != nil {
+			go cli.scheduledMessageLoop(sock.Context())
+			go cli.decryptionRecoveryLoop(sock.Context())
+			go cli.disappearingMessageLoop(sock.Context())
+			go cli.messageStatusPruneLoop(sock.Context())
+			go cli.presenceHistoryPruneLoop(sock.Context())
+		}
+		cli.resubscribePresences()
 	}()
 }
 
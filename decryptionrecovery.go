@@ -0,0 +1,279 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mau.fi/libsignal/signalerror"
+
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+// DecryptionRecoveryTimeout specifies how long to wait for an undecryptable message to be resent
+// before giving up and emitting *events.DecryptionGaveUp.
+var DecryptionRecoveryTimeout = 1 * time.Hour
+
+const decryptionRecoverySweepInterval = 5 * time.Minute
+
+// GetDecryptionRecoveryStats returns counters of undecryptable messages, grouped by failure cause.
+//
+// Messages that fail to decrypt are tracked through retry receipts and requests to the primary device
+// until they're either received successfully (*events.DecryptionRecovered) or the client gives up (*events.DecryptionGaveUp).
+// Both the tracking state and the counters are stored in the database, so they continue after restarts.
+func (cli *Client) GetDecryptionRecoveryStats() map[types.DecryptionFailureCause]types.DecryptionRecoveryStats {
+	if cli == nil {
+		return nil
+	}
+	cli.decryptionFailuresLock.Lock()
+	defer cli.decryptionFailuresLock.Unlock()
+	_ = cli.loadDecryptionFailures()
+	stats := make(map[types.DecryptionFailureCause]types.DecryptionRecoveryStats, len(cli.decryptionStats))
+	for cause, stat := range cli.decryptionStats {
+		stats[cause] = *stat
+	}
+	return stats
+}
+
+// GetPendingDecryptionFailures returns the messages that failed to decrypt and are still waiting to be recovered.
+func (cli *Client) GetPendingDecryptionFailures() ([]types.DecryptionFailure, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	cli.decryptionFailuresLock.Lock()
+	defer cli.decryptionFailuresLock.Unlock()
+	if err := cli.loadDecryptionFailures(); err != nil {
+		return nil, err
+	}
+	var failures []types.DecryptionFailure
+	cli.decryptionFailures.Range(func(_, value any) bool {
+		failures = append(failures, *value.(*types.DecryptionFailure))
+		return true
+	})
+	return failures, nil
+}
+
+func getDecryptionFailureCause(err error) types.DecryptionFailureCause {
+	switch {
+	case errors.Is(err, signalerror.ErrNoSenderKeyForUser),
+		errors.Is(err, signalerror.ErrNoSenderKeyStatesInRecord),
+		errors.Is(err, signalerror.ErrNoSenderKeyStateForID):
+		return types.DecryptionFailureNoSenderKey
+	case errors.Is(err, signalerror.ErrNoSessionForUser),
+		errors.Is(err, signalerror.ErrNoValidSessions),
+		errors.Is(err, signalerror.ErrUninitializedSession):
+		return types.DecryptionFailureNoSession
+	case errors.Is(err, signalerror.ErrBadMAC):
+		return types.DecryptionFailureBadMAC
+	case errors.Is(err, signalerror.ErrUntrustedIdentity):
+		return types.DecryptionFailureUntrustedIdentity
+	default:
+		return types.DecryptionFailureOther
+	}
+}
+
+type decryptionFailureKey struct {
+	Chat   types.JID
+	Sender types.JID
+	ID     types.MessageID
+}
+
+func makeDecryptionFailureKey(chat, sender types.JID, id types.MessageID) decryptionFailureKey {
+	// Placeholder resends from the phone don't have the device ID of the original sender
+	return decryptionFailureKey{Chat: chat.ToNonAD(), Sender: sender.ToNonAD(), ID: id}
+}
+
+// incrementDecryptionStats adds the given deltas to the counters of the given cause in memory and in the database.
+// The caller must hold decryptionFailuresLock.
+func (cli *Client) incrementDecryptionStats(cause types.DecryptionFailureCause, delta types.DecryptionRecoveryStats) {
+	stats, ok := cli.decryptionStats[cause]
+	if !ok {
+		stats = &types.DecryptionRecoveryStats{}
+		cli.decryptionStats[cause] = stats
+	}
+	stats.Failed += delta.Failed
+	stats.Recovered += delta.Recovered
+	stats.GaveUp += delta.GaveUp
+	err := cli.Store.DecryptionFailures.IncrementDecryptionStats(cause, delta)
+	if err != nil {
+		cli.Log.Warnf("Failed to store decryption recovery counters: %v", err)
+	}
+}
+
+// loadDecryptionFailures loads the pending failures and counters from the database if they haven't been loaded yet.
+// If loading fails, the same error is returned without retrying until decryptionRecoverySweepInterval has passed,
+// so that a broken database doesn't cause a query and a warning for every incoming message.
+// The caller must hold decryptionFailuresLock.
+func (cli *Client) loadDecryptionFailures() error {
+	if cli.decryptionFailuresLoaded.Load() {
+		return nil
+	} else if cli.decryptionFailuresLoadErr != nil && time.Since(cli.decryptionFailuresLoadFailedAt) < decryptionRecoverySweepInterval {
+		return cli.decryptionFailuresLoadErr
+	}
+	failures, err := cli.Store.DecryptionFailures.GetAllDecryptionFailures()
+	var stats map[types.DecryptionFailureCause]types.DecryptionRecoveryStats
+	if err == nil {
+		stats, err = cli.Store.DecryptionFailures.GetDecryptionStats()
+	}
+	if err != nil {
+		cli.decryptionFailuresLoadErr = fmt.Errorf("failed to load pending decryption failures: %w", err)
+		cli.decryptionFailuresLoadFailedAt = time.Now()
+		cli.Log.Warnf("%v", cli.decryptionFailuresLoadErr)
+		return cli.decryptionFailuresLoadErr
+	}
+	for _, failure := range failures {
+		cli.decryptionFailures.Store(makeDecryptionFailureKey(failure.Chat, failure.Sender, failure.ID), &failure)
+	}
+	for cause, stat := range stats {
+		cli.decryptionStats[cause] = &stat
+	}
+	cli.decryptionFailuresLoadErr = nil
+	cli.decryptionFailuresLoaded.Store(true)
+	return nil
+}
+
+// updateDecryptionFailure calls the given function with the pending failure for the given message and saves the changes.
+// If there's no pending failure and create is false, the function isn't called.
+func (cli *Client) updateDecryptionFailure(info *types.MessageInfo, create bool, fn func(failure *types.DecryptionFailure)) {
+	cli.decryptionFailuresLock.Lock()
+	defer cli.decryptionFailuresLock.Unlock()
+	if cli.loadDecryptionFailures() != nil {
+		return
+	}
+	key := makeDecryptionFailureKey(info.Chat, info.Sender, info.ID)
+	var failure *types.DecryptionFailure
+	if existing, ok := cli.decryptionFailures.Load(key); ok {
+		failure = existing.(*types.DecryptionFailure)
+	} else if !create {
+		return
+	} else {
+		failure = &types.DecryptionFailure{
+			Chat:     key.Chat,
+			Sender:   key.Sender,
+			ID:       key.ID,
+			FailedAt: time.Now(),
+		}
+	}
+	fn(failure)
+	err := cli.Store.DecryptionFailures.PutDecryptionFailure(*failure)
+	if err != nil {
+		cli.Log.Warnf("Failed to store decryption failure state of %s: %v", info.ID, err)
+	}
+	cli.decryptionFailures.Store(key, failure)
+}
+
+func (cli *Client) trackDecryptionFailure(info *types.MessageInfo, cause types.DecryptionFailureCause) {
+	cli.updateDecryptionFailure(info, true, func(failure *types.DecryptionFailure) {
+		if failure.Failures == 0 {
+			failure.Cause = cause
+			cli.incrementDecryptionStats(cause, types.DecryptionRecoveryStats{Failed: 1})
+		}
+		failure.Failures++
+	})
+}
+
+func (cli *Client) trackRetryReceiptSent(info *types.MessageInfo) {
+	cli.updateDecryptionFailure(info, false, func(failure *types.DecryptionFailure) {
+		failure.RetryReceipts++
+	})
+}
+
+func (cli *Client) trackPhoneRequestSent(info *types.MessageInfo) {
+	cli.updateDecryptionFailure(info, false, func(failure *types.DecryptionFailure) {
+		failure.PhoneRequested = true
+	})
+}
+
+// trackRetriesExhausted gives up on a message after the last retry receipt if it won't be requested from the phone.
+func (cli *Client) trackRetriesExhausted(info *types.MessageInfo) {
+	if cli.AutomaticMessageRerequestFromPhone && cli.MessengerConfig == nil {
+		return
+	}
+	cli.finishDecryptionFailure(makeDecryptionFailureKey(info.Chat, info.Sender, info.ID), false, func(failure types.DecryptionFailure) any {
+		return &events.DecryptionGaveUp{DecryptionFailure: failure, Reason: events.DecryptionGaveUpRetriesExhausted}
+	})
+}
+
+// trackDecryptionSuccess marks a previously undecryptable message as recovered.
+func (cli *Client) trackDecryptionSuccess(info *types.MessageInfo, method events.DecryptionRecoveryMethod) {
+	key := makeDecryptionFailureKey(info.Chat, info.Sender, info.ID)
+	// This is called for every incoming message, so avoid the lock unless the message is known to have failed
+	// (or the pending failures haven't been loaded yet).
+	if _, pending := cli.decryptionFailures.Load(key); !pending && cli.decryptionFailuresLoaded.Load() {
+		return
+	}
+	cli.finishDecryptionFailure(key, true, func(failure types.DecryptionFailure) any {
+		return &events.DecryptionRecovered{DecryptionFailure: failure, Method: method}
+	})
+}
+
+// finishDecryptionFailure stops tracking the given message and dispatches the event returned by makeEvent.
+func (cli *Client) finishDecryptionFailure(key decryptionFailureKey, recovered bool, makeEvent func(failure types.DecryptionFailure) any) {
+	cli.decryptionFailuresLock.Lock()
+	if cli.loadDecryptionFailures() != nil {
+		cli.decryptionFailuresLock.Unlock()
+		return
+	}
+	value, ok := cli.decryptionFailures.LoadAndDelete(key)
+	if !ok {
+		cli.decryptionFailuresLock.Unlock()
+		return
+	}
+	failure := value.(*types.DecryptionFailure)
+	if recovered {
+		cli.incrementDecryptionStats(failure.Cause, types.DecryptionRecoveryStats{Recovered: 1})
+	} else {
+		cli.incrementDecryptionStats(failure.Cause, types.DecryptionRecoveryStats{GaveUp: 1})
+	}
+	err := cli.Store.DecryptionFailures.DeleteDecryptionFailure(key.Chat, key.Sender, key.ID)
+	cli.decryptionFailuresLock.Unlock()
+	if err != nil {
+		cli.Log.Warnf("Failed to delete decryption failure state of %s: %v", key.ID, err)
+	}
+	cli.dispatchEvent(makeEvent(*failure))
+}
+
+// decryptionRecoveryLoop periodically gives up on undecryptable messages that haven't been recovered
+// within DecryptionRecoveryTimeout, until the given (socket) context is cancelled.
+func (cli *Client) decryptionRecoveryLoop(ctx context.Context) {
+	ticker := time.NewTicker(decryptionRecoverySweepInterval)
+	defer ticker.Stop()
+	for {
+		cli.sweepDecryptionFailures()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (cli *Client) sweepDecryptionFailures() {
+	cli.decryptionFailuresLock.Lock()
+	if cli.loadDecryptionFailures() != nil {
+		cli.decryptionFailuresLock.Unlock()
+		return
+	}
+	var expired []decryptionFailureKey
+	cli.decryptionFailures.Range(func(key, value any) bool {
+		if time.Since(value.(*types.DecryptionFailure).FailedAt) > DecryptionRecoveryTimeout {
+			expired = append(expired, key.(decryptionFailureKey))
+		}
+		return true
+	})
+	cli.decryptionFailuresLock.Unlock()
+	for _, key := range expired {
+		cli.Log.Debugf("Giving up on recovering undecryptable message %s", key.ID)
+		cli.finishDecryptionFailure(key, false, func(failure types.DecryptionFailure) any {
+			return &events.DecryptionGaveUp{DecryptionFailure: failure, Reason: events.DecryptionGaveUpTimeout}
+		})
+	}
+}
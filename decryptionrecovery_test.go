@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+type memoryDecryptionFailureStore struct {
+	failures map[decryptionFailureKey]types.DecryptionFailure
+	stats    map[types.DecryptionFailureCause]types.DecryptionRecoveryStats
+	queries  atomic.Int32
+	err      error
+}
+
+func newMemoryDecryptionFailureStore() *memoryDecryptionFailureStore {
+	return &memoryDecryptionFailureStore{
+		failures: make(map[decryptionFailureKey]types.DecryptionFailure),
+		stats:    make(map[types.DecryptionFailureCause]types.DecryptionRecoveryStats),
+	}
+}
+
+func (m *memoryDecryptionFailureStore) PutDecryptionFailure(failure types.DecryptionFailure) error {
+	m.queries.Add(1)
+	m.failures[decryptionFailureKey{failure.Chat, failure.Sender, failure.ID}] = failure
+	return nil
+}
+
+func (m *memoryDecryptionFailureStore) DeleteDecryptionFailure(chat, sender types.JID, id types.MessageID) error {
+	m.queries.Add(1)
+	delete(m.failures, decryptionFailureKey{chat, sender, id})
+	return nil
+}
+
+func (m *memoryDecryptionFailureStore) GetAllDecryptionFailures() ([]types.DecryptionFailure, error) {
+	m.queries.Add(1)
+	if m.err != nil {
+		return nil, m.err
+	}
+	output := make([]types.DecryptionFailure, 0, len(m.failures))
+	for _, failure := range m.failures {
+		output = append(output, failure)
+	}
+	return output, nil
+}
+
+func (m *memoryDecryptionFailureStore) IncrementDecryptionStats(cause types.DecryptionFailureCause, delta types.DecryptionRecoveryStats) error {
+	m.queries.Add(1)
+	stats := m.stats[cause]
+	stats.Failed += delta.Failed
+	stats.Recovered += delta.Recovered
+	stats.GaveUp += delta.GaveUp
+	m.stats[cause] = stats
+	return nil
+}
+
+func (m *memoryDecryptionFailureStore) GetDecryptionStats() (map[types.DecryptionFailureCause]types.DecryptionRecoveryStats, error) {
+	m.queries.Add(1)
+	output := make(map[types.DecryptionFailureCause]types.DecryptionRecoveryStats, len(m.stats))
+	for cause, stats := range m.stats {
+		output[cause] = stats
+	}
+	return output, nil
+}
+
+func newDecryptionRecoveryTestClient(failures *memoryDecryptionFailureStore) (*Client, *[]any) {
+	ownID := types.JID{User: "1000", Device: 2, Server: types.DefaultUserServer}
+	device := *store.NoopDevice
+	device.ID = &ownID
+	device.DecryptionFailures = failures
+	cli := NewClient(&device, waLog.Noop)
+	var evts []any
+	cli.AddEventHandler(func(evt any) {
+		evts = append(evts, evt)
+	})
+	return cli, &evts
+}
+
+func TestDecryptionRecoveryKeyedBySender(t *testing.T) {
+	failures := newMemoryDecryptionFailureStore()
+	cli, evts := newDecryptionRecoveryTestClient(failures)
+	group := types.NewJID("123456789", types.GroupServer)
+	alice := types.JID{User: "1111", Device: 3, Server: types.DefaultUserServer}
+	bob := types.NewJID("2222", types.DefaultUserServer)
+	failed := &types.MessageInfo{ID: "msg-1", MessageSource: types.MessageSource{Chat: group, Sender: alice, IsGroup: true}}
+	cli.trackDecryptionFailure(failed, types.DecryptionFailureNoSenderKey)
+
+	// A different sender can use the same message ID
+	cli.trackDecryptionSuccess(&types.MessageInfo{ID: "msg-1", MessageSource: types.MessageSource{Chat: group, Sender: bob, IsGroup: true}}, events.DecryptionRecoveredByResend)
+	if len(*evts) != 0 {
+		t.Fatalf("expected message from another sender not to recover the failure, got %+v", *evts)
+	}
+	// Placeholder resends from the phone don't include the sender device
+	cli.trackDecryptionSuccess(&types.MessageInfo{ID: "msg-1", MessageSource: types.MessageSource{Chat: group, Sender: alice.ToNonAD(), IsGroup: true}}, events.DecryptionRecoveredByPhone)
+	if len(*evts) != 1 {
+		t.Fatalf("expected exactly one event, got %+v", *evts)
+	} else if recovered, ok := (*evts)[0].(*events.DecryptionRecovered); !ok || recovered.Method != events.DecryptionRecoveredByPhone {
+		t.Errorf("expected DecryptionRecovered event, got %+v", (*evts)[0])
+	} else if len(failures.failures) != 0 {
+		t.Errorf("expected recovered failure to be deleted, got %+v", failures.failures)
+	}
+}
+
+func TestDecryptionSuccessFastPath(t *testing.T) {
+	failures := newMemoryDecryptionFailureStore()
+	cli, _ := newDecryptionRecoveryTestClient(failures)
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	cli.sweepDecryptionFailures()
+	loadQueries := failures.queries.Load()
+	for i := 0; i < 100; i++ {
+		cli.trackDecryptionSuccess(&types.MessageInfo{ID: "msg-1", MessageSource: types.MessageSource{Chat: alice, Sender: alice}}, events.DecryptionRecoveredByResend)
+	}
+	if queries := failures.queries.Load(); queries != loadQueries {
+		t.Errorf("expected successful messages not to query the database, got %d queries", queries-loadQueries)
+	}
+}
+
+func TestDecryptionLoadFailureIsNotRetriedPerMessage(t *testing.T) {
+	failures := newMemoryDecryptionFailureStore()
+	failures.err = errors.New("database is down")
+	cli, _ := newDecryptionRecoveryTestClient(failures)
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	for i := 0; i < 100; i++ {
+		cli.trackDecryptionSuccess(&types.MessageInfo{ID: "msg-1", MessageSource: types.MessageSource{Chat: alice, Sender: alice}}, events.DecryptionRecoveredByResend)
+	}
+	if queries := failures.queries.Load(); queries != 1 {
+		t.Errorf("expected failed load to be retried only after the sweep interval, got %d queries", queries)
+	}
+	if _, err := cli.GetPendingDecryptionFailures(); !errors.Is(err, failures.err) {
+		t.Errorf("expected load error to be returned, got %v", err)
+	}
+}
+
+func TestDecryptionStatsPersisted(t *testing.T) {
+	failures := newMemoryDecryptionFailureStore()
+	cli, _ := newDecryptionRecoveryTestClient(failures)
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	info := &types.MessageInfo{ID: "msg-1", MessageSource: types.MessageSource{Chat: alice, Sender: alice}}
+	cli.trackDecryptionFailure(info, types.DecryptionFailureBadMAC)
+	cli.trackDecryptionFailure(info, types.DecryptionFailureBadMAC)
+	cli.trackDecryptionFailure(&types.MessageInfo{ID: "msg-2", MessageSource: info.MessageSource}, types.DecryptionFailureBadMAC)
+	cli.trackDecryptionSuccess(info, events.DecryptionRecoveredByResend)
+
+	// A new client (e.g. after a restart) continues from the stored state
+	restarted, evts := newDecryptionRecoveryTestClient(failures)
+	expected := types.DecryptionRecoveryStats{Failed: 2, Recovered: 1}
+	if stats := restarted.GetDecryptionRecoveryStats()[types.DecryptionFailureBadMAC]; stats != expected {
+		t.Errorf("expected stored counters %+v, got %+v", expected, stats)
+	}
+	restarted.trackDecryptionSuccess(&types.MessageInfo{ID: "msg-2", MessageSource: info.MessageSource}, events.DecryptionRecoveredByResend)
+	expected.Recovered++
+	if len(*evts) != 1 {
+		t.Errorf("expected pending failure to be recovered after restart, got %+v", *evts)
+	} else if stats := restarted.GetDecryptionRecoveryStats()[types.DecryptionFailureBadMAC]; stats != expected {
+		t.Errorf("expected counters %+v, got %+v", expected, stats)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/msgbuilder"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waHistorySync"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+const (
+	expiredMessageSweepInterval = 1 * time.Minute
+	expiredMessageBatchSize     = 100
+)
+
+// GetDisappearingTimer returns the disappearing message timer of the given chat, or zero if disappearing messages are off.
+//
+// Timers are learned automatically from group info, disappearing timer changes in private chats and history syncs.
+// When sending messages to chats with a timer, the expiration is set automatically unless the message already has one.
+func (cli *Client) GetDisappearingTimer(chat types.JID) (time.Duration, error) {
+	if cli == nil {
+		return 0, ErrClientIsNil
+	}
+	timer, err := cli.Store.DisappearingMessages.GetDisappearingTimer(chat)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get disappearing timer: %w", err)
+	}
+	return timer, nil
+}
+
+func (cli *Client) putDisappearingTimer(chat types.JID, timer time.Duration, settingTimestamp time.Time) {
+	updated, err := cli.Store.DisappearingMessages.PutDisappearingTimer(chat, timer, settingTimestamp)
+	if err != nil {
+		cli.Log.Warnf("Failed to store disappearing timer of %s: %v", chat, err)
+	} else if updated {
+		cli.Log.Debugf("Disappearing timer of %s is now %s", chat, timer)
+	}
+}
+
+func (cli *Client) trackGroupDisappearingTimer(group types.JID, ephemeral *types.GroupEphemeral, timestamp time.Time) {
+	var timer time.Duration
+	if ephemeral.IsEphemeral {
+		timer = time.Duration(ephemeral.DisappearingTimer) * time.Second
+	}
+	cli.putDisappearingTimer(group, timer, timestamp)
+}
+
+func (cli *Client) storeHistoricalDisappearingTimers(conversations []*waHistorySync.Conversation) {
+	for _, conv := range conversations {
+		chatJID, _ := types.ParseJID(conv.GetID())
+		if chatJID.IsEmpty() || conv.EphemeralExpiration == nil {
+			continue
+		}
+		timer := time.Duration(conv.GetEphemeralExpiration()) * time.Second
+		cli.putDisappearingTimer(chatJID, timer, time.Unix(conv.GetEphemeralSettingTimestamp(), 0))
+	}
+}
+
+// applyDisappearingTimer returns a copy of an outgoing message with the expiration set to the disappearing timer of
+// the chat. The message is returned as-is if it already has an expiration, isn't a normal message or the chat has no timer.
+//
+// The caller's message is never modified, as it may be reused for other chats or sent concurrently.
+func (cli *Client) applyDisappearingTimer(to types.JID, message *waE2E.Message) *waE2E.Message {
+	switch to.Server {
+	case types.DefaultUserServer, types.HiddenUserServer, types.GroupServer:
+	default:
+		return message
+	}
+	if message.ProtocolMessage != nil || message.ReactionMessage != nil || message.EncReactionMessage != nil ||
+		message.PollUpdateMessage != nil || msgbuilder.ContextInfoOf(message).GetExpiration() != 0 {
+		return message
+	}
+	timer, err := cli.Store.DisappearingMessages.GetDisappearingTimer(to)
+	if err != nil {
+		cli.Log.Warnf("Failed to get disappearing timer of %s: %v", to, err)
+		return message
+	} else if timer == 0 {
+		return message
+	}
+	withTimer := proto.Clone(message).(*waE2E.Message)
+	if !msgbuilder.SetExpiration(withTimer, timer) {
+		return message
+	}
+	return withTimer
+}
+
+// trackDisappearingMessage learns disappearing timer changes and stores the expiration time of disappearing messages.
+func (cli *Client) trackDisappearingMessage(evt *events.Message) {
+	if protoMsg := evt.Message.GetProtocolMessage(); protoMsg.GetType() == waE2E.ProtocolMessage_EPHEMERAL_SETTING {
+		settingTimestamp := evt.Info.Timestamp
+		if protoMsg.EphemeralSettingTimestamp != nil {
+			settingTimestamp = time.Unix(protoMsg.GetEphemeralSettingTimestamp(), 0)
+		}
+		cli.putDisappearingTimer(evt.Info.Chat, time.Duration(protoMsg.GetEphemeralExpiration())*time.Second, settingTimestamp)
+		return
+	}
+	expiration := msgbuilder.ContextInfoOf(evt.Message).GetExpiration()
+	if expiration == 0 {
+		return
+	}
+	err := cli.Store.DisappearingMessages.PutExpiringMessage(types.ExpiringMessage{
+		Chat:      evt.Info.Chat,
+		Sender:    evt.Info.Sender,
+		ID:        evt.Info.ID,
+		ExpiresAt: evt.Info.Timestamp.Add(time.Duration(expiration) * time.Second),
+	})
+	if err != nil {
+		cli.Log.Warnf("Failed to store expiration of %s from %s: %v", evt.Info.ID, evt.Info.SourceString(), err)
+	}
+}
+
+// disappearingMessageLoop periodically emits *events.MessageExpired for disappearing messages whose time has passed,
+// until the given (socket) context is cancelled.
+func (cli *Client) disappearingMessageLoop(ctx context.Context) {
+	ticker := time.NewTicker(expiredMessageSweepInterval)
+	defer ticker.Stop()
+	for {
+		cli.sweepExpiredMessages()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (cli *Client) sweepExpiredMessages() {
+	for {
+		expired, err := cli.Store.DisappearingMessages.GetExpiredMessages(time.Now(), expiredMessageBatchSize)
+		if err != nil {
+			cli.Log.Warnf("Failed to get expired messages: %v", err)
+			return
+		}
+		for _, msg := range expired {
+			err = cli.Store.DisappearingMessages.DeleteExpiringMessage(msg.Chat, msg.Sender, msg.ID)
+			if err != nil {
+				cli.Log.Warnf("Failed to delete expired message %s in %s: %v", msg.ID, msg.Chat, err)
+				return
+			}
+			cli.dispatchEvent(&events.MessageExpired{ExpiringMessage: msg})
+		}
+		if len(expired) < expiredMessageBatchSize {
+			return
+		}
+	}
+}
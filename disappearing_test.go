@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/msgbuilder"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+type fixedTimerStore struct {
+	*store.NoopStore
+	timers map[types.JID]time.Duration
+}
+
+func (f *fixedTimerStore) GetDisappearingTimer(chat types.JID) (time.Duration, error) {
+	return f.timers[chat], nil
+}
+
+func TestApplyDisappearingTimerDoesNotModifyInput(t *testing.T) {
+	withTimer := types.NewJID("1111", types.DefaultUserServer)
+	withoutTimer := types.NewJID("2222", types.DefaultUserServer)
+	cli := &Client{Log: waLog.Noop, Store: &store.Device{DisappearingMessages: &fixedTimerStore{
+		timers: map[types.JID]time.Duration{withTimer: 24 * time.Hour},
+	}}}
+	message := &waE2E.Message{Conversation: proto.String("hello")}
+	original := proto.Clone(message)
+
+	var wg sync.WaitGroup
+	results := make([]*waE2E.Message, 4)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = cli.applyDisappearingTimer(withTimer, message)
+		}()
+	}
+	wg.Wait()
+	for _, result := range results {
+		if expiration := msgbuilder.ContextInfoOf(result).GetExpiration(); expiration != 86400 {
+			t.Errorf("expected expiration of one day, got %d", expiration)
+		}
+	}
+	if !proto.Equal(message, original) {
+		t.Errorf("expected input message to be unchanged, got %v", message)
+	}
+	if result := cli.applyDisappearingTimer(withoutTimer, message); msgbuilder.ContextInfoOf(result).GetExpiration() != 0 {
+		t.Error("expected no expiration when sending the same message to a chat without a timer")
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+)
+
+// HandlerQueueStats contains backpressure metrics for the queue that incoming nodes go through before being handled.
+type HandlerQueueStats struct {
+	// The number of nodes waiting in the handler queue channel.
+	Queued int
+	// The maximum number of nodes the handler queue channel can hold before ordering is no longer guaranteed.
+	Capacity int
+	// The number of times an incoming node was received while the handler queue channel was full.
+	Overflows uint64
+
+	// The fields below are only filled when concurrent node handling is enabled (ConcurrentNodeWorkers > 0).
+
+	// The number of nodes that have been taken from the queue, but are waiting for their chat's turn to be handled.
+	Backlog int
+	// The highest Backlog value seen since the client was created.
+	MaxBacklog int
+	// The number of chats that currently have nodes being handled or waiting to be handled.
+	ActiveChats int
+	// The number of workers currently running a node handler.
+	BusyWorkers int
+	// The number of times reading from the queue had to pause because the backlog was full.
+	BackpressureWaits uint64
+}
+
+// GetHandlerQueueStats returns the current backpressure metrics of the incoming node queue.
+func (cli *Client) GetHandlerQueueStats() HandlerQueueStats {
+	if cli == nil {
+		return HandlerQueueStats{}
+	}
+	stats := HandlerQueueStats{
+		Queued:    len(cli.handlerQueue),
+		Capacity:  cap(cli.handlerQueue),
+		Overflows: cli.handlerQueueOverflows.Load(),
+	}
+	cli.nodeDispatcherLock.Lock()
+	nd := cli.nodeDispatcher
+	cli.nodeDispatcherLock.Unlock()
+	if nd != nil {
+		nd.fillStats(&stats)
+	}
+	return stats
+}
+
+// nodeDispatcher handles incoming nodes concurrently across chats while keeping strict ordering within each chat.
+//
+// Each chat gets a lane with its own FIFO queue. A lane is drained by at most one goroutine at a time,
+// and the number of lanes being drained simultaneously is limited by the worker semaphore.
+type nodeDispatcher struct {
+	cli     *Client
+	workers chan struct{}
+
+	lock       sync.Mutex
+	cond       *sync.Cond
+	lanes      map[string][]*waBinary.Node
+	backlog    int
+	maxBacklog int
+	limit      int
+
+	busyWorkers       atomic.Int32
+	backpressureWaits atomic.Uint64
+}
+
+func newNodeDispatcher(cli *Client, workers, limit int) *nodeDispatcher {
+	nd := &nodeDispatcher{
+		cli:     cli,
+		workers: make(chan struct{}, workers),
+		lanes:   make(map[string][]*waBinary.Node),
+		limit:   limit,
+	}
+	nd.cond = sync.NewCond(&nd.lock)
+	return nd
+}
+
+func (nd *nodeDispatcher) fillStats(stats *HandlerQueueStats) {
+	nd.lock.Lock()
+	stats.Backlog = nd.backlog
+	stats.MaxBacklog = nd.maxBacklog
+	stats.ActiveChats = len(nd.lanes)
+	nd.lock.Unlock()
+	stats.BusyWorkers = int(nd.busyWorkers.Load())
+	stats.BackpressureWaits = nd.backpressureWaits.Load()
+}
+
+// enqueue adds the node to the lane of the given key, blocking while the backlog is full.
+// It returns false if the context is canceled before there's room for the node.
+func (nd *nodeDispatcher) enqueue(ctx context.Context, key string, node *waBinary.Node) bool {
+	nd.lock.Lock()
+	if nd.backlog >= nd.limit {
+		nd.backpressureWaits.Add(1)
+		stopWaking := context.AfterFunc(ctx, func() {
+			nd.lock.Lock()
+			nd.cond.Broadcast()
+			nd.lock.Unlock()
+		})
+		for nd.backlog >= nd.limit && ctx.Err() == nil {
+			nd.cond.Wait()
+		}
+		stopWaking()
+		if ctx.Err() != nil {
+			nd.lock.Unlock()
+			return false
+		}
+	}
+	queue, laneActive := nd.lanes[key]
+	nd.lanes[key] = append(queue, node)
+	nd.backlog++
+	if nd.backlog > nd.maxBacklog {
+		nd.maxBacklog = nd.backlog
+	}
+	nd.lock.Unlock()
+	if !laneActive {
+		go nd.runLane(key)
+	}
+	return true
+}
+
+// waitIdle blocks until every lane has been drained and all their handlers have returned.
+// It returns false if the context is canceled first.
+func (nd *nodeDispatcher) waitIdle(ctx context.Context) bool {
+	nd.lock.Lock()
+	defer nd.lock.Unlock()
+	if len(nd.lanes) == 0 {
+		return true
+	}
+	stopWaking := context.AfterFunc(ctx, func() {
+		nd.lock.Lock()
+		nd.cond.Broadcast()
+		nd.lock.Unlock()
+	})
+	defer stopWaking()
+	for len(nd.lanes) > 0 && ctx.Err() == nil {
+		nd.cond.Wait()
+	}
+	return ctx.Err() == nil
+}
+
+func (nd *nodeDispatcher) next(key string) *waBinary.Node {
+	nd.lock.Lock()
+	defer nd.lock.Unlock()
+	queue := nd.lanes[key]
+	if len(queue) == 0 {
+		delete(nd.lanes, key)
+		nd.cond.Broadcast()
+		return nil
+	}
+	node := queue[0]
+	queue[0] = nil
+	nd.lanes[key] = queue[1:]
+	nd.backlog--
+	nd.cond.Broadcast()
+	return node
+}
+
+func (nd *nodeDispatcher) runLane(key string) {
+	// The server lane doesn't take a worker slot, so that slow chat handlers can't delay pings and other info queries.
+	if key != serverLaneKey {
+		nd.workers <- struct{}{}
+		defer func() {
+			<-nd.workers
+		}()
+	}
+	for node := nd.next(key); node != nil; node = nd.next(key) {
+		nd.busyWorkers.Add(1)
+		nd.cli.runNodeHandler(node)
+		nd.busyWorkers.Add(-1)
+	}
+}
+
+// serverLaneKey is the lane used for info queries from the server and other nodes that aren't related to a chat.
+// It can't collide with chat lanes, as those are keyed by JIDs, which always contain an @.
+const serverLaneKey = "server"
+
+// getNodeOrderingKey returns the key used to decide which nodes must be handled in order relative to each other.
+//
+// Nodes related to a chat (messages, receipts, etc.) are keyed by the chat JID. Chats are keyed by their phone number
+// JID when the phone number of a LID is known, so that nodes addressed to either JID of the same chat share a lane.
+// Info queries, ib nodes and other nodes without a sender go to the server lane, which never waits for chat handlers.
+// Connection state nodes return an empty key, which makes them act as a barrier.
+func (cli *Client) getNodeOrderingKey(node *waBinary.Node) string {
+	switch node.Tag {
+	case "success", "failure", "stream:error":
+		return ""
+	case "iq", "ib":
+		return serverLaneKey
+	}
+	from, ok := node.Attrs["from"].(types.JID)
+	if !ok {
+		return serverLaneKey
+	}
+	switch from.Server {
+	case types.GroupServer, types.BroadcastServer, types.NewsletterServer:
+		return from.String()
+	}
+	cli.learnLIDMappingsFromNode(node)
+	chat := from
+	if recipient, ok := node.Attrs["recipient"].(types.JID); ok && cli.isOwnUser(from) {
+		chat = recipient
+	}
+	return cli.normalizeLID(chat.ToNonAD()).String()
+}
+
+func (cli *Client) isOwnUser(jid types.JID) bool {
+	if jid.Server == types.HiddenUserServer {
+		return cli.Store != nil && jid.User == cli.Store.LID.User
+	}
+	return jid.User == cli.getOwnID().User
+}
+
+// pnAttributes maps LID node attributes to the attribute that contains the phone number JID of the same user.
+var pnAttributes = map[string]string{
+	"from":        "sender_pn",
+	"participant": "participant_pn",
+	"recipient":   "peer_recipient_pn",
+}
+
+// learnLIDMappingsFromNode remembers which phone number JIDs the LIDs in the given node belong to,
+// for the nodes that contain both.
+func (cli *Client) learnLIDMappingsFromNode(node *waBinary.Node) {
+	for lidAttr, pnAttr := range pnAttributes {
+		lid, ok := node.Attrs[lidAttr].(types.JID)
+		if !ok || lid.Server != types.HiddenUserServer {
+			continue
+		}
+		if pn, ok := node.Attrs[pnAttr].(types.JID); ok {
+			cli.storeLIDMapping(lid, pn)
+		}
+	}
+}
+
+func (cli *Client) learnLIDMappingsFromParticipants(participants []types.GroupParticipant) {
+	for _, participant := range participants {
+		if participant.JID.Server == types.DefaultUserServer && !participant.LID.IsEmpty() {
+			cli.storeLIDMapping(participant.LID, participant.JID)
+		}
+	}
+}
+
+// lidMappingsSize is the maximum number of LID to phone number mappings kept in memory for ordering nodes.
+// When it's exceeded, the oldest mappings are forgotten, which only means that nodes of that chat sent to the LID
+// and the phone number may be handled in separate lanes until the mapping is seen again.
+const lidMappingsSize = 4096
+
+func (cli *Client) storeLIDMapping(lid, pn types.JID) {
+	lid, pn = lid.ToNonAD(), pn.ToNonAD()
+	if lid.Server != types.HiddenUserServer || pn.Server != types.DefaultUserServer {
+		return
+	}
+	cli.lidMappingsLock.Lock()
+	defer cli.lidMappingsLock.Unlock()
+	if cli.lidMappings == nil {
+		cli.lidMappings = make(map[types.JID]types.JID, lidMappingsSize)
+		cli.lidMappingsList = make([]types.JID, lidMappingsSize)
+	}
+	if _, known := cli.lidMappings[lid]; !known {
+		if oldest := cli.lidMappingsList[cli.lidMappingsPtr]; !oldest.IsEmpty() {
+			delete(cli.lidMappings, oldest)
+		}
+		cli.lidMappingsList[cli.lidMappingsPtr] = lid
+		cli.lidMappingsPtr = (cli.lidMappingsPtr + 1) % len(cli.lidMappingsList)
+	}
+	cli.lidMappings[lid] = pn
+}
+
+// normalizeLID returns the phone number JID of the given LID if it's known, and the input JID otherwise.
+func (cli *Client) normalizeLID(jid types.JID) types.JID {
+	if jid.Server != types.HiddenUserServer {
+		return jid
+	} else if cli.Store != nil && cli.Store.ID != nil && jid.User == cli.Store.LID.User {
+		return cli.Store.ID.ToNonAD()
+	}
+	cli.lidMappingsLock.RLock()
+	pn, ok := cli.lidMappings[jid]
+	cli.lidMappingsLock.RUnlock()
+	if ok {
+		return pn
+	}
+	return jid
+}
+
+func (cli *Client) concurrentHandlerQueueLoop(ctx context.Context) {
+	cli.nodeDispatcherLock.Lock()
+	if cli.nodeDispatcher == nil || cap(cli.nodeDispatcher.workers) != cli.ConcurrentNodeWorkers {
+		cli.nodeDispatcher = newNodeDispatcher(cli, cli.ConcurrentNodeWorkers, handlerQueueSize)
+	}
+	nd := cli.nodeDispatcher
+	cli.nodeDispatcherLock.Unlock()
+	cli.Log.Debugf("Starting concurrent handler queue loop with %d workers", cli.ConcurrentNodeWorkers)
+	for {
+		select {
+		case node := <-cli.handlerQueue:
+			key := cli.getNodeOrderingKey(node)
+			if key == "" {
+				// Connection-level nodes are a barrier: everything received before them is handled first,
+				// and nothing received after them is handled until they're done.
+				if !nd.waitIdle(ctx) {
+					cli.Log.Warnf("Handler queue loop closed while waiting for chats to be handled, node %s will not be handled", node.Tag)
+					cli.pendingNodes.Add(-1)
+					return
+				}
+				cli.runNodeHandler(node)
+				continue
+			}
+			if !nd.enqueue(ctx, key, node) {
+				cli.Log.Warnf("Handler queue loop closed while waiting for room in backlog, node %s will not be handled", node.Tag)
+				cli.pendingNodes.Add(-1)
+				return
+			}
+		case <-ctx.Done():
+			cli.Log.Debugf("Closing concurrent handler queue loop")
+			return
+		}
+	}
+}
+
+func (cli *Client) runNodeHandler(node *waBinary.Node) {
+	start := time.Now()
+	cli.nodeHandlers[node.Tag](node)
+	duration := time.Since(start)
+	cli.pendingNodes.Add(-1)
+	if duration > 5*time.Second {
+		cli.Log.Warnf("Node handling took %s for %s", duration, node.XMLString())
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+func TestConcurrentHandlerQueueBarrier(t *testing.T) {
+	var lock sync.Mutex
+	var handled []string
+	var wg sync.WaitGroup
+	record := func(node *waBinary.Node) {
+		defer wg.Done()
+		id, _ := node.Attrs["id"].(string)
+		if id == "slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		lock.Lock()
+		handled = append(handled, id)
+		lock.Unlock()
+	}
+	cli := &Client{
+		Log:                   waLog.Noop,
+		Store:                 &store.Device{},
+		ConcurrentNodeWorkers: 4,
+		handlerQueue:          make(chan *waBinary.Node, 8),
+		nodeHandlers:          map[string]nodeHandler{"message": record, "success": record},
+	}
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	bob := types.NewJID("2222", types.DefaultUserServer)
+	nodes := []*waBinary.Node{
+		{Tag: "message", Attrs: waBinary.Attrs{"id": "slow", "from": alice}},
+		{Tag: "success", Attrs: waBinary.Attrs{"id": "success"}},
+		{Tag: "message", Attrs: waBinary.Attrs{"id": "after", "from": bob}},
+	}
+	wg.Add(len(nodes))
+	for _, node := range nodes {
+		cli.pendingNodes.Add(1)
+		cli.handlerQueue <- node
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cli.concurrentHandlerQueueLoop(ctx)
+	wg.Wait()
+
+	if expected := []string{"slow", "success", "after"}; !slices.Equal(handled, expected) {
+		t.Errorf("expected nodes to be handled in order %v, got %v", expected, handled)
+	}
+	if pending := cli.pendingNodes.Load(); pending != 0 {
+		t.Errorf("expected no pending nodes, got %d", pending)
+	}
+}
+
+func TestNodeOrderingKeyNormalizesLID(t *testing.T) {
+	cli := &Client{Log: waLog.Noop, Store: &store.Device{}}
+	pn := types.NewJID("1111", types.DefaultUserServer)
+	lid := types.NewJID("9999", types.HiddenUserServer)
+
+	if key := cli.getNodeOrderingKey(&waBinary.Node{Tag: "message", Attrs: waBinary.Attrs{"from": lid}}); key != lid.String() {
+		t.Errorf("expected unknown LID to be used as is, got %s", key)
+	}
+	withPN := &waBinary.Node{Tag: "message", Attrs: waBinary.Attrs{"from": lid, "sender_pn": pn}}
+	if key := cli.getNodeOrderingKey(withPN); key != pn.String() {
+		t.Errorf("expected LID with sender_pn to be keyed by phone number, got %s", key)
+	}
+	receipt := &waBinary.Node{Tag: "receipt", Attrs: waBinary.Attrs{"from": types.JID{User: "9999", Device: 3, Server: types.HiddenUserServer}}}
+	if key := cli.getNodeOrderingKey(receipt); key != pn.String() {
+		t.Errorf("expected learned LID mapping to be used for later nodes, got %s", key)
+	}
+	if key := cli.getNodeOrderingKey(&waBinary.Node{Tag: "ib"}); key != serverLaneKey {
+		t.Errorf("expected ib node to use the server lane, got %s", key)
+	}
+	if key := cli.getNodeOrderingKey(&waBinary.Node{Tag: "success"}); key != "" {
+		t.Errorf("expected connection-level node to have empty key, got %s", key)
+	}
+}
+
+func TestLIDMappingsAreBounded(t *testing.T) {
+	cli := &Client{Log: waLog.Noop, Store: &store.Device{}}
+	for i := 0; i < lidMappingsSize+10; i++ {
+		cli.storeLIDMapping(types.NewJID(strconv.Itoa(100000+i), types.HiddenUserServer), types.NewJID(strconv.Itoa(i), types.DefaultUserServer))
+	}
+	if size := len(cli.lidMappings); size != lidMappingsSize {
+		t.Errorf("expected %d mappings to be kept, got %d", lidMappingsSize, size)
+	}
+	if jid := cli.normalizeLID(types.NewJID("100000", types.HiddenUserServer)); jid.Server != types.HiddenUserServer {
+		t.Errorf("expected oldest mapping to be forgotten, got %s", jid)
+	}
+	latest := types.NewJID(strconv.Itoa(100000+lidMappingsSize+9), types.HiddenUserServer)
+	if jid := cli.normalizeLID(latest); jid.User != strconv.Itoa(lidMappingsSize+9) {
+		t.Errorf("expected latest mapping to be kept, got %s", jid)
+	}
+}
+
+func TestBlockedChatDoesNotDelayIQ(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	started := make(chan struct{}, 2)
+	iqHandled := make(chan struct{})
+	cli := &Client{
+		Log:                   waLog.Noop,
+		Store:                 &store.Device{},
+		ConcurrentNodeWorkers: 1,
+		handlerQueue:          make(chan *waBinary.Node, 8),
+		nodeHandlers: map[string]nodeHandler{
+			"message": func(node *waBinary.Node) {
+				started <- struct{}{}
+				<-unblock
+			},
+			"iq": func(node *waBinary.Node) {
+				close(iqHandled)
+			},
+		},
+	}
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	bob := types.NewJID("2222", types.DefaultUserServer)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cli.concurrentHandlerQueueLoop(ctx)
+
+	// The only worker is stuck in alice's handler and bob's message is waiting for it
+	cli.handlerQueue <- &waBinary.Node{Tag: "message", Attrs: waBinary.Attrs{"id": "1", "from": alice}}
+	<-started
+	cli.handlerQueue <- &waBinary.Node{Tag: "message", Attrs: waBinary.Attrs{"id": "2", "from": bob}}
+	cli.handlerQueue <- &waBinary.Node{Tag: "iq", Attrs: waBinary.Attrs{"id": "ping", "from": types.ServerJID, "type": "get"}}
+	select {
+	case <-iqHandled:
+	case <-time.After(time.Second):
+		t.Fatalf("iq wasn't handled while a chat handler was blocked")
+	}
+}
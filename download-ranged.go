@@ -0,0 +1,398 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mau.fi/util/retryafter"
+
+	"github.com/pbribeiro/whatsmeow-mysql/util/cbcutil"
+)
+
+// MediaDownloadState records which segments of an encrypted media file have already been downloaded,
+// so that an interrupted DownloadToFileRanged call can be resumed without starting from zero.
+//
+// The state can be marshaled as JSON and stored next to the partial file.
+type MediaDownloadState struct {
+	FileEncSHA256 []byte `json:"file_enc_sha256"`
+	Size          int64  `json:"size"`
+	SegmentSize   int64  `json:"segment_size"`
+	Completed     []bool `json:"completed"`
+	// The hashes of the completed segments as they were downloaded. When resuming, the completed segments
+	// are checked against these, and any segment that changed in the file is downloaded again.
+	SegmentSHA256 [][]byte `json:"segment_sha256"`
+}
+
+func (mds *MediaDownloadState) matches(fileEncSHA256 []byte, size, segmentSize int64) bool {
+	segmentCount := int((size + segmentSize - 1) / segmentSize)
+	return mds != nil && bytes.Equal(mds.FileEncSHA256, fileEncSHA256) && mds.Size == size &&
+		mds.SegmentSize == segmentSize && len(mds.Completed) == segmentCount && len(mds.SegmentSHA256) == segmentCount
+}
+
+// RangedDownloadOptions contains options for DownloadToFileRanged.
+type RangedDownloadOptions struct {
+	// The number of segments to download at the same time. Defaults to 4.
+	Parallel int
+	// The size of each segment in bytes. Defaults to 4 MiB.
+	// The segment size can't be changed when resuming a download.
+	SegmentSize int64
+	// How many times to retry a single segment before giving up. Zero disables retries,
+	// and a negative value uses the default of 5 retries.
+	// Each retry continues from where the previous attempt stopped and uses the next media host.
+	MaxRetries int
+
+	// A previously saved state to resume from. The file must contain the partial data from the same download.
+	// If the state doesn't match the media being downloaded, the download starts from scratch.
+	State *MediaDownloadState
+	// Called with a snapshot of the state every time a segment finishes, so it can be persisted for resuming.
+	// Calls are never concurrent, and each snapshot is at least as new as the previous one.
+	SaveState func(state MediaDownloadState)
+	// Called as the download progresses with the number of encrypted bytes downloaded so far and the total size.
+	Progress func(downloaded, total int64)
+}
+
+// DownloadToFileRanged downloads the attachment from the given protobuf message into the given file using
+// parallel HTTP range requests.
+//
+// This is otherwise identical to [DownloadToFile], but failures only retry the affected segment, and interrupted
+// downloads can be resumed by passing the last saved [MediaDownloadState] along with the same file.
+// The encrypted data is written to the file first, and then verified and decrypted in place in a single pass.
+//
+// Media without a known file length or without encryption is downloaded with [DownloadToFile] instead.
+func (cli *Client) DownloadToFileRanged(ctx context.Context, msg DownloadableMessage, file File, opts RangedDownloadOptions) error {
+	if cli == nil {
+		return ErrClientIsNil
+	}
+	mediaType := GetMediaType(msg)
+	if mediaType == "" {
+		return fmt.Errorf("%w %T", ErrUnknownMediaType, msg)
+	}
+	fileLength := getSize(msg)
+	if fileLength <= 0 || len(msg.GetMediaKey()) == 0 || len(msg.GetFileEncSHA256()) != 32 || len(msg.GetDirectPath()) == 0 {
+		return cli.DownloadToFile(msg, file)
+	}
+	if opts.Parallel <= 0 {
+		opts.Parallel = 4
+	}
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = 4 * 1024 * 1024
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 5
+	}
+	mediaConn, err := cli.refreshMediaConn(false)
+	if err != nil {
+		return fmt.Errorf("failed to refresh media connections: %w", err)
+	}
+	urls := make([]string, len(mediaConn.Hosts))
+	for i, host := range mediaConn.Hosts {
+		urls[i] = fmt.Sprintf("https://%s%s&hash=%s&mms-type=%s&__wa-mms=", host.Hostname, msg.GetDirectPath(), base64.URLEncoding.EncodeToString(msg.GetFileEncSHA256()), mediaTypeToMMSType[mediaType])
+	}
+	if len(urls) == 0 {
+		return ErrNoURLPresent
+	}
+
+	rd := &rangedDownload{
+		cli:  cli,
+		urls: urls,
+		file: file,
+		opts: opts,
+		size: int64(cbcutil.EncryptedSize(uint64(fileLength))),
+	}
+	if opts.State.matches(msg.GetFileEncSHA256(), rd.size, opts.SegmentSize) {
+		rd.state = opts.State
+		err = rd.verifyCompletedSegments()
+		if err != nil {
+			return err
+		}
+	} else {
+		segmentCount := (rd.size + opts.SegmentSize - 1) / opts.SegmentSize
+		rd.state = &MediaDownloadState{
+			FileEncSHA256: msg.GetFileEncSHA256(),
+			Size:          rd.size,
+			SegmentSize:   opts.SegmentSize,
+			Completed:     make([]bool, segmentCount),
+			SegmentSHA256: make([][]byte, segmentCount),
+		}
+		err = file.Truncate(rd.size)
+		if err != nil {
+			return fmt.Errorf("failed to preallocate file: %w", err)
+		}
+	}
+	err = rd.run(ctx)
+	if err != nil {
+		return err
+	}
+	iv, cipherKey, macKey, _ := getMediaKeys(msg.GetMediaKey(), mediaType)
+	err = decryptMediaFileInPlace(file, rd.size, iv, cipherKey, macKey, msg.GetFileEncSHA256(), msg.GetFileSHA256(), int64(fileLength))
+	if err != nil {
+		// The file contents can't be trusted anymore, so make sure resuming starts from scratch
+		clear(rd.state.Completed)
+		rd.saveState()
+	}
+	return err
+}
+
+type rangedDownload struct {
+	cli  *Client
+	urls []string
+	file File
+	opts RangedDownloadOptions
+	size int64
+
+	stateLock  sync.Mutex
+	state      *MediaDownloadState
+	saveLock   sync.Mutex
+	downloaded atomic.Int64
+}
+
+func (rd *rangedDownload) saveState() {
+	if rd.opts.SaveState == nil {
+		return
+	}
+	// Saves are serialized so that an older snapshot can never be saved after a newer one
+	rd.saveLock.Lock()
+	defer rd.saveLock.Unlock()
+	rd.stateLock.Lock()
+	snapshot := *rd.state
+	snapshot.Completed = slices.Clone(rd.state.Completed)
+	snapshot.SegmentSHA256 = slices.Clone(rd.state.SegmentSHA256)
+	rd.stateLock.Unlock()
+	rd.opts.SaveState(snapshot)
+}
+
+func (rd *rangedDownload) segmentBounds(segment int) (start, end int64) {
+	start = int64(segment) * rd.opts.SegmentSize
+	end = min(start+rd.opts.SegmentSize, rd.size)
+	return
+}
+
+// verifyCompletedSegments checks the segments that a resumed state marks as completed against the file,
+// and marks the ones whose data changed since they were downloaded as not completed.
+func (rd *rangedDownload) verifyCompletedSegments() error {
+	buf := make([]byte, 32*1024)
+	for segment, completed := range rd.state.Completed {
+		if !completed {
+			continue
+		}
+		hasher := sha256.New()
+		start, end := rd.segmentBounds(segment)
+		for offset := start; offset < end; {
+			chunk := buf[:min(int64(len(buf)), end-offset)]
+			_, err := rd.file.ReadAt(chunk, offset)
+			if err != nil {
+				return fmt.Errorf("failed to read segment #%d from file: %w", segment, err)
+			}
+			hasher.Write(chunk)
+			offset += int64(len(chunk))
+		}
+		if !hmac.Equal(hasher.Sum(nil), rd.state.SegmentSHA256[segment]) {
+			rd.cli.Log.Warnf("Segment #%d of partial media download changed since it was downloaded, downloading it again", segment)
+			rd.state.Completed[segment] = false
+			rd.state.SegmentSHA256[segment] = nil
+		}
+	}
+	return nil
+}
+
+func (rd *rangedDownload) addProgress(n int64) {
+	downloaded := rd.downloaded.Add(n)
+	if rd.opts.Progress != nil {
+		rd.opts.Progress(downloaded, rd.size)
+	}
+}
+
+func (rd *rangedDownload) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	segments := make(chan int, len(rd.state.Completed))
+	for i, completed := range rd.state.Completed {
+		if completed {
+			start, end := rd.segmentBounds(i)
+			rd.downloaded.Add(end - start)
+		} else {
+			segments <- i
+		}
+	}
+	close(segments)
+	var wg sync.WaitGroup
+	for i := 0; i < rd.opts.Parallel; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for segment := range segments {
+				if ctx.Err() != nil {
+					return
+				}
+				hash, err := rd.downloadSegment(ctx, segment, worker)
+				if err != nil {
+					cancel(fmt.Errorf("failed to download segment #%d: %w", segment, err))
+					return
+				}
+				rd.stateLock.Lock()
+				rd.state.Completed[segment] = true
+				rd.state.SegmentSHA256[segment] = hash
+				rd.stateLock.Unlock()
+				rd.saveState()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return context.Cause(ctx)
+}
+
+// downloadSegment downloads the given segment into the file and returns the SHA-256 hash of the segment.
+func (rd *rangedDownload) downloadSegment(ctx context.Context, segment, worker int) ([]byte, error) {
+	start, end := rd.segmentBounds(segment)
+	hostIndex := worker % len(rd.urls)
+	// Retries continue from where the previous attempt stopped, so the data is always hashed in order
+	hasher := sha256.New()
+	for retryNum := 0; ; retryNum++ {
+		n, err := rd.downloadRange(ctx, rd.urls[hostIndex], start, end, hasher)
+		start += n
+		if err == nil {
+			return hasher.Sum(nil), nil
+		} else if ctx.Err() != nil {
+			return nil, ctx.Err()
+		} else if retryNum >= rd.opts.MaxRetries || !(shouldRetryMediaDownload(err) || errors.Is(err, io.ErrUnexpectedEOF)) {
+			return nil, err
+		}
+		retryDuration := time.Duration(retryNum+1) * time.Second
+		var httpErr DownloadHTTPError
+		if errors.As(err, &httpErr) {
+			retryDuration = retryafter.Parse(httpErr.Response.Header.Get("Retry-After"), retryDuration)
+		}
+		hostIndex = (hostIndex + 1) % len(rd.urls)
+		rd.cli.Log.Warnf("Failed to download media segment #%d: %v, retrying from byte %d in %s...", segment, err, start, retryDuration)
+		select {
+		case <-time.After(retryDuration):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// downloadRange downloads the bytes from start (inclusive) to end (exclusive) into the file and returns how many bytes were written.
+// The written bytes are also written to the given hasher.
+func (rd *rangedDownload) downloadRange(ctx context.Context, url string, start, end int64, hasher hash.Hash) (int64, error) {
+	req, err := rd.cli.prepareMediaDownloadRequest(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+	resp, err := rd.cli.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK && (start != 0 || end != rd.size) {
+		return 0, fmt.Errorf("media server doesn't support range requests")
+	} else if resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusOK {
+		return 0, DownloadHTTPError{Response: resp}
+	}
+	var written int64
+	buf := make([]byte, 32*1024)
+	for written < end-start {
+		n, readErr := resp.Body.Read(buf[:min(int64(len(buf)), end-start-written)])
+		if n > 0 {
+			_, err = rd.file.WriteAt(buf[:n], start+written)
+			if err != nil {
+				return written, fmt.Errorf("failed to write to file: %w", err)
+			}
+			hasher.Write(buf[:n])
+			written += int64(n)
+			rd.addProgress(int64(n))
+		}
+		if readErr == io.EOF {
+			if written < end-start {
+				return written, io.ErrUnexpectedEOF
+			}
+		} else if readErr != nil {
+			return written, readErr
+		}
+	}
+	return written, nil
+}
+
+// decryptMediaFileInPlace verifies the hashes and MAC of an encrypted media file and decrypts it in place, all in a single pass.
+//
+// The file is decrypted before the MAC can be checked, so the file contents are garbage if this returns an error.
+func decryptMediaFileInPlace(file File, size int64, iv, cipherKey, macKey, fileEncSHA256, fileSHA256 []byte, fileLength int64) error {
+	ciphertextLength := size - mediaHMACLength
+	if ciphertextLength <= 0 || ciphertextLength%aes.BlockSize != 0 {
+		return ErrTooShortFile
+	}
+	expectedMAC := make([]byte, mediaHMACLength)
+	_, err := file.ReadAt(expectedMAC, ciphertextLength)
+	if err != nil {
+		return fmt.Errorf("failed to read MAC from file: %w", err)
+	}
+	block, err := aes.NewCipher(cipherKey)
+	if err != nil {
+		return fmt.Errorf("failed to create cipher: %w", err)
+	}
+	cbc := cipher.NewCBCDecrypter(block, iv)
+	encHasher := sha256.New()
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(iv)
+	plainHasher := sha256.New()
+
+	buf := make([]byte, 32*1024)
+	var padding int64
+	for offset := int64(0); offset < ciphertextLength; {
+		chunk := buf[:min(int64(len(buf)), ciphertextLength-offset)]
+		_, err = file.ReadAt(chunk, offset)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		encHasher.Write(chunk)
+		mac.Write(chunk)
+		cbc.CryptBlocks(chunk, chunk)
+		_, err = file.WriteAt(chunk, offset)
+		if err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		offset += int64(len(chunk))
+		if offset == ciphertextLength {
+			padding = int64(chunk[len(chunk)-1])
+			if padding == 0 || padding > aes.BlockSize {
+				return fmt.Errorf("invalid padding length %d", padding)
+			}
+			chunk = chunk[:int64(len(chunk))-padding]
+		}
+		plainHasher.Write(chunk)
+	}
+	encHasher.Write(expectedMAC)
+	if !hmac.Equal(encHasher.Sum(nil), fileEncSHA256) {
+		return ErrInvalidMediaEncSHA256
+	} else if !hmac.Equal(mac.Sum(nil)[:mediaHMACLength], expectedMAC) {
+		return ErrInvalidMediaHMAC
+	} else if plaintextLength := ciphertextLength - padding; plaintextLength != fileLength {
+		return fmt.Errorf("%w: expected %d, got %d", ErrFileLengthMismatch, fileLength, plaintextLength)
+	} else if len(fileSHA256) == 32 && !hmac.Equal(plainHasher.Sum(nil), fileSHA256) {
+		return ErrInvalidMediaSHA256
+	} else if err = file.Truncate(plaintextLength); err != nil {
+		return fmt.Errorf("failed to truncate file to remove padding and MAC: %w", err)
+	}
+	return nil
+}
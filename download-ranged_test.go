@@ -0,0 +1,226 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mau.fi/util/random"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/util/cbcutil"
+)
+
+type testMedia struct {
+	plaintext []byte
+	encrypted []byte
+	msg       *waE2E.DocumentMessage
+}
+
+func makeTestMedia(t *testing.T, size int) *testMedia {
+	t.Helper()
+	tm := &testMedia{plaintext: random.Bytes(size)}
+	mediaKey := random.Bytes(32)
+	iv, cipherKey, macKey, _ := getMediaKeys(mediaKey, MediaDocument)
+	ciphertext, err := cbcutil.Encrypt(cipherKey, iv, tm.plaintext)
+	if err != nil {
+		t.Fatalf("failed to encrypt test media: %v", err)
+	}
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	tm.encrypted = append(ciphertext, mac.Sum(nil)[:mediaHMACLength]...)
+	fileSHA256 := sha256.Sum256(tm.plaintext)
+	fileEncSHA256 := sha256.Sum256(tm.encrypted)
+	tm.msg = &waE2E.DocumentMessage{
+		MediaKey:      mediaKey,
+		FileSHA256:    fileSHA256[:],
+		FileEncSHA256: fileEncSHA256[:],
+		FileLength:    proto.Uint64(uint64(size)),
+		DirectPath:    proto.String("/v/t62/file?ccb=1"),
+	}
+	return tm
+}
+
+func (tm *testMedia) decryptInPlace(file File) error {
+	iv, cipherKey, macKey, _ := getMediaKeys(tm.msg.GetMediaKey(), MediaDocument)
+	return decryptMediaFileInPlace(file, int64(len(tm.encrypted)), iv, cipherKey, macKey, tm.msg.GetFileEncSHA256(), tm.msg.GetFileSHA256(), int64(len(tm.plaintext)))
+}
+
+func writeTempFile(t *testing.T, data []byte) *os.File {
+	t.Helper()
+	file, err := os.Create(filepath.Join(t.TempDir(), "media"))
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = file.Close()
+	})
+	if _, err = file.WriteAt(data, 0); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	return file
+}
+
+func readTempFile(t *testing.T, file *os.File) []byte {
+	t.Helper()
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatalf("failed to read temp file: %v", err)
+	}
+	return data
+}
+
+func TestDecryptMediaFileInPlace(t *testing.T) {
+	tm := makeTestMedia(t, 100000)
+	tests := []struct {
+		name     string
+		tamper   func(data []byte)
+		expected error
+	}{
+		{"Valid", func(data []byte) {}, nil},
+		{"TamperedCiphertext", func(data []byte) { data[5000] ^= 0xff }, ErrInvalidMediaEncSHA256},
+		{"TamperedMAC", func(data []byte) { data[len(data)-1] ^= 0xff }, ErrInvalidMediaEncSHA256},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := bytes.Clone(tm.encrypted)
+			test.tamper(data)
+			file := writeTempFile(t, data)
+			err := tm.decryptInPlace(file)
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected error %v, got %v", test.expected, err)
+			} else if err == nil && !bytes.Equal(readTempFile(t, file), tm.plaintext) {
+				t.Error("decrypted file doesn't match plaintext")
+			}
+		})
+	}
+}
+
+// rangeServer serves the given data with range request support and records which ranges were requested.
+type rangeServer struct {
+	data []byte
+
+	lock   sync.Mutex
+	ranges []string
+}
+
+func (rs *rangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rs.lock.Lock()
+	rs.ranges = append(rs.ranges, r.Header.Get("Range"))
+	rs.lock.Unlock()
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(rs.data))
+}
+
+const testSegmentSize = 16 * 1024
+
+// prepareResumedDownload writes the first segment of the test media to a file and returns a state that marks it as completed.
+func prepareResumedDownload(t *testing.T, tm *testMedia, firstSegment []byte) (*os.File, *MediaDownloadState) {
+	t.Helper()
+	file := writeTempFile(t, firstSegment)
+	if err := file.Truncate(int64(len(tm.encrypted))); err != nil {
+		t.Fatalf("failed to preallocate file: %v", err)
+	}
+	segmentCount := (len(tm.encrypted) + testSegmentSize - 1) / testSegmentSize
+	segmentHash := sha256.Sum256(tm.encrypted[:testSegmentSize])
+	state := &MediaDownloadState{
+		FileEncSHA256: tm.msg.GetFileEncSHA256(),
+		Size:          int64(len(tm.encrypted)),
+		SegmentSize:   testSegmentSize,
+		Completed:     make([]bool, segmentCount),
+		SegmentSHA256: make([][]byte, segmentCount),
+	}
+	state.Completed[0] = true
+	state.SegmentSHA256[0] = segmentHash[:]
+	return file, state
+}
+
+func TestDownloadToFileRangedResume(t *testing.T) {
+	tm := makeTestMedia(t, 100000)
+	rs := &rangeServer{data: tm.encrypted}
+	cli := newFakeMediaClient(t, rs)
+	file, state := prepareResumedDownload(t, tm, tm.encrypted[:testSegmentSize])
+
+	err := cli.DownloadToFileRanged(context.Background(), tm.msg, file, RangedDownloadOptions{SegmentSize: testSegmentSize, State: state})
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	if !bytes.Equal(readTempFile(t, file), tm.plaintext) {
+		t.Error("downloaded file doesn't match plaintext")
+	}
+	for _, requested := range rs.ranges {
+		if requested == "bytes=0-16383" {
+			t.Error("expected already downloaded segment not to be requested again")
+		}
+	}
+	if expected := len(state.Completed) - 1; len(rs.ranges) != expected {
+		t.Errorf("expected %d range requests, got %v", expected, rs.ranges)
+	}
+}
+
+func TestDownloadToFileRangedReplacedPartialFile(t *testing.T) {
+	tm := makeTestMedia(t, 100000)
+	rs := &rangeServer{data: tm.encrypted}
+	cli := newFakeMediaClient(t, rs)
+	// The state says the first segment is done, but the file contents were replaced after it was saved
+	file, state := prepareResumedDownload(t, tm, random.Bytes(testSegmentSize))
+
+	var savedStates []MediaDownloadState
+	err := cli.DownloadToFileRanged(context.Background(), tm.msg, file, RangedDownloadOptions{
+		SegmentSize: testSegmentSize,
+		State:       state,
+		SaveState: func(state MediaDownloadState) {
+			savedStates = append(savedStates, state)
+		},
+	})
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	if !bytes.Equal(readTempFile(t, file), tm.plaintext) {
+		t.Error("downloaded file doesn't match plaintext")
+	}
+	if len(rs.ranges) != len(state.Completed) {
+		t.Errorf("expected replaced segment to be downloaded again, got ranges %v", rs.ranges)
+	}
+	if len(savedStates) == 0 {
+		t.Fatal("expected state to be saved")
+	}
+	last := savedStates[len(savedStates)-1]
+	for i, hash := range last.SegmentSHA256 {
+		if !last.Completed[i] || len(hash) != sha256.Size {
+			t.Errorf("expected segment #%d to be completed with a hash in the final state", i)
+		}
+	}
+}
+
+func TestDownloadToFileRangedNoRetries(t *testing.T) {
+	tm := makeTestMedia(t, 1000)
+	var requests atomic.Int32
+	cli := newFakeMediaClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	file := writeTempFile(t, nil)
+	err := cli.DownloadToFileRanged(context.Background(), tm.msg, file, RangedDownloadOptions{MaxRetries: 0})
+	if err == nil {
+		t.Fatal("expected download to fail")
+	} else if requests.Load() != 1 {
+		t.Errorf("expected MaxRetries: 0 to disable retries, got %d requests", requests.Load())
+	}
+}
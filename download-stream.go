@@ -0,0 +1,232 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DownloadStream downloads the attachment from the given protobuf message and returns a reader
+// that decrypts it on the fly.
+//
+// This is otherwise identical to [Download], but the file is never held in memory or written to disk,
+// which makes it possible to pipe media directly to object storage or an HTTP response.
+//
+// The MAC, the encrypted and plaintext SHA-256 hashes and the file length can only be verified once the whole
+// file has been read, so the final Read call returns an error (e.g. ErrInvalidMediaHMAC) instead of io.EOF
+// if the file was corrupted. Everything read before that must be treated as unverified until io.EOF is returned.
+// The last block of the file is withheld until verification passes.
+//
+// The returned reader must be closed. Unlike the other download methods, failed requests are not retried
+// once the response has started, as the data may already have been consumed.
+func (cli *Client) DownloadStream(ctx context.Context, msg DownloadableMessage) (io.ReadCloser, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	mediaType := GetMediaType(msg)
+	if mediaType == "" {
+		return nil, fmt.Errorf("%w %T", ErrUnknownMediaType, msg)
+	}
+	var urls []string
+	if urlable, ok := msg.(downloadableMessageWithURL); ok && len(urlable.GetURL()) > 0 && !strings.HasPrefix(urlable.GetURL(), "https://web.whatsapp.net") {
+		urls = append(urls, urlable.GetURL())
+	}
+	if len(msg.GetDirectPath()) > 0 {
+		mediaConn, err := cli.refreshMediaConn(false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to refresh media connections: %w", err)
+		}
+		for _, host := range mediaConn.Hosts {
+			urls = append(urls, fmt.Sprintf("https://%s%s&hash=%s&mms-type=%s&__wa-mms=", host.Hostname, msg.GetDirectPath(), base64.URLEncoding.EncodeToString(msg.GetFileEncSHA256()), mediaTypeToMMSType[mediaType]))
+		}
+	}
+	if len(urls) == 0 {
+		return nil, ErrNoURLPresent
+	}
+	var resp *http.Response
+	var err error
+	for i, url := range urls {
+		resp, err = cli.doStreamMediaDownloadRequest(ctx, url)
+		if err == nil {
+			break
+		} else if ctx.Err() != nil || errors.Is(err, ErrMediaDownloadFailedWith403) || errors.Is(err, ErrMediaDownloadFailedWith404) || errors.Is(err, ErrMediaDownloadFailedWith410) {
+			return nil, err
+		} else if i < len(urls)-1 {
+			cli.Log.Warnf("Failed to start media download stream: %s, trying with next host...", err)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media from last host: %w", err)
+	}
+	msr := &mediaStreamReader{
+		body:          resp.Body,
+		fileLength:    int64(getSize(msg)),
+		fileSHA256:    msg.GetFileSHA256(),
+		fileEncSHA256: msg.GetFileEncSHA256(),
+		plainHasher:   sha256.New(),
+		buf:           make([]byte, 32*1024),
+	}
+	if msg.GetMediaKey() != nil {
+		iv, cipherKey, macKey, _ := getMediaKeys(msg.GetMediaKey(), mediaType)
+		block, err := aes.NewCipher(cipherKey)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("failed to create cipher: %w", err)
+		}
+		msr.cbc = cipher.NewCBCDecrypter(block, iv)
+		msr.mac = hmac.New(sha256.New, macKey)
+		msr.mac.Write(iv)
+		msr.encHasher = sha256.New()
+	}
+	return msr, nil
+}
+
+func (cli *Client) doStreamMediaDownloadRequest(ctx context.Context, url string) (*http.Response, error) {
+	req, err := cli.prepareMediaDownloadRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cli.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, DownloadHTTPError{Response: resp}
+	}
+	return resp, nil
+}
+
+// mediaStreamReader decrypts and verifies media as it's read from the HTTP response.
+//
+// For encrypted media, the last AES block and the MAC are always held back in the tail buffer,
+// because the padding can only be removed and the MAC only checked once the end of the stream is known.
+type mediaStreamReader struct {
+	body io.ReadCloser
+
+	cbc         cipher.BlockMode
+	mac         hash.Hash
+	encHasher   hash.Hash
+	plainHasher hash.Hash
+
+	fileLength    int64
+	fileSHA256    []byte
+	fileEncSHA256 []byte
+
+	buf     []byte
+	tail    []byte
+	pending []byte
+	read    int64
+	eof     bool
+	err     error
+}
+
+// heldBackLength is the number of bytes at the end of an encrypted stream that can't be decrypted before reaching EOF.
+const heldBackLength = aes.BlockSize + mediaHMACLength
+
+func (msr *mediaStreamReader) Read(p []byte) (int, error) {
+	for len(msr.pending) == 0 {
+		if msr.err != nil {
+			return 0, msr.err
+		} else if msr.eof {
+			msr.err = io.EOF
+			return 0, io.EOF
+		}
+		msr.err = msr.fill()
+	}
+	n := copy(p, msr.pending)
+	msr.pending = msr.pending[n:]
+	return n, nil
+}
+
+func (msr *mediaStreamReader) fill() error {
+	n, err := msr.body.Read(msr.buf)
+	if errors.Is(err, io.EOF) {
+		msr.eof = true
+	} else if err != nil {
+		return err
+	}
+	if msr.cbc == nil {
+		msr.pending = msr.buf[:n]
+		msr.plainHasher.Write(msr.pending)
+		msr.read += int64(n)
+		if msr.eof {
+			return msr.verify()
+		}
+		return nil
+	}
+	msr.tail = append(msr.tail, msr.buf[:n]...)
+	if msr.eof {
+		return msr.finish()
+	}
+	decryptable := (len(msr.tail) - heldBackLength) / aes.BlockSize * aes.BlockSize
+	if decryptable <= 0 {
+		return nil
+	}
+	chunk := make([]byte, decryptable)
+	copy(chunk, msr.tail[:decryptable])
+	msr.tail = append(msr.tail[:0], msr.tail[decryptable:]...)
+	msr.encHasher.Write(chunk)
+	msr.mac.Write(chunk)
+	msr.cbc.CryptBlocks(chunk, chunk)
+	msr.plainHasher.Write(chunk)
+	msr.read += int64(len(chunk))
+	msr.pending = chunk
+	return nil
+}
+
+func (msr *mediaStreamReader) finish() error {
+	if len(msr.tail) < heldBackLength || (len(msr.tail)-mediaHMACLength)%aes.BlockSize != 0 {
+		return ErrTooShortFile
+	}
+	chunk := msr.tail[:len(msr.tail)-mediaHMACLength]
+	expectedMAC := msr.tail[len(msr.tail)-mediaHMACLength:]
+	msr.encHasher.Write(msr.tail)
+	msr.mac.Write(chunk)
+	if len(msr.fileEncSHA256) == 32 && !hmac.Equal(msr.encHasher.Sum(nil), msr.fileEncSHA256) {
+		return ErrInvalidMediaEncSHA256
+	} else if !hmac.Equal(msr.mac.Sum(nil)[:mediaHMACLength], expectedMAC) {
+		return ErrInvalidMediaHMAC
+	}
+	msr.cbc.CryptBlocks(chunk, chunk)
+	padding := int(chunk[len(chunk)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return fmt.Errorf("invalid padding length %d", padding)
+	}
+	chunk = chunk[:len(chunk)-padding]
+	msr.plainHasher.Write(chunk)
+	msr.read += int64(len(chunk))
+	if err := msr.verify(); err != nil {
+		return err
+	}
+	msr.pending = chunk
+	return nil
+}
+
+func (msr *mediaStreamReader) verify() error {
+	if msr.fileLength >= 0 && msr.read != msr.fileLength {
+		return fmt.Errorf("%w: expected %d, got %d", ErrFileLengthMismatch, msr.fileLength, msr.read)
+	} else if len(msr.fileSHA256) == 32 && !hmac.Equal(msr.plainHasher.Sum(nil), msr.fileSHA256) {
+		return ErrInvalidMediaSHA256
+	}
+	return nil
+}
+
+func (msr *mediaStreamReader) Close() error {
+	return msr.body.Close()
+}
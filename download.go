@@ -7,6 +7,7 @@
 package whatsmeow
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
@@ -200,6 +201,10 @@ func GetMediaType(msg DownloadableMessage) MediaType {
 //	imageData, err := cli.Download(msg.GetImageMessage())
 //
 // You can also use DownloadAny to download the first non-nil sub-message.
+//
+// If Client.MediaCache is set, previously downloaded files are returned from the cache.
+// Media marked as view-once, which includes all media in received view-once messages, is never read from or written
+// to the cache, see also DownloadViewOnce.
 func (cli *Client) Download(msg DownloadableMessage) ([]byte, error) {
 	if cli == nil {
 		return nil, ErrClientIsNil
@@ -208,6 +213,23 @@ func (cli *Client) Download(msg DownloadableMessage) ([]byte, error) {
 	if mediaType == "" {
 		return nil, fmt.Errorf("%w %T", ErrUnknownMediaType, msg)
 	}
+	if cli.MediaCache == nil || isViewOnceMedia(msg) {
+		return cli.download(msg, mediaType)
+	}
+	ctx := context.TODO()
+	if data := cli.getCachedMedia(ctx, msg); data != nil {
+		return data, nil
+	}
+	data, err := cli.download(msg, mediaType)
+	if err == nil {
+		cli.cacheMedia(ctx, msg, data)
+	} else if errors.Is(err, ErrMediaDownloadFailedWith404) || errors.Is(err, ErrMediaDownloadFailedWith410) {
+		data, err = cli.downloadFromCachedUpload(ctx, msg, mediaType, err)
+	}
+	return data, err
+}
+
+func (cli *Client) download(msg DownloadableMessage, mediaType MediaType) ([]byte, error) {
 	urlable, ok := msg.(downloadableMessageWithURL)
 	var url string
 	var isWebWhatsappNetURL bool
@@ -310,8 +332,8 @@ func (cli *Client) downloadPossiblyEncryptedMediaWithRetries(url string, checksu
 	return
 }
 
-func (cli *Client) doMediaDownloadRequest(url string) (*http.Response, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+func (cli *Client) prepareMediaDownloadRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare request: %w", err)
 	}
@@ -321,6 +343,14 @@ func (cli *Client) doMediaDownloadRequest(url string) (*http.Response, error) {
 		req.Header.Set("User-Agent", cli.MessengerConfig.UserAgent)
 	}
 	// TODO user agent for whatsapp downloads?
+	return req, nil
+}
+
+func (cli *Client) doMediaDownloadRequest(url string) (*http.Response, error) {
+	req, err := cli.prepareMediaDownloadRequest(context.Background(), url)
+	if err != nil {
+		return nil, err
+	}
 	resp, err := cli.http.Do(req)
 	if err != nil {
 		return nil, err
@@ -25,6 +25,12 @@ var (
 
 	ErrAlreadyConnected = errors.New("websocket is already connected")
 
+	// ErrClientShuttingDown is returned by SendMessage and other methods that send data to the server after Client.Shutdown has been called.
+	ErrClientShuttingDown = errors.New("client is shutting down and not accepting new messages")
+	// ErrRateLimited is returned by presence, IsOnWhatsApp and group methods if Client.RateLimiter doesn't allow another action
+	// within RateLimitConfig.MaxQueryWait.
+	ErrRateLimited = errors.New("rate limit reached")
+
 	ErrQRAlreadyConnected = errors.New("GetQRChannel must be called before connecting")
 	ErrQRStoreContainsID  = errors.New("GetQRChannel can only be called when there's no user ID in the client's Store")
 
@@ -93,17 +99,27 @@ var (
 	ErrContactQRLinkNotFound = errors.New("that contact QR link does not exist or has been revoked")
 	// ErrInvalidImageFormat is returned by SetGroupPhoto if the given photo is not in the correct format.
 	ErrInvalidImageFormat = errors.New("the given data is not a valid image")
+	// ErrInvalidStickerFormat is returned by SendSticker if the given data is not a WebP image.
+	ErrInvalidStickerFormat = errors.New("stickers must be WebP images")
 	// ErrMediaNotAvailableOnPhone is returned by DecryptMediaRetryNotification if the given event contains error code 2.
 	ErrMediaNotAvailableOnPhone = errors.New("media no longer available on phone")
 	// ErrUnknownMediaRetryError is returned by DecryptMediaRetryNotification if the given event contains an unknown error code.
 	ErrUnknownMediaRetryError = errors.New("unknown media retry error")
+	// ErrMediaRetryTimeout is returned by DownloadWithAutoRetry if the phone doesn't respond to the media retry request in time.
+	ErrMediaRetryTimeout = errors.New("timed out waiting for media retry response")
+	// ErrMediaRetryFailed is returned by DownloadWithAutoRetry if the phone responds to the media retry request with a non-success result.
+	ErrMediaRetryFailed = errors.New("media retry failed")
+	// ErrScheduledMessageNotFound is returned by CancelScheduledMessage and RescheduleMessage if the given message isn't scheduled.
+	ErrScheduledMessageNotFound = errors.New("scheduled message not found")
 	// ErrInvalidDisappearingTimer is returned by SetDisappearingTimer if the given timer is not one of the allowed values.
 	ErrInvalidDisappearingTimer = errors.New("invalid disappearing timer provided")
 )
 
 // Some errors that Client.SendMessage can return
 var (
+	// Deprecated: non-status broadcast lists are supported now. ErrBroadcastListNotFound is returned for unknown lists.
 	ErrBroadcastListUnsupported = errors.New("sending to non-status broadcast lists is not yet supported")
+	ErrBroadcastListNotFound    = errors.New("broadcast list not found")
 	ErrUnknownServer            = errors.New("can't send message to unknown server")
 	ErrRecipientADJID           = errors.New("message recipient must be a user JID with no device part")
 	ErrServerReturnedError      = errors.New("server returned error")
@@ -136,12 +152,17 @@ var (
 	ErrInvalidMediaSHA256         = errors.New("hash of media plaintext doesn't match")
 	ErrUnknownMediaType           = errors.New("unknown media type")
 	ErrNothingDownloadableFound   = errors.New("didn't find any attachments in message")
+	ErrNotViewOnceMessage         = errors.New("given message isn't a view-once message")
+	ErrViewOnceMediaOpened        = errors.New("view-once media was already opened")
+	ErrViewOnceMediaExpired       = errors.New("view-once media expired or was discarded before being opened")
 )
 
 var (
 	ErrOriginalMessageSecretNotFound = errors.New("original message secret key not found")
 	ErrNotEncryptedReactionMessage   = errors.New("given message isn't an encrypted reaction message")
 	ErrNotPollUpdateMessage          = errors.New("given message isn't a poll update message")
+	ErrPollNotFound                  = errors.New("poll not found")
+	ErrMessageStatusNotFound         = errors.New("message status not found")
 )
 
 type wrappedIQError struct {
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
 	"github.com/pbribeiro/whatsmeow-mysql/types"
@@ -19,7 +20,13 @@ import (
 
 const InviteLinkPrefix = "https://chat.whatsapp.com/"
 
+// sendGroupIQ sends a group query after waiting for the rate limiter (if one is set).
+//
+// If Client.RateLimiter doesn't allow another query within RateLimitConfig.MaxQueryWait, ErrRateLimited is returned.
 func (cli *Client) sendGroupIQ(ctx context.Context, iqType infoQueryType, jid types.JID, content waBinary.Node) (*waBinary.Node, error) {
+	if err := cli.waitRateLimit(ctx); err != nil {
+		return nil, err
+	}
 	return cli.sendIQ(infoQuery{
 		Context:   ctx,
 		Namespace: "w:g2",
@@ -252,6 +259,9 @@ func (cli *Client) SetGroupPhoto(jid types.JID, avatar []byte) (string, error) {
 			Content: avatar,
 		}}
 	}
+	if err := cli.waitRateLimit(context.TODO()); err != nil {
+		return "", err
+	}
 	resp, err := cli.sendIQ(infoQuery{
 		Namespace: "w:profile:picture",
 		Type:      iqSet,
@@ -479,6 +489,8 @@ func (cli *Client) GetJoinedGroups() ([]*types.GroupInfo, error) {
 		parsed, parseErr := cli.parseGroupNode(&child)
 		if parseErr != nil {
 			cli.Log.Warnf("Error parsing group %s: %v", parsed.JID, parseErr)
+		} else {
+			cli.trackGroupDisappearingTimer(parsed.JID, &parsed.GroupEphemeral, time.Now())
 		}
 		infos = append(infos, parsed)
 	}
@@ -522,14 +534,27 @@ func (cli *Client) GetLinkedGroupsParticipants(community types.JID) ([]types.JID
 }
 
 // GetGroupInfo requests basic info about a group chat from the WhatsApp servers.
+//
+// Like all other group methods, this waits for Client.RateLimiter if it's set.
 func (cli *Client) GetGroupInfo(jid types.JID) (*types.GroupInfo, error) {
+	if err := cli.waitRateLimit(context.TODO()); err != nil {
+		return nil, err
+	}
 	return cli.getGroupInfo(context.TODO(), jid, true)
 }
 
 func (cli *Client) getGroupInfo(ctx context.Context, jid types.JID, lockParticipantCache bool) (*types.GroupInfo, error) {
-	res, err := cli.sendGroupIQ(ctx, iqGet, jid, waBinary.Node{
-		Tag:   "query",
-		Attrs: waBinary.Attrs{"request": "interactive"},
+	// This doesn't use sendGroupIQ, as it's also used internally when sending messages,
+	// which shouldn't take rate limit tokens. GetGroupInfo waits for the rate limiter itself.
+	res, err := cli.sendIQ(infoQuery{
+		Context:   ctx,
+		Namespace: "w:g2",
+		Type:      iqGet,
+		To:        jid,
+		Content: []waBinary.Node{{
+			Tag:   "query",
+			Attrs: waBinary.Attrs{"request": "interactive"},
+		}},
 	})
 	if errors.Is(err, ErrIQNotFound) {
 		return nil, wrapIQError(ErrGroupNotFound, err)
@@ -547,6 +572,7 @@ func (cli *Client) getGroupInfo(ctx context.Context, jid types.JID, lockParticip
 	if err != nil {
 		return groupInfo, err
 	}
+	cli.trackGroupDisappearingTimer(groupInfo.JID, &groupInfo.GroupEphemeral, time.Now())
 	if lockParticipantCache {
 		cli.groupParticipantsCacheLock.Lock()
 		defer cli.groupParticipantsCacheLock.Unlock()
@@ -656,6 +682,7 @@ func (cli *Client) parseGroupNode(groupNode *waBinary.Node) (*types.GroupInfo, e
 			cli.Log.Warnf("Possibly failed to parse %s element in group node: %+v", child.Tag, childAG.Errors)
 		}
 	}
+	cli.learnLIDMappingsFromParticipants(group.Participants)
 
 	return &group, ag.Error()
 }
@@ -866,13 +893,21 @@ Outer:
 func (cli *Client) parseGroupNotification(node *waBinary.Node) (any, error) {
 	children := node.GetChildren()
 	if len(children) == 1 && children[0].Tag == "create" {
-		return cli.parseGroupCreate(&children[0])
+		groupCreate, err := cli.parseGroupCreate(&children[0])
+		if err != nil {
+			return nil, err
+		}
+		cli.trackGroupDisappearingTimer(groupCreate.JID, &groupCreate.GroupEphemeral, groupCreate.GroupCreated)
+		return groupCreate, nil
 	} else {
 		groupChange, err := cli.parseGroupChange(node)
 		if err != nil {
 			return nil, err
 		}
 		cli.updateGroupParticipantCache(groupChange)
+		if groupChange.Ephemeral != nil {
+			cli.trackGroupDisappearingTimer(groupChange.JID, groupChange.Ephemeral, groupChange.Timestamp)
+		}
 		return groupChange, nil
 	}
 }
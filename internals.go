@@ -16,6 +16,7 @@ import (
 
 	"github.com/pbribeiro/whatsmeow-mysql/appstate"
 	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
+	"github.com/pbribeiro/whatsmeow-mysql/msgbuilder"
 	"github.com/pbribeiro/whatsmeow-mysql/proto/waCommon"
 	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
 	"github.com/pbribeiro/whatsmeow-mysql/proto/waHistorySync"
@@ -75,6 +76,10 @@ func (int *DangerousInternalClient) GetBroadcastListParticipants(jid types.JID)
 	return int.c.getBroadcastListParticipants(jid)
 }
 
+func (int *DangerousInternalClient) GetStoredBroadcastListRecipients(jid types.JID) ([]types.JID, error) {
+	return int.c.getStoredBroadcastListRecipients(jid)
+}
+
 func (int *DangerousInternalClient) GetStatusBroadcastRecipients() ([]types.JID, error) {
 	return int.c.getStatusBroadcastRecipients()
 }
@@ -83,6 +88,22 @@ func (int *DangerousInternalClient) HandleCallEvent(node *waBinary.Node) {
 	int.c.handleCallEvent(node)
 }
 
+func (int *DangerousInternalClient) DecryptCallKey(from types.JID, encNode *waBinary.Node) ([]byte, error) {
+	return int.c.decryptCallKey(from, encNode)
+}
+
+func (int *DangerousInternalClient) SendCallNode(to, callCreator types.JID, callID, tag string, attrs waBinary.Attrs, content []waBinary.Node) error {
+	return int.c.sendCallNode(to, callCreator, callID, tag, attrs, content)
+}
+
+func (int *DangerousInternalClient) ApplyCallPolicy(offer *events.CallOffer) {
+	int.c.applyCallPolicy(offer)
+}
+
+func (int *DangerousInternalClient) ShouldSendCallAutoReply(caller types.JID, interval time.Duration) bool {
+	return int.c.shouldSendCallAutoReply(caller, interval)
+}
+
 func (int *DangerousInternalClient) GetSocketWaitChan() <-chan struct{} {
 	return int.c.getSocketWaitChan()
 }
@@ -155,6 +176,114 @@ func (int *DangerousInternalClient) HandleConnectSuccess(node *waBinary.Node) {
 	int.c.handleConnectSuccess(node)
 }
 
+func (int *DangerousInternalClient) IncrementDecryptionStats(cause types.DecryptionFailureCause, delta types.DecryptionRecoveryStats) {
+	int.c.incrementDecryptionStats(cause, delta)
+}
+
+func (int *DangerousInternalClient) LoadDecryptionFailures() error {
+	return int.c.loadDecryptionFailures()
+}
+
+func (int *DangerousInternalClient) UpdateDecryptionFailure(info *types.MessageInfo, create bool, fn func(*types.DecryptionFailure)) {
+	int.c.updateDecryptionFailure(info, create, fn)
+}
+
+func (int *DangerousInternalClient) TrackDecryptionFailure(info *types.MessageInfo, cause types.DecryptionFailureCause) {
+	int.c.trackDecryptionFailure(info, cause)
+}
+
+func (int *DangerousInternalClient) TrackRetryReceiptSent(info *types.MessageInfo) {
+	int.c.trackRetryReceiptSent(info)
+}
+
+func (int *DangerousInternalClient) TrackPhoneRequestSent(info *types.MessageInfo) {
+	int.c.trackPhoneRequestSent(info)
+}
+
+func (int *DangerousInternalClient) TrackRetriesExhausted(info *types.MessageInfo) {
+	int.c.trackRetriesExhausted(info)
+}
+
+func (int *DangerousInternalClient) TrackDecryptionSuccess(info *types.MessageInfo, method events.DecryptionRecoveryMethod) {
+	int.c.trackDecryptionSuccess(info, method)
+}
+
+func (int *DangerousInternalClient) FinishDecryptionFailure(key decryptionFailureKey, recovered bool, makeEvent func(types.DecryptionFailure) any) {
+	int.c.finishDecryptionFailure(key, recovered, makeEvent)
+}
+
+func (int *DangerousInternalClient) DecryptionRecoveryLoop(ctx context.Context) {
+	int.c.decryptionRecoveryLoop(ctx)
+}
+
+func (int *DangerousInternalClient) SweepDecryptionFailures() {
+	int.c.sweepDecryptionFailures()
+}
+
+func (int *DangerousInternalClient) PutDisappearingTimer(chat types.JID, timer time.Duration, settingTimestamp time.Time) {
+	int.c.putDisappearingTimer(chat, timer, settingTimestamp)
+}
+
+func (int *DangerousInternalClient) TrackGroupDisappearingTimer(group types.JID, ephemeral *types.GroupEphemeral, timestamp time.Time) {
+	int.c.trackGroupDisappearingTimer(group, ephemeral, timestamp)
+}
+
+func (int *DangerousInternalClient) StoreHistoricalDisappearingTimers(conversations []*waHistorySync.Conversation) {
+	int.c.storeHistoricalDisappearingTimers(conversations)
+}
+
+func (int *DangerousInternalClient) ApplyDisappearingTimer(to types.JID, message *waE2E.Message) *waE2E.Message {
+	return int.c.applyDisappearingTimer(to, message)
+}
+
+func (int *DangerousInternalClient) TrackDisappearingMessage(evt *events.Message) {
+	int.c.trackDisappearingMessage(evt)
+}
+
+func (int *DangerousInternalClient) DisappearingMessageLoop(ctx context.Context) {
+	int.c.disappearingMessageLoop(ctx)
+}
+
+func (int *DangerousInternalClient) SweepExpiredMessages() {
+	int.c.sweepExpiredMessages()
+}
+
+func (int *DangerousInternalClient) GetNodeOrderingKey(node *waBinary.Node) string {
+	return int.c.getNodeOrderingKey(node)
+}
+
+func (int *DangerousInternalClient) IsOwnUser(jid types.JID) bool {
+	return int.c.isOwnUser(jid)
+}
+
+func (int *DangerousInternalClient) LearnLIDMappingsFromNode(node *waBinary.Node) {
+	int.c.learnLIDMappingsFromNode(node)
+}
+
+func (int *DangerousInternalClient) LearnLIDMappingsFromParticipants(participants []types.GroupParticipant) {
+	int.c.learnLIDMappingsFromParticipants(participants)
+}
+
+func (int *DangerousInternalClient) StoreLIDMapping(lid, pn types.JID) {
+	int.c.storeLIDMapping(lid, pn)
+}
+
+func (int *DangerousInternalClient) NormalizeLID(jid types.JID) types.JID {
+	return int.c.normalizeLID(jid)
+}
+
+func (int *DangerousInternalClient) ConcurrentHandlerQueueLoop(ctx context.Context) {
+	int.c.concurrentHandlerQueueLoop(ctx)
+}
+
+func (int *DangerousInternalClient) RunNodeHandler(node *waBinary.Node) {
+	int.c.runNodeHandler(node)
+}
+
+func (int *DangerousInternalClient) Download(msg DownloadableMessage, mediaType MediaType) ([]byte, error) {
+	return int.c.download(msg, mediaType)
+}
+
 func (int *DangerousInternalClient) DownloadAndDecrypt(url string, mediaKey []byte, appInfo MediaType, fileLength int, fileEncSHA256, fileSHA256 []byte) (data []byte, err error) {
 	return int.c.downloadAndDecrypt(url, mediaKey, appInfo, fileLength, fileEncSHA256, fileSHA256)
 }
@@ -163,6 +292,10 @@ func (int *DangerousInternalClient) DownloadPossiblyEncryptedMediaWithRetries(ur
 	return int.c.downloadPossiblyEncryptedMediaWithRetries(url, checksum)
 }
 
+func (int *DangerousInternalClient) PrepareMediaDownloadRequest(ctx context.Context, url string) (*http.Request, error) {
+	return int.c.prepareMediaDownloadRequest(ctx, url)
+}
+
 func (int *DangerousInternalClient) DoMediaDownloadRequest(url string) (*http.Response, error) {
 	return int.c.doMediaDownloadRequest(url)
 }
@@ -175,6 +308,10 @@ func (int *DangerousInternalClient) DownloadEncryptedMedia(url string, checksum
 	return int.c.downloadEncryptedMedia(url, checksum)
 }
 
+func (int *DangerousInternalClient) DoStreamMediaDownloadRequest(ctx context.Context, url string) (*http.Response, error) {
+	return int.c.doStreamMediaDownloadRequest(ctx, url)
+}
+
 func (int *DangerousInternalClient) DownloadAndDecryptToFile(url string, mediaKey []byte, appInfo MediaType, fileLength int, fileEncSHA256, fileSHA256 []byte, file File) error {
 	return int.c.downloadAndDecryptToFile(url, mediaKey, appInfo, fileLength, fileEncSHA256, fileSHA256, file)
 }
@@ -235,6 +372,30 @@ func (int *DangerousInternalClient) SendKeepAlive(ctx context.Context) (isSucces
 	return int.c.sendKeepAlive(ctx)
 }
 
+func (int *DangerousInternalClient) GetCachedUpload(ctx context.Context, mediaType MediaType, fileSHA256 []byte) *CachedUpload {
+	return int.c.getCachedUpload(ctx, mediaType, fileSHA256)
+}
+
+func (int *DangerousInternalClient) DeleteCachedUpload(ctx context.Context, mediaType MediaType, fileSHA256 []byte) {
+	int.c.deleteCachedUpload(ctx, mediaType, fileSHA256)
+}
+
+func (int *DangerousInternalClient) CacheUpload(ctx context.Context, mediaType MediaType, resp UploadResponse) {
+	int.c.cacheUpload(ctx, mediaType, resp)
+}
+
+func (int *DangerousInternalClient) GetCachedMedia(ctx context.Context, msg DownloadableMessage) []byte {
+	return int.c.getCachedMedia(ctx, msg)
+}
+
+func (int *DangerousInternalClient) CacheMedia(ctx context.Context, msg DownloadableMessage, data []byte) {
+	int.c.cacheMedia(ctx, msg, data)
+}
+
+func (int *DangerousInternalClient) DownloadFromCachedUpload(ctx context.Context, msg DownloadableMessage, mediaType MediaType, downloadErr error) ([]byte, error) {
+	return int.c.downloadFromCachedUpload(ctx, msg, mediaType, downloadErr)
+}
+
 func (int *DangerousInternalClient) RefreshMediaConn(force bool) (*MediaConn, error) {
 	return int.c.refreshMediaConn(force)
 }
@@ -247,6 +408,46 @@ func (int *DangerousInternalClient) HandleMediaRetryNotification(node *waBinary.
 	int.c.handleMediaRetryNotification(node)
 }
 
+func (int *DangerousInternalClient) AddMediaRetryWaiter(id types.MessageID) chan *events.MediaRetry {
+	return int.c.addMediaRetryWaiter(id)
+}
+
+func (int *DangerousInternalClient) RemoveMediaRetryWaiter(id types.MessageID, ch chan *events.MediaRetry) {
+	int.c.removeMediaRetryWaiter(id, ch)
+}
+
+func (int *DangerousInternalClient) TrackMessageState(evt *events.Message) {
+	int.c.trackMessageState(evt)
+}
+
+func (int *DangerousInternalClient) TrackDeleteForMe(evt *events.DeleteForMe, dispatch bool) {
+	int.c.trackDeleteForMe(evt, dispatch)
+}
+
+func (int *DangerousInternalClient) TrackOutgoingMessage(to, ownID types.JID, id types.MessageID, message *waE2E.Message, ts time.Time) {
+	int.c.trackOutgoingMessage(to, ownID, id, message, ts)
+}
+
+func (int *DangerousInternalClient) DispatchMessageStateChanged(chat, sender types.JID, id types.MessageID, change events.MessageStateChange, source *events.Message) {
+	int.c.dispatchMessageStateChanged(chat, sender, id, change, source)
+}
+
+func (int *DangerousInternalClient) TrackSentMessage(to types.JID, id types.MessageID, message *waE2E.Message, participants []types.JID, sentAt time.Time) {
+	int.c.trackSentMessage(to, id, message, participants, sentAt)
+}
+
+func (int *DangerousInternalClient) TrackReceipt(receipt *events.Receipt) {
+	int.c.trackReceipt(receipt)
+}
+
+func (int *DangerousInternalClient) MessageStatusPruneLoop(ctx context.Context) {
+	int.c.messageStatusPruneLoop(ctx)
+}
+
+func (int *DangerousInternalClient) PruneMessageStatuses() {
+	int.c.pruneMessageStatuses()
+}
+
 func (int *DangerousInternalClient) HandleEncryptedMessage(node *waBinary.Node) {
 	int.c.handleEncryptedMessage(node)
 }
@@ -439,6 +640,14 @@ func (int *DangerousInternalClient) SendPairError(id string, code int, text stri
 	int.c.sendPairError(id, code, text)
 }
 
+func (int *DangerousInternalClient) TrackPollCreation(info *types.MessageInfo, poll *waE2E.PollCreationMessage) {
+	int.c.trackPollCreation(info, poll)
+}
+
+func (int *DangerousInternalClient) TrackPollVote(evt *events.Message) {
+	int.c.trackPollVote(evt)
+}
+
 func (int *DangerousInternalClient) GetServerPreKeyCount() (int, error) {
 	return int.c.getServerPreKeyCount()
 }
@@ -451,6 +660,10 @@ func (int *DangerousInternalClient) FetchPreKeys(ctx context.Context, users []ty
 	return int.c.fetchPreKeys(ctx, users)
 }
 
+func (int *DangerousInternalClient) FetchPreKeysChunk(ctx context.Context, users []types.JID, respData map[types.JID]preKeyResp) error {
+	return int.c.fetchPreKeysChunk(ctx, users, respData)
+}
+
 func (int *DangerousInternalClient) HandleChatState(node *waBinary.Node) {
 	int.c.handleChatState(node)
 }
@@ -459,6 +672,30 @@ func (int *DangerousInternalClient) HandlePresence(node *waBinary.Node) {
 	int.c.handlePresence(node)
 }
 
+func (int *DangerousInternalClient) SendChatPresence(ctx context.Context, jid types.JID, state types.ChatPresence, media types.ChatPresenceMedia) error {
+	return int.c.sendChatPresence(ctx, jid, state, media)
+}
+
+func (int *DangerousInternalClient) PresenceHistoryPruneLoop(ctx context.Context) {
+	int.c.presenceHistoryPruneLoop(ctx)
+}
+
+func (int *DangerousInternalClient) PrunePresenceHistory() {
+	int.c.prunePresenceHistory()
+}
+
+func (int *DangerousInternalClient) ResubscribePresences() {
+	int.c.resubscribePresences()
+}
+
+func (int *DangerousInternalClient) TrackPresence(evt *events.Presence) {
+	int.c.trackPresence(evt)
+}
+
+func (int *DangerousInternalClient) TrackChatPresence(source types.MessageSource, state types.ChatPresence, media types.ChatPresenceMedia) {
+	int.c.trackChatPresence(source, state, media)
+}
+
 func (int *DangerousInternalClient) ParsePrivacySettings(privacyNode *waBinary.Node, settings *types.PrivacySettings) *events.PrivacySettings {
 	return int.c.parsePrivacySettings(privacyNode, settings)
 }
@@ -467,6 +704,18 @@ func (int *DangerousInternalClient) HandlePrivacySettingsNotification(privacyNod
 	int.c.handlePrivacySettingsNotification(privacyNode)
 }
 
+func (int *DangerousInternalClient) WaitRateLimit(ctx context.Context) error {
+	return int.c.waitRateLimit(ctx)
+}
+
+func (int *DangerousInternalClient) WaitMessageRateLimit(ctx context.Context, to types.JID, message *waE2E.Message) error {
+	return int.c.waitMessageRateLimit(ctx, to, message)
+}
+
+func (int *DangerousInternalClient) ThrottleRateLimiter(reason string, duration time.Duration) {
+	int.c.throttleRateLimiter(reason, duration)
+}
+
 func (int *DangerousInternalClient) HandleReceipt(node *waBinary.Node) {
 	int.c.handleReceipt(node)
 }
@@ -483,6 +732,10 @@ func (int *DangerousInternalClient) MaybeDeferredAck(node *waBinary.Node) func()
 	return int.c.maybeDeferredAck(node)
 }
 
+func (int *DangerousInternalClient) GoSendReceipt(fn func()) {
+	int.c.goSendReceipt(fn)
+}
+
 func (int *DangerousInternalClient) SendAck(node *waBinary.Node) {
 	int.c.sendAck(node)
 }
@@ -563,6 +816,26 @@ func (int *DangerousInternalClient) SendRetryReceipt(node *waBinary.Node, info *
 	int.c.sendRetryReceipt(node, info, forceIncludeIdentity)
 }
 
+func (int *DangerousInternalClient) LockScheduledMessage(id types.MessageID) (unlock func()) {
+	return int.c.lockScheduledMessage(id)
+}
+
+func (int *DangerousInternalClient) WakeScheduledMessageLoop() {
+	int.c.wakeScheduledMessageLoop()
+}
+
+func (int *DangerousInternalClient) ScheduledMessageLoop(ctx context.Context) {
+	int.c.scheduledMessageLoop(ctx)
+}
+
+func (int *DangerousInternalClient) SendDueScheduledMessages(ctx context.Context) (time.Time, error) {
+	return int.c.sendDueScheduledMessages(ctx)
+}
+
+func (int *DangerousInternalClient) SendScheduledMessage(ctx context.Context, id types.MessageID) {
+	int.c.sendScheduledMessage(ctx, id)
+}
+
 func (int *DangerousInternalClient) SendGroupV3(ctx context.Context, to, ownID types.JID, id types.MessageID, messageApp []byte, msgAttrs messageAttrs, frankingTag []byte, timings *MessageDebugTimings) (string, []byte, error) {
 	return int.c.sendGroupV3(ctx, to, ownID, id, messageApp, msgAttrs, frankingTag, timings)
 }
@@ -591,10 +864,14 @@ func (int *DangerousInternalClient) SendNewsletter(to types.JID, id types.Messag
 	return int.c.sendNewsletter(to, id, message, mediaID, timings)
 }
 
-func (int *DangerousInternalClient) SendGroup(ctx context.Context, to, ownID types.JID, id types.MessageID, message *waE2E.Message, timings *MessageDebugTimings, botNode *waBinary.Node) (string, []byte, error) {
+func (int *DangerousInternalClient) SendGroup(ctx context.Context, to, ownID types.JID, id types.MessageID, message *waE2E.Message, timings *MessageDebugTimings, botNode *waBinary.Node) (string, []types.JID, []byte, error) {
 	return int.c.sendGroup(ctx, to, ownID, id, message, timings, botNode)
 }
 
+func (int *DangerousInternalClient) SendBroadcastList(ctx context.Context, to types.JID, message *waE2E.Message, req SendRequestExtra) (resp SendResponse, err error) {
+	return int.c.sendBroadcastList(ctx, to, message, req)
+}
+
 func (int *DangerousInternalClient) SendPeerMessage(to types.JID, id types.MessageID, message *waE2E.Message, timings *MessageDebugTimings) ([]byte, error) {
 	return int.c.sendPeerMessage(to, id, message, timings)
 }
@@ -631,10 +908,34 @@ func (int *DangerousInternalClient) EncryptMessageForDevice(plaintext []byte, to
 	return int.c.encryptMessageForDevice(plaintext, to, bundle, extraAttrs)
 }
 
+func (int *DangerousInternalClient) UploadForSend(ctx context.Context, to types.JID, data []byte, mediaType MediaType, opts *MediaSendOptions) (msgbuilder.Media, error) {
+	return int.c.uploadForSend(ctx, to, data, mediaType, opts)
+}
+
+func (int *DangerousInternalClient) GetShutdownReport() ShutdownReport {
+	return int.c.getShutdownReport()
+}
+
+func (int *DangerousInternalClient) PruneReceivedStatuses() {
+	int.c.pruneReceivedStatuses()
+}
+
+func (int *DangerousInternalClient) TrackStatus(evt *events.Message) {
+	int.c.trackStatus(evt)
+}
+
+func (int *DangerousInternalClient) RecordTraffic(dir TrafficDirection, node *waBinary.Node, data []byte) {
+	int.c.recordTraffic(dir, node, data)
+}
+
 func (int *DangerousInternalClient) RawUpload(ctx context.Context, dataToUpload io.Reader, uploadSize uint64, fileHash []byte, appInfo MediaType, newsletter bool, resp *UploadResponse) error {
 	return int.c.rawUpload(ctx, dataToUpload, uploadSize, fileHash, appInfo, newsletter, resp)
 }
 
+func (int *DangerousInternalClient) UploadStreamWithRetries(ctx context.Context, appInfo MediaType, newsletter bool, uploadSize uint64, fileHash []byte, opts StreamUploadOptions, resp *UploadResponse, open openUploadBodyFunc, uploadedHash func() []byte) (err error) {
+	return int.c.uploadStreamWithRetries(ctx, appInfo, newsletter, uploadSize, fileHash, opts, resp, open, uploadedHash)
+}
+
 func (int *DangerousInternalClient) ParseBusinessProfile(node *waBinary.Node) (*types.BusinessProfile, error) {
 	return int.c.parseBusinessProfile(node)
 }
@@ -91,12 +91,12 @@ var writef func(format string, args ...any)
 func main() {
 	fset := token.NewFileSet()
 	fileNames := []string{
-		"appstate.go", "armadillomessage.go", "broadcast.go", "call.go", "client.go",
-		"connectionevents.go", "download.go", "download-to-file.go", "group.go", "handshake.go",
-		"keepalive.go", "mediaconn.go", "mediaretry.go", "message.go", "msgsecret.go",
-		"newsletter.go", "notification.go", "pair-code.go", "pair.go", "prekeys.go",
-		"presence.go", "privacysettings.go", "push.go", "qrchan.go", "receipt.go", "request.go",
-		"retry.go", "sendfb.go", "send.go", "upload.go", "user.go",
+		"appstate.go", "armadillomessage.go", "broadcast.go", "call.go", "callpolicy.go", "client.go",
+		"connectionevents.go", "decryptionrecovery.go", "disappearing.go", "dispatch.go", "download.go", "download-ranged.go", "download-stream.go", "download-to-file.go", "group.go", "handshake.go",
+		"keepalive.go", "mediacache.go", "mediaconn.go", "mediainfo.go", "mediaretry.go", "messagestate.go", "messagestatus.go", "message.go", "msgsecret.go",
+		"newsletter.go", "notification.go", "pair-code.go", "pair.go", "polls.go", "prekeys.go",
+		"presence.go", "presencetracker.go", "privacysettings.go", "push.go", "qrchan.go", "ratelimit.go", "receipt.go", "request.go",
+		"retry.go", "scheduler.go", "sendfb.go", "send.go", "sendmedia.go", "shutdown.go", "status.go", "subscribe.go", "traffic.go", "upload.go", "uploadstream.go", "user.go", "viewonce.go",
 	}
 	files := make([]*ast.File, len(fileNames))
 	for i, name := range fileNames {
@@ -0,0 +1,350 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DefaultMediaUploadTTL is the default duration for which FilesystemMediaCache keeps uploaded media
+// whose direct path doesn't say when the server copy expires.
+//
+// WhatsApp doesn't keep media on its servers forever, so old uploads must not be reused:
+// recipients would get a message whose attachment can't be downloaded.
+const DefaultMediaUploadTTL = 7 * 24 * time.Hour
+
+// MinCachedUploadValidity is how long the server copy of a cached upload must still be valid for it to be reused.
+// Recipients may download the media a while after it's sent, so uploads that are about to expire are uploaded again.
+var MinCachedUploadValidity = 24 * time.Hour
+
+// CachedUpload is a previous upload stored in a MediaCache.
+type CachedUpload struct {
+	UploadResponse
+	MediaType  MediaType
+	UploadedAt time.Time
+}
+
+// ExpiresAt returns the time when the server copy of the upload expires, based on the oe parameter of the direct path.
+// If the direct path doesn't contain an expiry, the zero time is returned.
+func (cu *CachedUpload) ExpiresAt() time.Time {
+	parsed, err := url.Parse(cu.DirectPath)
+	if err != nil {
+		return time.Time{}
+	}
+	expiry, err := strconv.ParseInt(parsed.Query().Get("oe"), 16, 64)
+	if err != nil || expiry <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(expiry, 0)
+}
+
+// MediaCache is a storage backend for deduplicating media uploads and downloads.
+//
+// Uploads are keyed by the media type and the SHA-256 hash of the plaintext, so uploading the same file again
+// will reuse the previous direct path and media key instead of uploading a new copy.
+// Downloads are keyed by the SHA-256 hash of the encrypted file (FileEncSHA256 in messages),
+// which is the same for all messages that forward the same upload.
+//
+// Errors returned by the cache are logged, but don't prevent uploading or downloading normally.
+//
+// The client checks the expiry in the direct path of cached uploads itself (see CachedUpload.ExpiresAt),
+// so implementations only need their own TTL for uploads whose direct path doesn't have one.
+type MediaCache interface {
+	// GetUpload returns a previous upload of the given file, or nil if there isn't one or it has expired.
+	GetUpload(ctx context.Context, mediaType MediaType, fileSHA256 []byte) (*CachedUpload, error)
+	// PutUpload stores an upload for reuse.
+	PutUpload(ctx context.Context, upload *CachedUpload) error
+	// DeleteUpload removes an upload, e.g. after the server copy has turned out to be gone.
+	DeleteUpload(ctx context.Context, mediaType MediaType, fileSHA256 []byte) error
+
+	// GetMedia returns the decrypted contents of the given file, or nil if it's not cached or has expired.
+	GetMedia(ctx context.Context, fileEncSHA256 []byte) ([]byte, error)
+	// PutMedia stores the decrypted contents of a downloaded file.
+	PutMedia(ctx context.Context, fileEncSHA256, data []byte) error
+}
+
+// FilesystemMediaCache is a MediaCache that stores uploads as JSON files and decrypted media as plain files in a directory.
+type FilesystemMediaCache struct {
+	Dir string
+	// How long uploads are kept. Uploads whose direct path has an expiry are also removed when that expiry is
+	// closer than MinCachedUploadValidity, even if they're younger than this. Defaults to DefaultMediaUploadTTL.
+	UploadTTL time.Duration
+	// How long downloaded media is kept. Zero means media is kept until it's deleted manually or with Prune.
+	MediaTTL time.Duration
+}
+
+var _ MediaCache = (*FilesystemMediaCache)(nil)
+
+// NewFilesystemMediaCache creates a new FilesystemMediaCache in the given directory, creating the directory if it doesn't exist.
+func NewFilesystemMediaCache(dir string) (*FilesystemMediaCache, error) {
+	for _, subdir := range []string{"uploads", "media"} {
+		err := os.MkdirAll(filepath.Join(dir, subdir), 0700)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create media cache directory: %w", err)
+		}
+	}
+	return &FilesystemMediaCache{Dir: dir, UploadTTL: DefaultMediaUploadTTL}, nil
+}
+
+type cachedUploadFile struct {
+	MediaType     MediaType `json:"media_type"`
+	UploadedAt    time.Time `json:"uploaded_at"`
+	URL           string    `json:"url"`
+	DirectPath    string    `json:"direct_path"`
+	Handle        string    `json:"handle,omitempty"`
+	ObjectID      string    `json:"object_id,omitempty"`
+	MediaKey      []byte    `json:"media_key"`
+	FileEncSHA256 []byte    `json:"file_enc_sha256"`
+	FileSHA256    []byte    `json:"file_sha256"`
+	FileLength    uint64    `json:"file_length"`
+}
+
+func (fmc *FilesystemMediaCache) uploadPath(mediaType MediaType, fileSHA256 []byte) string {
+	// The media type is part of the key because the encryption keys are derived from it
+	mediaTypeHash := sha256.Sum256([]byte(mediaType))
+	return filepath.Join(fmc.Dir, "uploads", fmt.Sprintf("%x-%x.json", mediaTypeHash[:4], fileSHA256))
+}
+
+func (fmc *FilesystemMediaCache) mediaPath(fileEncSHA256 []byte) string {
+	return filepath.Join(fmc.Dir, "media", fmt.Sprintf("%x", fileEncSHA256))
+}
+
+func (fmc *FilesystemMediaCache) uploadTTL() time.Duration {
+	if fmc.UploadTTL <= 0 {
+		return DefaultMediaUploadTTL
+	}
+	return fmc.UploadTTL
+}
+
+// writeFileAtomic writes the data to a temporary file and renames it over the target,
+// so that concurrent readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tempFile.Write(data)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFile.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tempFile.Name())
+	}
+	return err
+}
+
+func (fmc *FilesystemMediaCache) GetUpload(_ context.Context, mediaType MediaType, fileSHA256 []byte) (*CachedUpload, error) {
+	path := fmc.uploadPath(mediaType, fileSHA256)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var file cachedUploadFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cached upload: %w", err)
+	}
+	if time.Since(file.UploadedAt) > fmc.uploadTTL() {
+		_ = os.Remove(path)
+		return nil, nil
+	}
+	return &CachedUpload{
+		UploadResponse: UploadResponse{
+			URL:           file.URL,
+			DirectPath:    file.DirectPath,
+			Handle:        file.Handle,
+			ObjectID:      file.ObjectID,
+			MediaKey:      file.MediaKey,
+			FileEncSHA256: file.FileEncSHA256,
+			FileSHA256:    file.FileSHA256,
+			FileLength:    file.FileLength,
+		},
+		MediaType:  file.MediaType,
+		UploadedAt: file.UploadedAt,
+	}, nil
+}
+
+func (fmc *FilesystemMediaCache) PutUpload(_ context.Context, upload *CachedUpload) error {
+	data, err := json.Marshal(&cachedUploadFile{
+		MediaType:     upload.MediaType,
+		UploadedAt:    upload.UploadedAt,
+		URL:           upload.URL,
+		DirectPath:    upload.DirectPath,
+		Handle:        upload.Handle,
+		ObjectID:      upload.ObjectID,
+		MediaKey:      upload.MediaKey,
+		FileEncSHA256: upload.FileEncSHA256,
+		FileSHA256:    upload.FileSHA256,
+		FileLength:    upload.FileLength,
+	})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(fmc.uploadPath(upload.MediaType, upload.FileSHA256), data)
+}
+
+func (fmc *FilesystemMediaCache) DeleteUpload(_ context.Context, mediaType MediaType, fileSHA256 []byte) error {
+	err := os.Remove(fmc.uploadPath(mediaType, fileSHA256))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (fmc *FilesystemMediaCache) GetMedia(_ context.Context, fileEncSHA256 []byte) ([]byte, error) {
+	path := fmc.mediaPath(fileEncSHA256)
+	if fmc.MediaTTL > 0 {
+		stat, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		} else if time.Since(stat.ModTime()) > fmc.MediaTTL {
+			_ = os.Remove(path)
+			return nil, nil
+		}
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (fmc *FilesystemMediaCache) PutMedia(_ context.Context, fileEncSHA256, data []byte) error {
+	return writeFileAtomic(fmc.mediaPath(fileEncSHA256), data)
+}
+
+// Prune deletes all expired uploads and media from the cache directory.
+func (fmc *FilesystemMediaCache) Prune() error {
+	now := time.Now()
+	for subdir, ttl := range map[string]time.Duration{"uploads": fmc.uploadTTL(), "media": fmc.MediaTTL} {
+		if ttl <= 0 {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(fmc.Dir, subdir))
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", subdir, err)
+		}
+		for _, entry := range entries {
+			// Cached uploads are never rewritten, so the modification time is the upload time
+			info, err := entry.Info()
+			if err != nil || now.Sub(info.ModTime()) <= ttl {
+				continue
+			}
+			_ = os.Remove(filepath.Join(fmc.Dir, subdir, entry.Name()))
+		}
+	}
+	return nil
+}
+
+func (cli *Client) getCachedUpload(ctx context.Context, mediaType MediaType, fileSHA256 []byte) *CachedUpload {
+	cached, err := cli.MediaCache.GetUpload(ctx, mediaType, fileSHA256)
+	if err != nil {
+		cli.Log.Warnf("Failed to get cached upload of %X: %v", fileSHA256, err)
+		return nil
+	} else if cached == nil {
+		return nil
+	}
+	if expiry := cached.ExpiresAt(); !expiry.IsZero() && time.Until(expiry) < MinCachedUploadValidity {
+		cli.Log.Debugf("Server copy of cached upload %X expires at %s, not reusing it", fileSHA256, expiry)
+		cli.deleteCachedUpload(ctx, mediaType, fileSHA256)
+		return nil
+	}
+	return cached
+}
+
+func (cli *Client) deleteCachedUpload(ctx context.Context, mediaType MediaType, fileSHA256 []byte) {
+	err := cli.MediaCache.DeleteUpload(ctx, mediaType, fileSHA256)
+	if err != nil {
+		cli.Log.Warnf("Failed to delete cached upload of %X: %v", fileSHA256, err)
+	}
+}
+
+func (cli *Client) cacheUpload(ctx context.Context, mediaType MediaType, resp UploadResponse) {
+	err := cli.MediaCache.PutUpload(ctx, &CachedUpload{
+		UploadResponse: resp,
+		MediaType:      mediaType,
+		UploadedAt:     time.Now(),
+	})
+	if err != nil {
+		cli.Log.Warnf("Failed to cache upload of %X: %v", resp.FileSHA256, err)
+	}
+}
+
+func (cli *Client) getCachedMedia(ctx context.Context, msg DownloadableMessage) []byte {
+	encSHA256 := msg.GetFileEncSHA256()
+	if len(encSHA256) != 32 {
+		return nil
+	}
+	data, err := cli.MediaCache.GetMedia(ctx, encSHA256)
+	if err != nil {
+		cli.Log.Warnf("Failed to get cached media %X: %v", encSHA256, err)
+		return nil
+	} else if data == nil {
+		return nil
+	}
+	fileSHA256 := msg.GetFileSHA256()
+	if len(fileSHA256) == 32 && sha256.Sum256(data) != *(*[32]byte)(fileSHA256) {
+		cli.Log.Warnf("Cached media %X doesn't match expected hash, ignoring it", encSHA256)
+		return nil
+	}
+	return data
+}
+
+func (cli *Client) cacheMedia(ctx context.Context, msg DownloadableMessage, data []byte) {
+	encSHA256 := msg.GetFileEncSHA256()
+	if len(encSHA256) != 32 {
+		return
+	}
+	err := cli.MediaCache.PutMedia(ctx, encSHA256, data)
+	if err != nil {
+		cli.Log.Warnf("Failed to cache media %X: %v", encSHA256, err)
+	}
+}
+
+// downloadFromCachedUpload is called when downloading media fails because the server copy is gone.
+//
+// If the file was uploaded by us, the cached upload either points to the same dead copy and is invalidated,
+// or it points to a newer upload of the same file, which is downloaded instead. If neither works, the original
+// error is returned, so that DownloadWithAutoRetry can continue with a media retry receipt.
+func (cli *Client) downloadFromCachedUpload(ctx context.Context, msg DownloadableMessage, mediaType MediaType, downloadErr error) ([]byte, error) {
+	fileSHA256 := msg.GetFileSHA256()
+	if len(fileSHA256) != 32 {
+		return nil, downloadErr
+	}
+	cached := cli.getCachedUpload(ctx, mediaType, fileSHA256)
+	if cached == nil {
+		return nil, downloadErr
+	} else if cached.DirectPath == msg.GetDirectPath() || bytes.Equal(cached.FileEncSHA256, msg.GetFileEncSHA256()) {
+		cli.Log.Debugf("Server copy of cached upload %X is gone, removing it from cache", fileSHA256)
+		cli.deleteCachedUpload(ctx, mediaType, fileSHA256)
+		return nil, downloadErr
+	}
+	cli.Log.Debugf("Downloading %X using newer cached upload after %v", fileSHA256, downloadErr)
+	data, err := cli.DownloadMediaWithPath(cached.DirectPath, cached.FileEncSHA256, cached.FileSHA256, cached.MediaKey, int(cached.FileLength), mediaType, "")
+	if err != nil {
+		return nil, downloadErr
+	}
+	return data, nil
+}
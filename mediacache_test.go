@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+func directPathExpiringAt(expiry time.Time) string {
+	return fmt.Sprintf("/v/t62.7119-24/12345_67890_n.enc?ccb=11-4&oh=01_abc&oe=%X&_nc_sid=5e03e0", expiry.Unix())
+}
+
+func TestCachedUploadExpiresAt(t *testing.T) {
+	expiry := time.Unix(1767225600, 0)
+	tests := []struct {
+		name       string
+		directPath string
+		expected   time.Time
+	}{
+		{"WithExpiry", directPathExpiringAt(expiry), expiry},
+		{"WithoutExpiry", "/v/t62.7119-24/12345_67890_n.enc?ccb=11-4", time.Time{}},
+		{"InvalidExpiry", "/v/t62.7119-24/12345_67890_n.enc?oe=xyz", time.Time{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cu := &CachedUpload{UploadResponse: UploadResponse{DirectPath: test.directPath}}
+			if expiresAt := cu.ExpiresAt(); !expiresAt.Equal(test.expected) {
+				t.Errorf("expected expiry %s, got %s", test.expected, expiresAt)
+			}
+		})
+	}
+}
+
+func TestCachedUploadExpiry(t *testing.T) {
+	cache, err := NewFilesystemMediaCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	cli := newFakeMediaClient(t, http.NotFoundHandler())
+	cli.MediaCache = cache
+	ctx := context.Background()
+	upload := func(name string, directPath string) []byte {
+		fileSHA256 := []byte(strings.Repeat(name[:1], 32))
+		err := cache.PutUpload(ctx, &CachedUpload{
+			UploadResponse: UploadResponse{DirectPath: directPath, FileSHA256: fileSHA256},
+			MediaType:      MediaImage,
+			UploadedAt:     time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("failed to cache upload: %v", err)
+		}
+		return fileSHA256
+	}
+
+	valid := upload("valid", directPathExpiringAt(time.Now().Add(7*24*time.Hour)))
+	if cli.getCachedUpload(ctx, MediaImage, valid) == nil {
+		t.Error("expected upload with a distant expiry to be reused")
+	}
+	expiring := upload("expiring", directPathExpiringAt(time.Now().Add(time.Hour)))
+	if cli.getCachedUpload(ctx, MediaImage, expiring) != nil {
+		t.Error("expected upload that is about to expire not to be reused")
+	} else if cached, _ := cache.GetUpload(ctx, MediaImage, expiring); cached != nil {
+		t.Error("expected upload that is about to expire to be removed from the cache")
+	}
+	noExpiry := upload("none", "/v/t62.7119-24/12345_67890_n.enc?ccb=11-4")
+	if cli.getCachedUpload(ctx, MediaImage, noExpiry) == nil {
+		t.Error("expected upload without expiry to be reused until the cache TTL")
+	}
+}
+
+func TestDownloadWithAutoRetryAfterCachedUploadIsGone(t *testing.T) {
+	cache, err := NewFilesystemMediaCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	var downloads atomic.Int32
+	cli := newFakeMediaClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	ownID := types.JID{User: "1000", Device: 2, Server: types.DefaultUserServer}
+	cli.Store = &store.Device{ID: &ownID}
+	cli.MediaCache = cache
+	cli.mediaRetryWaiters = make(map[types.MessageID][]chan<- *events.MediaRetry)
+	ctx := context.Background()
+
+	tm := makeTestMedia(t, 1000)
+	tm.msg.DirectPath = proto.String(directPathExpiringAt(time.Now().Add(7 * 24 * time.Hour)))
+	err = cache.PutUpload(ctx, &CachedUpload{
+		UploadResponse: UploadResponse{
+			DirectPath:    tm.msg.GetDirectPath(),
+			MediaKey:      tm.msg.GetMediaKey(),
+			FileEncSHA256: tm.msg.GetFileEncSHA256(),
+			FileSHA256:    tm.msg.GetFileSHA256(),
+			FileLength:    tm.msg.GetFileLength(),
+		},
+		MediaType:  MediaDocument,
+		UploadedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to cache upload: %v", err)
+	}
+
+	info := &types.MessageInfo{ID: "msg-1", MessageSource: types.MessageSource{Chat: ownID.ToNonAD(), Sender: ownID, IsFromMe: true}}
+	_, err = cli.DownloadWithAutoRetry(ctx, info, tm.msg)
+	// There's no websocket, so the retry receipt can't actually be sent, but it must be attempted
+	if !errors.Is(err, ErrNotConnected) || !strings.Contains(err.Error(), "media retry receipt") {
+		t.Errorf("expected download to fall through to the media retry flow, got %v", err)
+	}
+	if cached, _ := cache.GetUpload(ctx, MediaDocument, tm.msg.GetFileSHA256()); cached != nil {
+		t.Error("expected dead cached upload to be removed")
+	}
+	if downloads.Load() == 0 {
+		t.Error("expected the media server to be asked for the file")
+	}
+}
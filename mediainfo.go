@@ -0,0 +1,244 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"math"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// thumbnailMaxSize is the maximum width and height of generated inline JPEG thumbnails.
+const thumbnailMaxSize = 72
+
+// mediaInfo contains metadata extracted from a media file.
+type mediaInfo struct {
+	Width     uint32
+	Height    uint32
+	Duration  time.Duration
+	Animated  bool
+	Thumbnail []byte
+}
+
+// detectMimeType sniffs the MIME type of the given file, falling back to the file extension if sniffing doesn't give a specific type.
+func detectMimeType(data []byte, fileName string) string {
+	mimeType := http.DetectContentType(data)
+	if mimeType == "application/octet-stream" || strings.HasPrefix(mimeType, "text/plain") {
+		if extType := mime.TypeByExtension(filepath.Ext(fileName)); extType != "" {
+			return extType
+		}
+	}
+	return mimeType
+}
+
+// getImageInfo decodes the given JPEG, PNG or GIF image to find its dimensions and generate a thumbnail.
+// WebP images only get dimensions, as there's no decoder for them in the standard library.
+func getImageInfo(data []byte) (info mediaInfo, ok bool) {
+	if width, height, animated, isWebP := getWebPInfo(data); isWebP {
+		return mediaInfo{Width: width, Height: height, Animated: animated}, true
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	bounds := img.Bounds()
+	info.Width = uint32(bounds.Dx())
+	info.Height = uint32(bounds.Dy())
+	info.Thumbnail, _ = makeJPEGThumbnail(img)
+	return info, true
+}
+
+// makeJPEGThumbnail scales the image down to fit in thumbnailMaxSize using area averaging and encodes it as a JPEG.
+func makeJPEGThumbnail(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if srcWidth == 0 || srcHeight == 0 {
+		return nil, ErrInvalidImageFormat
+	}
+	dstWidth, dstHeight := srcWidth, srcHeight
+	if dstWidth > thumbnailMaxSize || dstHeight > thumbnailMaxSize {
+		if srcWidth > srcHeight {
+			dstWidth = thumbnailMaxSize
+			dstHeight = max(1, srcHeight*thumbnailMaxSize/srcWidth)
+		} else {
+			dstHeight = thumbnailMaxSize
+			dstWidth = max(1, srcWidth*thumbnailMaxSize/srcHeight)
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		srcY0 := bounds.Min.Y + y*srcHeight/dstHeight
+		srcY1 := max(srcY0+1, bounds.Min.Y+(y+1)*srcHeight/dstHeight)
+		for x := 0; x < dstWidth; x++ {
+			srcX0 := bounds.Min.X + x*srcWidth/dstWidth
+			srcX1 := max(srcX0+1, bounds.Min.X+(x+1)*srcWidth/dstWidth)
+			var r, g, b, a, n uint64
+			for sy := srcY0; sy < srcY1; sy++ {
+				for sx := srcX0; sx < srcX1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa), n+1
+				}
+			}
+			offset := dst.PixOffset(x, y)
+			// JPEG doesn't have transparency, so blend transparent pixels onto white
+			white := (n*0xffff - a) >> 8
+			dst.Pix[offset] = uint8(min(255, (r>>8+white)/n))
+			dst.Pix[offset+1] = uint8(min(255, (g>>8+white)/n))
+			dst.Pix[offset+2] = uint8(min(255, (b>>8+white)/n))
+			dst.Pix[offset+3] = 255
+		}
+	}
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 60})
+	return buf.Bytes(), err
+}
+
+// getWebPInfo reads the dimensions and animation flag from the header of a WebP image.
+func getWebPInfo(data []byte) (width, height uint32, animated, ok bool) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return
+	}
+	switch string(data[12:16]) {
+	case "VP8 ":
+		// Lossy: 3-byte frame tag and 3-byte start code, then 14-bit dimensions
+		if !bytes.Equal(data[23:26], []byte{0x9d, 0x01, 0x2a}) {
+			return
+		}
+		width = uint32(binary.LittleEndian.Uint16(data[26:28]) & 0x3fff)
+		height = uint32(binary.LittleEndian.Uint16(data[28:30]) & 0x3fff)
+	case "VP8L":
+		// Lossless: 1-byte signature, then 14-bit dimensions minus one
+		if data[20] != 0x2f {
+			return
+		}
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		width = bits&0x3fff + 1
+		height = (bits>>14)&0x3fff + 1
+	case "VP8X":
+		// Extended: 1-byte flags, 3 reserved bytes, then 24-bit canvas dimensions minus one
+		animated = data[20]&0x02 != 0
+		width = uint32(data[24]) | uint32(data[25])<<8 | uint32(data[26])<<16 + 1
+		height = uint32(data[27]) | uint32(data[28])<<8 | uint32(data[29])<<16 + 1
+	default:
+		return
+	}
+	ok = true
+	return
+}
+
+// getMP4Info reads the duration and video dimensions from the moov box of an MP4 file.
+//
+// The moov box must be complete and contain a movie header, otherwise the file is considered invalid.
+func getMP4Info(data []byte) (info mediaInfo, ok bool) {
+	moov := findMP4Box(data, "moov")
+	if moov == nil {
+		return
+	}
+	mvhd := findMP4Box(moov, "mvhd")
+	var timescale uint32
+	var duration uint64
+	if len(mvhd) >= 32 && mvhd[0] == 1 {
+		timescale = binary.BigEndian.Uint32(mvhd[20:24])
+		duration = binary.BigEndian.Uint64(mvhd[24:32])
+	} else if len(mvhd) >= 20 && mvhd[0] == 0 {
+		timescale = binary.BigEndian.Uint32(mvhd[12:16])
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	} else {
+		return
+	}
+	if timescale > 0 {
+		info.Duration = time.Duration(duration) * time.Second / time.Duration(timescale)
+	}
+	for trak := range iterMP4Boxes(moov, "trak") {
+		tkhd := findMP4Box(trak, "tkhd")
+		// Width and height are the last two 16.16 fixed point fields
+		if len(tkhd) < 84 {
+			continue
+		}
+		dimOffset := 76
+		if tkhd[0] == 1 {
+			dimOffset = 88
+		}
+		if len(tkhd) < dimOffset+8 {
+			continue
+		}
+		width := binary.BigEndian.Uint32(tkhd[dimOffset:dimOffset+4]) >> 16
+		height := binary.BigEndian.Uint32(tkhd[dimOffset+4:dimOffset+8]) >> 16
+		if width > 0 && height > 0 {
+			info.Width, info.Height = width, height
+			break
+		}
+	}
+	return info, true
+}
+
+// iterMP4Boxes iterates over the contents of the boxes with the given type at the top level of data.
+func iterMP4Boxes(data []byte, boxType string) func(yield func([]byte) bool) {
+	return func(yield func([]byte) bool) {
+		for len(data) >= 8 {
+			size := uint64(binary.BigEndian.Uint32(data[0:4]))
+			headerSize := uint64(8)
+			if size == 1 {
+				if len(data) < 16 {
+					return
+				}
+				size = binary.BigEndian.Uint64(data[8:16])
+				headerSize = 16
+			} else if size == 0 {
+				size = uint64(len(data))
+			}
+			if size < headerSize || size > uint64(len(data)) {
+				return
+			}
+			if string(data[4:8]) == boxType && !yield(data[headerSize:size]) {
+				return
+			}
+			data = data[size:]
+		}
+	}
+}
+
+func findMP4Box(data []byte, boxType string) []byte {
+	for box := range iterMP4Boxes(data, boxType) {
+		return box
+	}
+	return nil
+}
+
+// getOggDuration calculates the duration of an Ogg Opus or Vorbis file from the granule position of the last page.
+func getOggDuration(data []byte) (time.Duration, bool) {
+	if len(data) < 28 || string(data[0:4]) != "OggS" {
+		return 0, false
+	}
+	var sampleRate, preSkip uint64
+	if idx := bytes.Index(data, []byte("OpusHead")); idx >= 0 && len(data) >= idx+12 {
+		// Opus granule positions are always at 48 kHz regardless of the input sample rate
+		sampleRate = 48000
+		preSkip = uint64(binary.LittleEndian.Uint16(data[idx+10 : idx+12]))
+	} else if idx = bytes.Index(data, []byte("\x01vorbis")); idx >= 0 && len(data) >= idx+16 {
+		sampleRate = uint64(binary.LittleEndian.Uint32(data[idx+12 : idx+16]))
+	}
+	lastPage := bytes.LastIndex(data, []byte("OggS"))
+	if sampleRate == 0 || len(data) < lastPage+14 {
+		return 0, false
+	}
+	granule := binary.LittleEndian.Uint64(data[lastPage+6 : lastPage+14])
+	// A granule position of -1 means no packet ends on the page, which only happens with truncated files
+	if granule == math.MaxUint64 || granule < preSkip {
+		return 0, false
+	}
+	return time.Duration(granule-preSkip) * time.Second / time.Duration(sampleRate), true
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func makeWebP(chunk string, payload []byte) []byte {
+	data := []byte("RIFF\x00\x00\x00\x00WEBP" + chunk)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(payload)))
+	data = append(data, payload...)
+	binary.LittleEndian.PutUint32(data[4:8], uint32(len(data)-8))
+	return data
+}
+
+func makeVP8Payload(startCode []byte, width, height uint16) []byte {
+	payload := append([]byte{0x50, 0x2a, 0x00}, startCode...)
+	payload = binary.LittleEndian.AppendUint16(payload, width)
+	return binary.LittleEndian.AppendUint16(payload, height)
+}
+
+func makeVP8LPayload(width, height uint32) []byte {
+	payload := binary.LittleEndian.AppendUint32([]byte{0x2f}, (width-1)|(height-1)<<14)
+	// Real lossless images always have image data after the header
+	return append(payload, 0, 0, 0, 0, 0)
+}
+
+func makeVP8XPayload(flags byte, width, height uint32) []byte {
+	return []byte{
+		flags, 0, 0, 0,
+		byte(width - 1), byte((width - 1) >> 8), byte((width - 1) >> 16),
+		byte(height - 1), byte((height - 1) >> 8), byte((height - 1) >> 16),
+	}
+}
+
+func TestGetWebPInfo(t *testing.T) {
+	vp8 := makeWebP("VP8 ", makeVP8Payload([]byte{0x9d, 0x01, 0x2a}, 640, 480))
+	tests := []struct {
+		name                  string
+		data                  []byte
+		width, height         uint32
+		animated, expectValid bool
+	}{
+		{"lossy", vp8, 640, 480, false, true},
+		{"lossless", makeWebP("VP8L", makeVP8LPayload(100, 50)), 100, 50, false, true},
+		{"extended", makeWebP("VP8X", makeVP8XPayload(0x00, 512, 512)), 512, 512, false, true},
+		{"extended animated", makeWebP("VP8X", makeVP8XPayload(0x02, 1, 70000)), 1, 70000, true, true},
+		{"truncated lossy", vp8[:28], 0, 0, false, false},
+		{"only RIFF header", vp8[:12], 0, 0, false, false},
+		{"lossy without start code", makeWebP("VP8 ", makeVP8Payload([]byte{1, 2, 3}, 640, 480)), 0, 0, false, false},
+		{"lossless without signature", makeWebP("VP8L", slices.Concat([]byte{0x00}, makeVP8LPayload(100, 50)[1:])), 0, 0, false, false},
+		{"unknown chunk", makeWebP("ALPH", make([]byte, 10)), 0, 0, false, false},
+		{"not webp", append([]byte("RIFF\x00\x00\x00\x00WAVE"), make([]byte, 20)...), 0, 0, false, false},
+	}
+	for _, test := range tests {
+		width, height, animated, ok := getWebPInfo(test.data)
+		if ok != test.expectValid {
+			t.Errorf("%s: expected ok=%t, got %t", test.name, test.expectValid, ok)
+		} else if width != test.width || height != test.height || animated != test.animated {
+			t.Errorf("%s: expected %dx%d (animated: %t), got %dx%d (animated: %t)",
+				test.name, test.width, test.height, test.animated, width, height, animated)
+		}
+	}
+}
+
+func TestGetImageInfo(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 200, 100))); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	info, ok := getImageInfo(buf.Bytes())
+	if !ok || info.Width != 200 || info.Height != 100 || len(info.Thumbnail) == 0 {
+		t.Errorf("unexpected info for PNG: %t %+v", ok, info)
+	}
+	if _, ok = getImageInfo(buf.Bytes()[:buf.Len()/2]); ok {
+		t.Error("expected truncated PNG to be rejected")
+	}
+	if _, ok = getImageInfo(makeWebP("VP8 ", makeVP8Payload([]byte{0x9d, 0x01, 0x2a}, 640, 480))[:25]); ok {
+		t.Error("expected truncated WebP to be rejected")
+	}
+}
+
+func makeMP4Box(boxType string, payloads ...[]byte) []byte {
+	content := bytes.Join(payloads, nil)
+	box := binary.BigEndian.AppendUint32(nil, uint32(8+len(content)))
+	return append(append(box, boxType...), content...)
+}
+
+func makeMVHD(version byte, timescale uint32, duration uint64) []byte {
+	mvhd := make([]byte, 100)
+	mvhd[0] = version
+	if version == 1 {
+		binary.BigEndian.PutUint32(mvhd[20:24], timescale)
+		binary.BigEndian.PutUint64(mvhd[24:32], duration)
+	} else {
+		binary.BigEndian.PutUint32(mvhd[12:16], timescale)
+		binary.BigEndian.PutUint32(mvhd[16:20], uint32(duration))
+	}
+	return makeMP4Box("mvhd", mvhd)
+}
+
+func makeTKHD(version byte, width, height uint32) []byte {
+	tkhd := make([]byte, 84)
+	dimOffset := 76
+	if version == 1 {
+		tkhd = make([]byte, 96)
+		dimOffset = 88
+	}
+	tkhd[0] = version
+	binary.BigEndian.PutUint32(tkhd[dimOffset:], width<<16)
+	binary.BigEndian.PutUint32(tkhd[dimOffset+4:], height<<16)
+	return makeMP4Box("tkhd", tkhd)
+}
+
+func TestGetMP4Info(t *testing.T) {
+	ftyp := makeMP4Box("ftyp", []byte("isom\x00\x00\x02\x00"))
+	mdat := makeMP4Box("mdat", make([]byte, 64))
+	moov := makeMP4Box("moov",
+		makeMVHD(0, 1000, 5500),
+		makeMP4Box("trak", makeTKHD(0, 0, 0)),
+		makeMP4Box("trak", makeTKHD(0, 640, 360)),
+	)
+	valid := bytes.Join([][]byte{ftyp, moov, mdat}, nil)
+	tests := []struct {
+		name          string
+		data          []byte
+		duration      time.Duration
+		width, height uint32
+		expectValid   bool
+	}{
+		{"version 0", valid, 5500 * time.Millisecond, 640, 360, true},
+		{"version 1", bytes.Join([][]byte{ftyp, makeMP4Box("moov", makeMVHD(1, 90000, 90000*12), makeMP4Box("trak", makeTKHD(1, 1280, 720)))}, nil), 12 * time.Second, 1280, 720, true},
+		{"moov at end", bytes.Join([][]byte{ftyp, mdat, moov}, nil), 5500 * time.Millisecond, 640, 360, true},
+		{"audio only", makeMP4Box("moov", makeMVHD(0, 44100, 44100*3), makeMP4Box("trak", makeTKHD(0, 0, 0))), 3 * time.Second, 0, 0, true},
+		{"truncated moov", valid[:len(ftyp)+len(moov)-10], 0, 0, 0, false},
+		{"truncated box header", valid[:len(ftyp)+4], 0, 0, 0, false},
+		{"no moov", bytes.Join([][]byte{ftyp, mdat}, nil), 0, 0, 0, false},
+		{"truncated mvhd", makeMP4Box("moov", makeMP4Box("mvhd", make([]byte, 10))), 0, 0, 0, false},
+		{"short version 1 mvhd", makeMP4Box("moov", makeMP4Box("mvhd", append([]byte{1}, make([]byte, 23)...))), 0, 0, 0, false},
+		{"box smaller than header", append(binary.BigEndian.AppendUint32(nil, 4), "moov"...), 0, 0, 0, false},
+	}
+	for _, test := range tests {
+		info, ok := getMP4Info(test.data)
+		if ok != test.expectValid {
+			t.Errorf("%s: expected ok=%t, got %t", test.name, test.expectValid, ok)
+		} else if info.Duration != test.duration || info.Width != test.width || info.Height != test.height {
+			t.Errorf("%s: expected %s %dx%d, got %s %dx%d",
+				test.name, test.duration, test.width, test.height, info.Duration, info.Width, info.Height)
+		}
+	}
+}
+
+func makeOggPage(granule uint64, payload []byte) []byte {
+	page := []byte("OggS\x00\x00")
+	page = binary.LittleEndian.AppendUint64(page, granule)
+	// Serial number, sequence number and checksum aren't used
+	page = append(page, make([]byte, 12)...)
+	page = append(page, 1, byte(len(payload)))
+	return append(page, payload...)
+}
+
+func TestGetOggDuration(t *testing.T) {
+	opusHead := binary.LittleEndian.AppendUint16([]byte("OpusHead\x01\x01"), 312)
+	opusHead = binary.LittleEndian.AppendUint32(opusHead, 16000)
+	opus := bytes.Join([][]byte{
+		makeOggPage(0, opusHead),
+		makeOggPage(0, []byte("OpusTags")),
+		makeOggPage(48000*3+312, make([]byte, 32)),
+	}, nil)
+	vorbisHead := binary.LittleEndian.AppendUint32([]byte("\x01vorbis\x00\x00\x00\x00\x02"), 44100)
+	vorbis := bytes.Join([][]byte{
+		makeOggPage(0, vorbisHead),
+		makeOggPage(44100*2, make([]byte, 32)),
+	}, nil)
+	tests := []struct {
+		name        string
+		data        []byte
+		duration    time.Duration
+		expectValid bool
+	}{
+		{"opus", opus, 3 * time.Second, true},
+		{"vorbis", vorbis, 2 * time.Second, true},
+		{"truncated last page header", slices.Concat(opus[:len(opus)-42], []byte("OggS\x00\x00\x01")), 0, false},
+		{"no packet ends on last page", slices.Concat(opus, makeOggPage(math.MaxUint64, nil)), 0, false},
+		{"truncated opus header", makeOggPage(0, []byte("OpusHead\x01")), 0, false},
+		{"granule before pre-skip", bytes.Join([][]byte{makeOggPage(0, opusHead), makeOggPage(100, nil)}, nil), 0, false},
+		{"unknown codec", makeOggPage(48000, []byte("FLAC")), 0, false},
+		{"not ogg", []byte("ID3\x04\x00\x00\x00\x00\x00\x00" + string(make([]byte, 32))), 0, false},
+	}
+	for _, test := range tests {
+		duration, ok := getOggDuration(test.data)
+		if ok != test.expectValid {
+			t.Errorf("%s: expected ok=%t, got %t", test.name, test.expectValid, ok)
+		} else if duration != test.duration {
+			t.Errorf("%s: expected duration %s, got %s", test.name, test.duration, duration)
+		}
+	}
+}
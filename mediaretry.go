@@ -7,7 +7,11 @@
 package whatsmeow
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"go.mau.fi/util/random"
 	"google.golang.org/protobuf/proto"
@@ -73,9 +77,19 @@ func encryptMediaRetryReceipt(messageID types.MessageID, mediaKey []byte) (ciphe
 //	    // Alternatively, you can use cli.DownloadMediaWithPath and provide the individual fields manually.
 //	  }
 //	}
+//
+// DownloadWithAutoRetry can be used to do all of the above automatically.
+//
+// If Client.MediaCache is set, Download first tries to handle 404 and 410 errors by itself: files that were downloaded
+// before are returned from the cache, and files that were uploaded by this client are downloaded from a newer upload of
+// the same file if there is one. Cached uploads whose server copy is gone are removed from the cache, so the file is
+// uploaded again the next time it's sent. If the cache can't provide the file, Download still returns the 404 or 410 error,
+// and a retry receipt is needed like without a cache. DownloadWithAutoRetry does both steps automatically.
 func (cli *Client) SendMediaRetryReceipt(message *types.MessageInfo, mediaKey []byte) error {
 	if cli == nil {
 		return ErrClientIsNil
+	} else if cli.IsShuttingDown() {
+		return ErrClientShuttingDown
 	}
 	ciphertext, iv, err := encryptMediaRetryReceipt(message.ID, mediaKey)
 	if err != nil {
@@ -180,5 +194,93 @@ func (cli *Client) handleMediaRetryNotification(node *waBinary.Node) {
 		cli.Log.Warnf("Failed to parse media retry notification: %v", err)
 		return
 	}
+	cli.mediaRetryWaitersLock.Lock()
+	waiters := cli.mediaRetryWaiters[evt.MessageID]
+	delete(cli.mediaRetryWaiters, evt.MessageID)
+	cli.mediaRetryWaitersLock.Unlock()
+	for _, waiter := range waiters {
+		waiter <- evt
+	}
 	cli.dispatchEvent(evt)
 }
+
+// DefaultMediaRetryTimeout is the time DownloadWithAutoRetry waits for the phone to re-upload media
+// if the context doesn't have a deadline.
+const DefaultMediaRetryTimeout = 1 * time.Minute
+
+func (cli *Client) addMediaRetryWaiter(id types.MessageID) chan *events.MediaRetry {
+	ch := make(chan *events.MediaRetry, 1)
+	cli.mediaRetryWaitersLock.Lock()
+	cli.mediaRetryWaiters[id] = append(cli.mediaRetryWaiters[id], ch)
+	cli.mediaRetryWaitersLock.Unlock()
+	return ch
+}
+
+func (cli *Client) removeMediaRetryWaiter(id types.MessageID, ch chan *events.MediaRetry) {
+	cli.mediaRetryWaitersLock.Lock()
+	defer cli.mediaRetryWaitersLock.Unlock()
+	waiters := cli.mediaRetryWaiters[id]
+	for i, waiter := range waiters {
+		if waiter == chan<- *events.MediaRetry(ch) {
+			waiters = slices.Delete(waiters, i, i+1)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(cli.mediaRetryWaiters, id)
+	} else {
+		cli.mediaRetryWaiters[id] = waiters
+	}
+}
+
+// DownloadWithAutoRetry downloads the attachment from the given message,
+// automatically asking the phone to re-upload it if it's no longer available on the server.
+//
+// If the download fails with a 404 or 410 error, and Client.MediaCache (if set) doesn't have the file either,
+// this sends a media retry receipt (see SendMediaRetryReceipt), waits for the phone to respond,
+// and downloads the file again from the new direct path.
+// If the context doesn't have a deadline, the wait is limited to DefaultMediaRetryTimeout.
+//
+// The new direct path is not written into msg. The events.MediaRetry is still dispatched normally,
+// so event handlers can store the new path if necessary.
+func (cli *Client) DownloadWithAutoRetry(ctx context.Context, info *types.MessageInfo, msg DownloadableMessage) ([]byte, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	data, err := cli.Download(msg)
+	if !errors.Is(err, ErrMediaDownloadFailedWith404) && !errors.Is(err, ErrMediaDownloadFailedWith410) {
+		return data, err
+	}
+	mediaType := GetMediaType(msg)
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultMediaRetryTimeout)
+		defer cancel()
+	}
+	cli.Log.Debugf("Media in %s not available on server (%v), requesting re-upload from phone", info.ID, err)
+	ch := cli.addMediaRetryWaiter(info.ID)
+	defer cli.removeMediaRetryWaiter(info.ID, ch)
+	err = cli.SendMediaRetryReceipt(info, msg.GetMediaKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to send media retry receipt: %w", err)
+	}
+	var evt *events.MediaRetry
+	select {
+	case evt = <-ch:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %w", ErrMediaRetryTimeout, ctx.Err())
+	}
+	retryData, err := DecryptMediaRetryNotification(evt, msg.GetMediaKey())
+	if err != nil {
+		return nil, err
+	} else if retryData.GetResult() != waMmsRetry.MediaRetryNotification_SUCCESS {
+		return nil, fmt.Errorf("%w: %s", ErrMediaRetryFailed, retryData.GetResult())
+	} else if retryData.GetDirectPath() == "" {
+		return nil, fmt.Errorf("%w: no direct path in response", ErrMediaRetryFailed)
+	}
+	data, err = cli.DownloadMediaWithPath(retryData.GetDirectPath(), msg.GetFileEncSHA256(), msg.GetFileSHA256(), msg.GetMediaKey(), getSize(msg), mediaType, "")
+	if err == nil && cli.MediaCache != nil && !isViewOnceMedia(msg) {
+		cli.cacheMedia(ctx, msg, data)
+	}
+	return data, err
+}
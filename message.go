@@ -223,6 +223,7 @@ func (cli *Client) decryptMessages(info *types.MessageInfo, node *waBinary.Node)
 	if ok && len(node.GetChildrenByTag("enc")) == 0 {
 		uType := events.UnavailableType(unavailableNode.AttrGetter().String("type"))
 		cli.Log.Warnf("Unavailable message %s from %s (type: %q)", info.ID, info.SourceString(), uType)
+		cli.trackDecryptionFailure(info, types.DecryptionFailureUnavailable)
 		go cli.delayedRequestMessageFromPhone(info)
 		cli.dispatchEvent(&events.UndecryptableMessage{Info: *info, IsUnavailable: true, UnavailableType: uType})
 		return
@@ -286,6 +287,7 @@ func (cli *Client) decryptMessages(info *types.MessageInfo, node *waBinary.Node)
 			isUnavailable := encType == "skmsg" && !containsDirectMsg && errors.Is(err, signalerror.ErrNoSenderKeyForUser)
 			// TODO figure out why @bot messages fail to decrypt
 			if info.Chat.Server != types.BotServer && encType != "msmsg" {
+				cli.trackDecryptionFailure(info, getDecryptionFailureCause(err))
 				go cli.sendRetryReceipt(node, info, isUnavailable)
 			}
 			cli.dispatchEvent(&events.UndecryptableMessage{
@@ -315,7 +317,8 @@ func (cli *Client) decryptMessages(info *types.MessageInfo, node *waBinary.Node)
 		}
 	}
 	if handled {
-		go cli.sendMessageReceipt(info)
+		cli.trackDecryptionSuccess(info, events.DecryptionRecoveredByResend)
+		cli.goSendReceipt(func() { cli.sendMessageReceipt(info) })
 	}
 }
 
@@ -463,6 +466,7 @@ func (cli *Client) handleHistorySyncNotification(notif *waE2E.HistorySyncNotific
 			go cli.handleHistoricalPushNames(historySync.GetPushnames())
 		} else if len(historySync.GetConversations()) > 0 {
 			go cli.storeHistoricalMessageSecrets(historySync.GetConversations())
+			go cli.storeHistoricalDisappearingTimers(historySync.GetConversations())
 		}
 		cli.dispatchEvent(&events.HistorySync{
 			Data: &historySync,
@@ -521,6 +525,7 @@ func (cli *Client) handlePlaceholderResendResponse(msg *waE2E.PeerDataOperationR
 		} else {
 			msgEvt.UnavailableRequestID = reqID
 			cli.dispatchEvent(msgEvt)
+			cli.trackDecryptionSuccess(&msgEvt.Info, events.DecryptionRecoveredByPhone)
 		}
 	}
 }
@@ -533,7 +538,7 @@ func (cli *Client) handleProtocolMessage(info *types.MessageInfo, msg *waE2E.Mes
 		if cli.historySyncHandlerStarted.CompareAndSwap(false, true) {
 			go cli.handleHistorySyncNotificationLoop()
 		}
-		go cli.sendProtocolMessageReceipt(info.ID, types.ReceiptTypeHistorySync)
+		cli.goSendReceipt(func() { cli.sendProtocolMessageReceipt(info.ID, types.ReceiptTypeHistorySync) })
 	}
 
 	if protoMsg.GetPeerDataOperationRequestResponseMessage().GetPeerDataOperationRequestType() == waE2E.PeerDataOperationRequestType_PLACEHOLDER_MESSAGE_RESEND {
@@ -545,7 +550,7 @@ func (cli *Client) handleProtocolMessage(info *types.MessageInfo, msg *waE2E.Mes
 	}
 
 	if info.Category == "peer" {
-		go cli.sendProtocolMessageReceipt(info.ID, types.ReceiptTypePeerMsg)
+		cli.goSendReceipt(func() { cli.sendProtocolMessageReceipt(info.ID, types.ReceiptTypePeerMsg) })
 	}
 }
 
@@ -650,8 +655,20 @@ func (cli *Client) storeHistoricalMessageSecrets(conversations []*waHistorySync.
 
 func (cli *Client) handleDecryptedMessage(info *types.MessageInfo, msg *waE2E.Message, retryCount int) {
 	cli.processProtocolParts(info, msg)
-	evt := &events.Message{Info: *info, RawMessage: msg, RetryCount: retryCount}
-	cli.dispatchEvent(evt.UnwrapRaw())
+	evt := (&events.Message{Info: *info, RawMessage: msg, RetryCount: retryCount}).UnwrapRaw()
+	markViewOnceMedia(evt)
+	if info.Chat == types.StatusBroadcastJID {
+		cli.trackStatus(evt)
+	} else if poll := getPollCreation(evt.Message); poll != nil {
+		cli.trackPollCreation(&evt.Info, poll)
+	}
+	cli.dispatchEvent(evt)
+	if evt.Message.GetPollUpdateMessage() != nil {
+		cli.trackPollVote(evt)
+	} else {
+		cli.trackMessageState(evt)
+	}
+	cli.trackDisappearingMessage(evt)
 }
 
 func (cli *Client) sendProtocolMessageReceipt(id types.MessageID, msgType types.ReceiptType) {
@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waCommon"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+// GetMessageState returns the current state of a message: the latest text after edits, the current reaction of each user,
+// and whether the message has been revoked or deleted for us.
+//
+// Edits, reactions (including encrypted reactions), revokes and deletions for us are tracked automatically as they're
+// received or sent, and each change emits an *events.MessageStateChanged. If nothing has happened to the message,
+// the returned state is empty. The sender is the user who sent the original message.
+func (cli *Client) GetMessageState(chat, sender types.JID, id types.MessageID) (*types.MessageState, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	state, err := cli.Store.MessageStates.GetMessageState(chat, sender, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message state: %w", err)
+	}
+	return state, nil
+}
+
+// getTargetSenderFromKey finds the sender of the message referenced by the given key.
+//
+// Unlike getOrigSenderFromKey, this allows any kind of JID, as it's only used for local bookkeeping and not for encryption.
+func getTargetSenderFromKey(info *types.MessageInfo, key *waCommon.MessageKey) (types.JID, error) {
+	if key.GetFromMe() {
+		return info.Sender.ToNonAD(), nil
+	}
+	// In private chats, the remote JID is the chat from the perspective of the user who sent the key,
+	// which means it's the other user, i.e. the sender of the target message.
+	jidStr := key.GetParticipant()
+	if jidStr == "" {
+		jidStr = key.GetRemoteJID()
+	}
+	jid, err := types.ParseJID(jidStr)
+	if err != nil {
+		return types.EmptyJID, fmt.Errorf("failed to parse JID %q of target message sender: %w", jidStr, err)
+	}
+	return jid.ToNonAD(), nil
+}
+
+func getMessageText(msg *waE2E.Message) string {
+	switch {
+	case msg.Conversation != nil:
+		return msg.GetConversation()
+	case msg.ExtendedTextMessage != nil:
+		return msg.GetExtendedTextMessage().GetText()
+	case msg.ImageMessage != nil:
+		return msg.GetImageMessage().GetCaption()
+	case msg.VideoMessage != nil:
+		return msg.GetVideoMessage().GetCaption()
+	case msg.DocumentMessage != nil:
+		return msg.GetDocumentMessage().GetCaption()
+	default:
+		return ""
+	}
+}
+
+func timestampOrDefault(ms int64, fallback time.Time) time.Time {
+	if ms == 0 {
+		return fallback
+	}
+	return time.UnixMilli(ms)
+}
+
+func (cli *Client) trackMessageState(evt *events.Message) {
+	var key *waCommon.MessageKey
+	var change events.MessageStateChange
+	var reaction *waE2E.ReactionMessage
+	protoMsg := evt.Message.GetProtocolMessage()
+	switch {
+	case evt.Message.ReactionMessage != nil:
+		reaction = evt.Message.GetReactionMessage()
+		key, change = reaction.GetKey(), events.MessageStateChangeReaction
+	case evt.Message.EncReactionMessage != nil:
+		var err error
+		reaction, err = cli.DecryptReaction(evt)
+		if err != nil {
+			cli.Log.Warnf("Failed to decrypt reaction %s from %s: %v", evt.Info.ID, evt.Info.SourceString(), err)
+			return
+		}
+		key, change = evt.Message.GetEncReactionMessage().GetTargetMessageKey(), events.MessageStateChangeReaction
+	case protoMsg.GetType() == waE2E.ProtocolMessage_MESSAGE_EDIT:
+		key, change = protoMsg.GetKey(), events.MessageStateChangeEdit
+	case protoMsg.GetType() == waE2E.ProtocolMessage_REVOKE:
+		key, change = protoMsg.GetKey(), events.MessageStateChangeRevoke
+	default:
+		return
+	}
+	sender, err := getTargetSenderFromKey(&evt.Info, key)
+	if err != nil {
+		cli.Log.Warnf("Failed to get target of %s %s from %s: %v", change, evt.Info.ID, evt.Info.SourceString(), err)
+		return
+	}
+	updated := true
+	switch change {
+	case events.MessageStateChangeReaction:
+		updated, err = cli.Store.MessageStates.PutMessageReaction(evt.Info.Chat, sender, key.GetID(), types.MessageReaction{
+			Reactor:   evt.Info.Sender.ToNonAD(),
+			Emoji:     reaction.GetText(),
+			Timestamp: timestampOrDefault(reaction.GetSenderTimestampMS(), evt.Info.Timestamp),
+		})
+	case events.MessageStateChangeEdit:
+		timestamp := timestampOrDefault(protoMsg.GetTimestampMS(), evt.Info.Timestamp)
+		updated, err = cli.Store.MessageStates.PutMessageEdit(evt.Info.Chat, sender, key.GetID(), getMessageText(protoMsg.GetEditedMessage()), timestamp)
+	case events.MessageStateChangeRevoke:
+		err = cli.Store.MessageStates.PutMessageRevoked(evt.Info.Chat, sender, key.GetID())
+	}
+	if err != nil {
+		cli.Log.Warnf("Failed to store %s %s from %s: %v", change, evt.Info.ID, evt.Info.SourceString(), err)
+		return
+	} else if !updated {
+		cli.Log.Debugf("Ignoring outdated %s %s from %s", change, evt.Info.ID, evt.Info.SourceString())
+		return
+	}
+	cli.dispatchMessageStateChanged(evt.Info.Chat, sender, key.GetID(), change, evt)
+}
+
+func (cli *Client) trackDeleteForMe(evt *events.DeleteForMe, dispatch bool) {
+	sender := evt.SenderJID
+	if evt.IsFromMe {
+		sender = cli.getOwnID()
+	} else if sender.IsEmpty() {
+		sender = evt.ChatJID
+	}
+	err := cli.Store.MessageStates.PutMessageDeletedForMe(evt.ChatJID, sender, evt.MessageID)
+	if err != nil {
+		cli.Log.Warnf("Failed to store deletion of %s in %s: %v", evt.MessageID, evt.ChatJID, err)
+	} else if dispatch {
+		cli.dispatchMessageStateChanged(evt.ChatJID, sender, evt.MessageID, events.MessageStateChangeDeleteForMe, nil)
+	}
+}
+
+// trackOutgoingMessage tracks polls, votes, reactions, edits, revokes and disappearing messages sent by this device,
+// as they won't be received as incoming messages. It must only be called after the server has accepted the message,
+// so that changes which never reached anyone aren't stored or announced with MessageStateChanged.
+func (cli *Client) trackOutgoingMessage(to, ownID types.JID, id types.MessageID, message *waE2E.Message, ts time.Time) {
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	evt := (&events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{
+				Chat:     to,
+				Sender:   ownID,
+				IsFromMe: true,
+				IsGroup:  to.Server == types.GroupServer,
+			},
+			ID:        id,
+			Timestamp: ts,
+		},
+		RawMessage: message,
+	}).UnwrapRaw()
+	if poll := getPollCreation(evt.Message); poll != nil {
+		cli.trackPollCreation(&evt.Info, poll)
+	} else if evt.Message.GetPollUpdateMessage() != nil {
+		cli.trackPollVote(evt)
+	} else {
+		cli.trackMessageState(evt)
+	}
+	cli.trackDisappearingMessage(evt)
+}
+
+func (cli *Client) dispatchMessageStateChanged(chat, sender types.JID, id types.MessageID, change events.MessageStateChange, source *events.Message) {
+	state, err := cli.Store.MessageStates.GetMessageState(chat, sender, id)
+	if err != nil {
+		cli.Log.Warnf("Failed to get state of %s in %s after %s: %v", id, chat, change, err)
+		return
+	}
+	cli.dispatchEvent(&events.MessageStateChanged{
+		MessageState: *state,
+		Change:       change,
+		Source:       source,
+	})
+}
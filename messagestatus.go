@@ -0,0 +1,136 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+// DefaultMessageStatusRetention is the default value of Client.MessageStatusRetention.
+const DefaultMessageStatusRetention = 30 * 24 * time.Hour
+
+const messageStatusPruneInterval = 1 * time.Hour
+
+// GetMessageStatus returns the delivery, read and played receipts of each recipient of a message sent by us.
+//
+// The recipients are stored when the server accepts the message (group members, broadcast list recipients or the other
+// user in DMs), and receipts from all of their devices are aggregated as they arrive. Each new state of a recipient
+// emits an *events.MessageStatusChanged. Reactions, poll votes and protocol messages like edits aren't tracked.
+// Statuses are deleted after MessageStatusRetention. If the message isn't tracked, ErrMessageStatusNotFound is returned.
+func (cli *Client) GetMessageStatus(id types.MessageID) (*types.MessageStatus, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	status, err := cli.Store.MessageStatuses.GetMessageStatus(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message status: %w", err)
+	} else if status == nil {
+		return nil, ErrMessageStatusNotFound
+	}
+	return status, nil
+}
+
+func isMessageStatusTrackable(msg *waE2E.Message) bool {
+	return msg.ProtocolMessage == nil && msg.ReactionMessage == nil && msg.EncReactionMessage == nil && msg.PollUpdateMessage == nil
+}
+
+// trackSentMessage stores the recipients of a message after the server has accepted it, so that receipts can be aggregated.
+//
+// LIDs are stored as the phone number JID if the mapping is known, and receipts are normalized the same way in trackReceipt,
+// so that receipts match the recipient regardless of which addressing the recipient's devices use.
+func (cli *Client) trackSentMessage(to types.JID, id types.MessageID, message *waE2E.Message, participants []types.JID, sentAt time.Time) {
+	if len(participants) == 0 || to.Server == types.BotServer || !isMessageStatusTrackable(message) {
+		return
+	} else if sentAt.IsZero() {
+		sentAt = time.Now()
+	}
+	ownID := cli.getOwnID()
+	ownLID := cli.Store.LID
+	recipients := make([]types.JID, 0, len(participants))
+	seen := make(map[types.JID]struct{}, len(participants))
+	for _, participant := range participants {
+		participant = cli.normalizeLID(participant.ToNonAD())
+		if participant.User == ownID.User || (!ownLID.IsEmpty() && participant.User == ownLID.User) {
+			continue
+		} else if _, alreadyAdded := seen[participant]; alreadyAdded {
+			continue
+		}
+		seen[participant] = struct{}{}
+		recipients = append(recipients, participant)
+	}
+	err := cli.Store.MessageStatuses.PutMessageRecipients(to, id, recipients, sentAt)
+	if err != nil {
+		cli.Log.Warnf("Failed to store recipients of %s: %v", id, err)
+	}
+}
+
+func (cli *Client) trackReceipt(receipt *events.Receipt) {
+	switch receipt.Type {
+	case types.ReceiptTypeDelivered, types.ReceiptTypeRead, types.ReceiptTypePlayed:
+	default:
+		return
+	}
+	if receipt.IsFromMe {
+		return
+	}
+	recipient := cli.normalizeLID(receipt.Sender.ToNonAD())
+	for _, id := range receipt.MessageIDs {
+		updated, err := cli.Store.MessageStatuses.PutMessageReceipt(id, recipient, receipt.Type, receipt.Timestamp)
+		if err != nil {
+			cli.Log.Warnf("Failed to store receipt for %s from %s: %v", id, receipt.SourceString(), err)
+			continue
+		} else if !updated {
+			continue
+		}
+		status, err := cli.Store.MessageStatuses.GetMessageStatus(id)
+		if err != nil {
+			cli.Log.Warnf("Failed to get status of %s after receipt from %s: %v", id, receipt.SourceString(), err)
+			continue
+		} else if status == nil {
+			continue
+		}
+		cli.dispatchEvent(&events.MessageStatusChanged{
+			MessageStatus: *status,
+			Recipient:     recipient,
+			Type:          receipt.Type,
+			Receipt:       receipt,
+		})
+	}
+}
+
+// messageStatusPruneLoop periodically deletes message statuses older than MessageStatusRetention,
+// until the given (socket) context is cancelled.
+func (cli *Client) messageStatusPruneLoop(ctx context.Context) {
+	if cli.MessageStatusRetention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(messageStatusPruneInterval)
+	defer ticker.Stop()
+	for {
+		cli.pruneMessageStatuses()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (cli *Client) pruneMessageStatuses() {
+	deleted, err := cli.Store.MessageStatuses.DeleteMessageStatusesBefore(time.Now().Add(-cli.MessageStatusRetention))
+	if err != nil {
+		cli.Log.Warnf("Failed to delete old message statuses: %v", err)
+	} else if deleted > 0 {
+		cli.Log.Debugf("Deleted %d message statuses older than %s", deleted, cli.MessageStatusRetention)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+type memoryMessageStatusStore struct {
+	statuses map[types.MessageID]*types.MessageStatus
+}
+
+func (m *memoryMessageStatusStore) PutMessageRecipients(chat types.JID, id types.MessageID, recipients []types.JID, sentAt time.Time) error {
+	status, ok := m.statuses[id]
+	if !ok {
+		status = &types.MessageStatus{Chat: chat, ID: id, SentAt: sentAt}
+		m.statuses[id] = status
+	}
+	for _, recipient := range recipients {
+		status.Recipients = append(status.Recipients, types.MessageRecipientStatus{User: recipient})
+	}
+	return nil
+}
+
+func (m *memoryMessageStatusStore) PutMessageReceipt(id types.MessageID, recipient types.JID, receiptType types.ReceiptType, timestamp time.Time) (bool, error) {
+	status, ok := m.statuses[id]
+	if !ok {
+		return false, nil
+	}
+	for i := range status.Recipients {
+		if status.Recipients[i].User == recipient && status.Recipients[i].DeliveredAt.IsZero() {
+			status.Recipients[i].DeliveredAt = timestamp
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryMessageStatusStore) GetMessageStatus(id types.MessageID) (*types.MessageStatus, error) {
+	return m.statuses[id], nil
+}
+
+func (m *memoryMessageStatusStore) DeleteMessageStatusesBefore(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newMessageStatusTestClient() (*Client, *memoryMessageStatusStore) {
+	ownID := types.JID{User: "1000", Device: 2, Server: types.DefaultUserServer}
+	device := *store.NoopDevice
+	device.ID = &ownID
+	statuses := &memoryMessageStatusStore{statuses: make(map[types.MessageID]*types.MessageStatus)}
+	device.MessageStatuses = statuses
+	return NewClient(&device, waLog.Noop), statuses
+}
+
+func TestSentMessageNotTrackedWithoutAck(t *testing.T) {
+	cli, statuses := newMessageStatusTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	// There's no websocket, so the server never accepts the message
+	_, err := cli.SendMessage(context.Background(), alice, &waE2E.Message{Conversation: proto.String("Hello")}, SendRequestExtra{ID: "msg-1"})
+	if err == nil {
+		t.Fatal("expected sending to fail without a connection")
+	} else if len(statuses.statuses) != 0 {
+		t.Errorf("expected failed message not to be tracked, got %+v", statuses.statuses)
+	}
+}
+
+func TestReceiptFromLIDMatchesRecipient(t *testing.T) {
+	cli, statuses := newMessageStatusTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	aliceLID := types.NewJID("98765", types.HiddenUserServer)
+	bob := types.NewJID("2222", types.DefaultUserServer)
+	bobLID := types.NewJID("87654", types.HiddenUserServer)
+	group := types.NewJID("123456789", types.GroupServer)
+	cli.storeLIDMapping(aliceLID, alice)
+	cli.storeLIDMapping(bobLID, bob)
+
+	// The group participant list may contain either addressing
+	cli.trackSentMessage(group, "msg-1", &waE2E.Message{Conversation: proto.String("Hello")}, []types.JID{alice, bobLID}, time.Now())
+	status := statuses.statuses["msg-1"]
+	if status == nil || len(status.Recipients) != 2 || status.Recipients[1].User != bob {
+		t.Fatalf("expected recipients to be stored with phone numbers, got %+v", status)
+	}
+
+	var changes []*events.MessageStatusChanged
+	cli.AddEventHandler(func(evt any) {
+		if change, ok := evt.(*events.MessageStatusChanged); ok {
+			changes = append(changes, change)
+		}
+	})
+	cli.trackReceipt(&events.Receipt{
+		MessageSource: types.MessageSource{Chat: group, Sender: types.JID{User: "98765", Device: 4, Server: types.HiddenUserServer}, IsGroup: true},
+		MessageIDs:    []types.MessageID{"msg-1"},
+		Timestamp:     time.Now(),
+		Type:          types.ReceiptTypeDelivered,
+	})
+	if status.Recipients[0].DeliveredAt.IsZero() {
+		t.Error("expected receipt from LID to be matched to the phone number recipient")
+	} else if len(changes) != 1 || changes[0].Recipient != alice {
+		t.Errorf("expected one status change for %s, got %+v", alice, changes)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package msgbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+)
+
+// VCardPhone is a phone number in a VCard.
+type VCardPhone struct {
+	// The phone number in international format, e.g. +1 234 567 890.
+	Number string
+	// The type of the number, such as CELL, WORK or HOME. Defaults to CELL.
+	Type string
+	// If true, the number is marked as a WhatsApp user so that official clients show a message button for it.
+	WhatsApp bool
+}
+
+// VCard contains the fields of a contact card.
+type VCard struct {
+	FullName     string
+	Organization string
+	Phones       []VCardPhone
+	Emails       []string
+}
+
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// String formats the contact card in the vCard 3.0 format used by WhatsApp.
+func (vc VCard) String() string {
+	var buf strings.Builder
+	buf.WriteString("BEGIN:VCARD\nVERSION:3.0\n")
+	_, _ = fmt.Fprintf(&buf, "N:;%s;;;\n", vcardEscaper.Replace(vc.FullName))
+	_, _ = fmt.Fprintf(&buf, "FN:%s\n", vcardEscaper.Replace(vc.FullName))
+	if vc.Organization != "" {
+		_, _ = fmt.Fprintf(&buf, "ORG:%s;\n", vcardEscaper.Replace(vc.Organization))
+	}
+	for _, phone := range vc.Phones {
+		phoneType := phone.Type
+		if phoneType == "" {
+			phoneType = "CELL"
+		}
+		if phone.WhatsApp {
+			digits := strings.Map(func(r rune) rune {
+				if r >= '0' && r <= '9' {
+					return r
+				}
+				return -1
+			}, phone.Number)
+			_, _ = fmt.Fprintf(&buf, "TEL;type=%s;type=VOICE;waid=%s:%s\n", phoneType, digits, phone.Number)
+		} else {
+			_, _ = fmt.Fprintf(&buf, "TEL;type=%s;type=VOICE:%s\n", phoneType, phone.Number)
+		}
+	}
+	for _, email := range vc.Emails {
+		_, _ = fmt.Fprintf(&buf, "EMAIL;type=INTERNET:%s\n", email)
+	}
+	buf.WriteString("END:VCARD")
+	return buf.String()
+}
+
+// ContactBuilder builds messages containing a single contact card.
+type ContactBuilder struct {
+	contextBuilder[*ContactBuilder]
+	msg *waE2E.ContactMessage
+}
+
+// Contact starts building a contact message with the given display name and vCard data.
+// The vCard can be generated with VCard.String.
+func Contact(displayName, vcard string) *ContactBuilder {
+	cb := &ContactBuilder{msg: &waE2E.ContactMessage{
+		DisplayName: proto.String(displayName),
+		Vcard:       proto.String(vcard),
+	}}
+	cb.self = cb
+	return cb
+}
+
+// Build builds the message.
+func (cb *ContactBuilder) Build() *waE2E.Message {
+	contact := proto.Clone(cb.msg).(*waE2E.ContactMessage)
+	contact.ContextInfo = cb.builtContextInfo()
+	return &waE2E.Message{ContactMessage: contact}
+}
+
+// ContactsBuilder builds messages containing multiple contact cards.
+type ContactsBuilder struct {
+	contextBuilder[*ContactsBuilder]
+	msg *waE2E.ContactsArrayMessage
+}
+
+// Contacts starts building a contact array message. Use Add to add the contacts.
+func Contacts(displayName string) *ContactsBuilder {
+	cb := &ContactsBuilder{msg: &waE2E.ContactsArrayMessage{
+		DisplayName: proto.String(displayName),
+	}}
+	cb.self = cb
+	return cb
+}
+
+// Add adds a contact card to the message.
+func (cb *ContactsBuilder) Add(displayName, vcard string) *ContactsBuilder {
+	cb.msg.Contacts = append(cb.msg.Contacts, &waE2E.ContactMessage{
+		DisplayName: proto.String(displayName),
+		Vcard:       proto.String(vcard),
+	})
+	return cb
+}
+
+// Build builds the message.
+func (cb *ContactsBuilder) Build() *waE2E.Message {
+	contacts := proto.Clone(cb.msg).(*waE2E.ContactsArrayMessage)
+	contacts.ContextInfo = cb.builtContextInfo()
+	return &waE2E.Message{ContactsArrayMessage: contacts}
+}
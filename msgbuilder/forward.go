@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package msgbuilder
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+)
+
+// ForwardBuilder builds forwarded copies of existing messages.
+type ForwardBuilder struct {
+	original *waE2E.Message
+	score    uint32
+}
+
+// Forward starts building a forwarded copy of the given message.
+//
+// The message should be the unwrapped Message field of an *events.Message, not RawMessage.
+// View-once messages can't be forwarded.
+func Forward(original *waE2E.Message) *ForwardBuilder {
+	return &ForwardBuilder{
+		original: original,
+		score:    ContextInfoOf(original).GetForwardingScore() + 1,
+	}
+}
+
+// Score overrides the forwarding score, which is normally the previous score plus one.
+// WhatsApp shows messages with a score of 5 or more as "forwarded many times".
+func (fb *ForwardBuilder) Score(score uint32) *ForwardBuilder {
+	fb.score = score
+	return fb
+}
+
+// Build builds the message.
+//
+// Reply info, mentions and other chat-specific context from the original message are not included in the copy.
+func (fb *ForwardBuilder) Build() *waE2E.Message {
+	msg := proto.Clone(fb.original).(*waE2E.Message)
+	msg.MessageContextInfo = nil
+	if msg.Conversation != nil {
+		msg.ExtendedTextMessage = &waE2E.ExtendedTextMessage{Text: msg.Conversation}
+		msg.Conversation = nil
+	}
+	setContextInfo(msg, &waE2E.ContextInfo{
+		IsForwarded:     proto.Bool(true),
+		ForwardingScore: proto.Uint32(fb.score),
+	})
+	return msg
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package msgbuilder
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+)
+
+// LocationBuilder builds static location messages.
+type LocationBuilder struct {
+	contextBuilder[*LocationBuilder]
+	msg *waE2E.LocationMessage
+}
+
+// Location starts building a location message pointing at the given coordinates.
+func Location(latitude, longitude float64) *LocationBuilder {
+	lb := &LocationBuilder{msg: &waE2E.LocationMessage{
+		DegreesLatitude:  proto.Float64(latitude),
+		DegreesLongitude: proto.Float64(longitude),
+	}}
+	lb.self = lb
+	return lb
+}
+
+// Name sets the name of the place at the location.
+func (lb *LocationBuilder) Name(name string) *LocationBuilder {
+	lb.msg.Name = optionalString(name)
+	return lb
+}
+
+// Address sets the street address of the place at the location.
+func (lb *LocationBuilder) Address(address string) *LocationBuilder {
+	lb.msg.Address = optionalString(address)
+	return lb
+}
+
+// URL sets a link to more info about the place.
+func (lb *LocationBuilder) URL(url string) *LocationBuilder {
+	lb.msg.URL = optionalString(url)
+	return lb
+}
+
+// Thumbnail sets the small inline JPEG map preview.
+func (lb *LocationBuilder) Thumbnail(jpeg []byte) *LocationBuilder {
+	lb.msg.JPEGThumbnail = jpeg
+	return lb
+}
+
+// Build builds the message.
+func (lb *LocationBuilder) Build() *waE2E.Message {
+	loc := proto.Clone(lb.msg).(*waE2E.LocationMessage)
+	loc.ContextInfo = lb.builtContextInfo()
+	return &waE2E.Message{LocationMessage: loc}
+}
+
+// LiveLocationBuilder builds live location messages.
+//
+// The first message starts sharing the live location, later updates should be sent with the same
+// coordinates fields updated and an increasing sequence number.
+type LiveLocationBuilder struct {
+	contextBuilder[*LiveLocationBuilder]
+	msg *waE2E.LiveLocationMessage
+}
+
+// LiveLocation starts building a live location message with the given current coordinates.
+func LiveLocation(latitude, longitude float64) *LiveLocationBuilder {
+	llb := &LiveLocationBuilder{msg: &waE2E.LiveLocationMessage{
+		DegreesLatitude:  proto.Float64(latitude),
+		DegreesLongitude: proto.Float64(longitude),
+	}}
+	llb.self = llb
+	return llb
+}
+
+// Accuracy sets the accuracy of the coordinates in meters.
+func (llb *LiveLocationBuilder) Accuracy(meters uint32) *LiveLocationBuilder {
+	llb.msg.AccuracyInMeters = optionalUint32(meters)
+	return llb
+}
+
+// Speed sets the current speed in meters per second.
+func (llb *LiveLocationBuilder) Speed(metersPerSecond float32) *LiveLocationBuilder {
+	llb.msg.SpeedInMps = proto.Float32(metersPerSecond)
+	return llb
+}
+
+// Heading sets the current direction of movement in degrees clockwise from magnetic north.
+func (llb *LiveLocationBuilder) Heading(degrees uint32) *LiveLocationBuilder {
+	llb.msg.DegreesClockwiseFromMagneticNorth = proto.Uint32(degrees)
+	return llb
+}
+
+// Caption sets the caption shown with the live location.
+func (llb *LiveLocationBuilder) Caption(caption string) *LiveLocationBuilder {
+	llb.msg.Caption = optionalString(caption)
+	return llb
+}
+
+// Sequence sets the sequence number of the location update.
+func (llb *LiveLocationBuilder) Sequence(seq int64) *LiveLocationBuilder {
+	llb.msg.SequenceNumber = proto.Int64(seq)
+	return llb
+}
+
+// TimeOffset sets how long after the start of sharing this location update is.
+func (llb *LiveLocationBuilder) TimeOffset(offset time.Duration) *LiveLocationBuilder {
+	llb.msg.TimeOffset = proto.Uint32(uint32(offset.Seconds()))
+	return llb
+}
+
+// Thumbnail sets the small inline JPEG map preview.
+func (llb *LiveLocationBuilder) Thumbnail(jpeg []byte) *LiveLocationBuilder {
+	llb.msg.JPEGThumbnail = jpeg
+	return llb
+}
+
+// Build builds the message.
+func (llb *LiveLocationBuilder) Build() *waE2E.Message {
+	loc := proto.Clone(llb.msg).(*waE2E.LiveLocationMessage)
+	loc.ContextInfo = llb.builtContextInfo()
+	return &waE2E.Message{LiveLocationMessage: loc}
+}
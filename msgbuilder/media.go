@@ -0,0 +1,351 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package msgbuilder
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+)
+
+// Media contains the info of a file uploaded with Client.Upload.
+//
+// The fields are the same as in whatsmeow.UploadResponse, so an upload response can be converted directly:
+//
+//	uploaded, err := cli.Upload(ctx, data, whatsmeow.MediaImage)
+//	msg := msgbuilder.Image(msgbuilder.Media(uploaded), "image/jpeg").Caption("Hello").Build()
+type Media struct {
+	URL        string `json:"url"`
+	DirectPath string `json:"direct_path"`
+	Handle     string `json:"handle"`
+	ObjectID   string `json:"object_id"`
+
+	MediaKey      []byte `json:"-"`
+	FileEncSHA256 []byte `json:"-"`
+	FileSHA256    []byte `json:"-"`
+	FileLength    uint64 `json:"-"`
+}
+
+func optionalString(val string) *string {
+	if val == "" {
+		return nil
+	}
+	return proto.String(val)
+}
+
+func optionalUint32(val uint32) *uint32 {
+	if val == 0 {
+		return nil
+	}
+	return proto.Uint32(val)
+}
+
+func wrapViewOnce(msg *waE2E.Message, viewOnce bool) *waE2E.Message {
+	if !viewOnce {
+		return msg
+	}
+	return &waE2E.Message{ViewOnceMessage: &waE2E.FutureProofMessage{Message: msg}}
+}
+
+// ImageBuilder builds image messages.
+type ImageBuilder struct {
+	contextBuilder[*ImageBuilder]
+	msg      *waE2E.ImageMessage
+	viewOnce bool
+}
+
+// Image starts building an image message from an uploaded file.
+func Image(media Media, mimetype string) *ImageBuilder {
+	ib := &ImageBuilder{msg: &waE2E.ImageMessage{
+		URL:               proto.String(media.URL),
+		DirectPath:        proto.String(media.DirectPath),
+		MediaKey:          media.MediaKey,
+		FileEncSHA256:     media.FileEncSHA256,
+		FileSHA256:        media.FileSHA256,
+		FileLength:        proto.Uint64(media.FileLength),
+		Mimetype:          proto.String(mimetype),
+		MediaKeyTimestamp: proto.Int64(time.Now().Unix()),
+	}}
+	ib.self = ib
+	return ib
+}
+
+// Caption sets the caption of the image.
+func (ib *ImageBuilder) Caption(caption string) *ImageBuilder {
+	ib.msg.Caption = optionalString(caption)
+	return ib
+}
+
+// Dimensions sets the width and height of the image.
+func (ib *ImageBuilder) Dimensions(width, height uint32) *ImageBuilder {
+	ib.msg.Width = optionalUint32(width)
+	ib.msg.Height = optionalUint32(height)
+	return ib
+}
+
+// Thumbnail sets the small inline JPEG thumbnail that's shown before the image is downloaded.
+func (ib *ImageBuilder) Thumbnail(jpeg []byte) *ImageBuilder {
+	ib.msg.JPEGThumbnail = jpeg
+	return ib
+}
+
+// ViewOnce makes the image viewable only once by the recipient.
+func (ib *ImageBuilder) ViewOnce() *ImageBuilder {
+	ib.viewOnce = true
+	return ib
+}
+
+// Build builds the message.
+func (ib *ImageBuilder) Build() *waE2E.Message {
+	img := proto.Clone(ib.msg).(*waE2E.ImageMessage)
+	img.ContextInfo = ib.builtContextInfo()
+	if ib.viewOnce {
+		img.ViewOnce = proto.Bool(true)
+	}
+	return wrapViewOnce(&waE2E.Message{ImageMessage: img}, ib.viewOnce)
+}
+
+// VideoBuilder builds video messages.
+type VideoBuilder struct {
+	contextBuilder[*VideoBuilder]
+	msg      *waE2E.VideoMessage
+	viewOnce bool
+}
+
+// Video starts building a video message from an uploaded file.
+func Video(media Media, mimetype string) *VideoBuilder {
+	vb := &VideoBuilder{msg: &waE2E.VideoMessage{
+		URL:               proto.String(media.URL),
+		DirectPath:        proto.String(media.DirectPath),
+		MediaKey:          media.MediaKey,
+		FileEncSHA256:     media.FileEncSHA256,
+		FileSHA256:        media.FileSHA256,
+		FileLength:        proto.Uint64(media.FileLength),
+		Mimetype:          proto.String(mimetype),
+		MediaKeyTimestamp: proto.Int64(time.Now().Unix()),
+	}}
+	vb.self = vb
+	return vb
+}
+
+// Caption sets the caption of the video.
+func (vb *VideoBuilder) Caption(caption string) *VideoBuilder {
+	vb.msg.Caption = optionalString(caption)
+	return vb
+}
+
+// Dimensions sets the width and height of the video.
+func (vb *VideoBuilder) Dimensions(width, height uint32) *VideoBuilder {
+	vb.msg.Width = optionalUint32(width)
+	vb.msg.Height = optionalUint32(height)
+	return vb
+}
+
+// Duration sets the length of the video.
+func (vb *VideoBuilder) Duration(duration time.Duration) *VideoBuilder {
+	vb.msg.Seconds = optionalUint32(uint32(duration.Round(time.Second).Seconds()))
+	return vb
+}
+
+// GIF makes the video autoplay without sound and loop like a GIF.
+func (vb *VideoBuilder) GIF() *VideoBuilder {
+	vb.msg.GifPlayback = proto.Bool(true)
+	return vb
+}
+
+// Thumbnail sets the small inline JPEG thumbnail that's shown before the video is downloaded.
+func (vb *VideoBuilder) Thumbnail(jpeg []byte) *VideoBuilder {
+	vb.msg.JPEGThumbnail = jpeg
+	return vb
+}
+
+// ViewOnce makes the video viewable only once by the recipient.
+func (vb *VideoBuilder) ViewOnce() *VideoBuilder {
+	vb.viewOnce = true
+	return vb
+}
+
+// Build builds the message.
+func (vb *VideoBuilder) Build() *waE2E.Message {
+	vid := proto.Clone(vb.msg).(*waE2E.VideoMessage)
+	vid.ContextInfo = vb.builtContextInfo()
+	if vb.viewOnce {
+		vid.ViewOnce = proto.Bool(true)
+	}
+	return wrapViewOnce(&waE2E.Message{VideoMessage: vid}, vb.viewOnce)
+}
+
+// AudioBuilder builds audio and voice messages.
+type AudioBuilder struct {
+	contextBuilder[*AudioBuilder]
+	msg      *waE2E.AudioMessage
+	viewOnce bool
+}
+
+// Audio starts building an audio message from an uploaded file.
+func Audio(media Media, mimetype string) *AudioBuilder {
+	ab := &AudioBuilder{msg: &waE2E.AudioMessage{
+		URL:               proto.String(media.URL),
+		DirectPath:        proto.String(media.DirectPath),
+		MediaKey:          media.MediaKey,
+		FileEncSHA256:     media.FileEncSHA256,
+		FileSHA256:        media.FileSHA256,
+		FileLength:        proto.Uint64(media.FileLength),
+		Mimetype:          proto.String(mimetype),
+		MediaKeyTimestamp: proto.Int64(time.Now().Unix()),
+	}}
+	ab.self = ab
+	return ab
+}
+
+// Voice marks the audio as a voice message (push-to-talk).
+// Voice messages should be Opus in an Ogg container (audio/ogg; codecs=opus).
+func (ab *AudioBuilder) Voice() *AudioBuilder {
+	ab.msg.PTT = proto.Bool(true)
+	return ab
+}
+
+// Duration sets the length of the audio.
+func (ab *AudioBuilder) Duration(duration time.Duration) *AudioBuilder {
+	ab.msg.Seconds = optionalUint32(uint32(duration.Round(time.Second).Seconds()))
+	return ab
+}
+
+// Waveform sets the waveform that's displayed for voice messages. WhatsApp uses 64 samples in the range 0-100.
+func (ab *AudioBuilder) Waveform(waveform []byte) *AudioBuilder {
+	ab.msg.Waveform = waveform
+	return ab
+}
+
+// ViewOnce makes the audio playable only once by the recipient.
+func (ab *AudioBuilder) ViewOnce() *AudioBuilder {
+	ab.viewOnce = true
+	return ab
+}
+
+// Build builds the message.
+func (ab *AudioBuilder) Build() *waE2E.Message {
+	audio := proto.Clone(ab.msg).(*waE2E.AudioMessage)
+	audio.ContextInfo = ab.builtContextInfo()
+	if ab.viewOnce {
+		audio.ViewOnce = proto.Bool(true)
+	}
+	return wrapViewOnce(&waE2E.Message{AudioMessage: audio}, ab.viewOnce)
+}
+
+// DocumentBuilder builds document (file) messages.
+type DocumentBuilder struct {
+	contextBuilder[*DocumentBuilder]
+	msg *waE2E.DocumentMessage
+}
+
+// Document starts building a document message from an uploaded file.
+func Document(media Media, mimetype, fileName string) *DocumentBuilder {
+	db := &DocumentBuilder{msg: &waE2E.DocumentMessage{
+		URL:               proto.String(media.URL),
+		DirectPath:        proto.String(media.DirectPath),
+		MediaKey:          media.MediaKey,
+		FileEncSHA256:     media.FileEncSHA256,
+		FileSHA256:        media.FileSHA256,
+		FileLength:        proto.Uint64(media.FileLength),
+		Mimetype:          proto.String(mimetype),
+		FileName:          proto.String(fileName),
+		Title:             proto.String(fileName),
+		MediaKeyTimestamp: proto.Int64(time.Now().Unix()),
+	}}
+	db.self = db
+	return db
+}
+
+// Title overrides the title of the document, which defaults to the file name.
+func (db *DocumentBuilder) Title(title string) *DocumentBuilder {
+	db.msg.Title = proto.String(title)
+	return db
+}
+
+// Caption sets the caption of the document.
+func (db *DocumentBuilder) Caption(caption string) *DocumentBuilder {
+	db.msg.Caption = optionalString(caption)
+	return db
+}
+
+// PageCount sets the number of pages in the document.
+func (db *DocumentBuilder) PageCount(pages uint32) *DocumentBuilder {
+	db.msg.PageCount = optionalUint32(pages)
+	return db
+}
+
+// Thumbnail sets the small inline JPEG preview of the document.
+func (db *DocumentBuilder) Thumbnail(jpeg []byte, width, height uint32) *DocumentBuilder {
+	db.msg.JPEGThumbnail = jpeg
+	db.msg.ThumbnailWidth = optionalUint32(width)
+	db.msg.ThumbnailHeight = optionalUint32(height)
+	return db
+}
+
+// Build builds the message.
+//
+// Documents with captions are wrapped in a DocumentWithCaptionMessage like the official clients do.
+func (db *DocumentBuilder) Build() *waE2E.Message {
+	doc := proto.Clone(db.msg).(*waE2E.DocumentMessage)
+	doc.ContextInfo = db.builtContextInfo()
+	msg := &waE2E.Message{DocumentMessage: doc}
+	if doc.Caption != nil {
+		msg = &waE2E.Message{DocumentWithCaptionMessage: &waE2E.FutureProofMessage{Message: msg}}
+	}
+	return msg
+}
+
+// StickerBuilder builds sticker messages.
+type StickerBuilder struct {
+	contextBuilder[*StickerBuilder]
+	msg *waE2E.StickerMessage
+}
+
+// Sticker starts building a sticker message from an uploaded WebP file.
+func Sticker(media Media) *StickerBuilder {
+	sb := &StickerBuilder{msg: &waE2E.StickerMessage{
+		URL:               proto.String(media.URL),
+		DirectPath:        proto.String(media.DirectPath),
+		MediaKey:          media.MediaKey,
+		FileEncSHA256:     media.FileEncSHA256,
+		FileSHA256:        media.FileSHA256,
+		FileLength:        proto.Uint64(media.FileLength),
+		Mimetype:          proto.String("image/webp"),
+		MediaKeyTimestamp: proto.Int64(time.Now().Unix()),
+	}}
+	sb.self = sb
+	return sb
+}
+
+// Dimensions sets the width and height of the sticker. Stickers are normally 512x512.
+func (sb *StickerBuilder) Dimensions(width, height uint32) *StickerBuilder {
+	sb.msg.Width = optionalUint32(width)
+	sb.msg.Height = optionalUint32(height)
+	return sb
+}
+
+// Animated marks the sticker as an animated WebP.
+func (sb *StickerBuilder) Animated() *StickerBuilder {
+	sb.msg.IsAnimated = proto.Bool(true)
+	return sb
+}
+
+// Thumbnail sets the small inline PNG thumbnail of the sticker.
+func (sb *StickerBuilder) Thumbnail(png []byte) *StickerBuilder {
+	sb.msg.PngThumbnail = png
+	return sb
+}
+
+// Build builds the message.
+func (sb *StickerBuilder) Build() *waE2E.Message {
+	sticker := proto.Clone(sb.msg).(*waE2E.StickerMessage)
+	sticker.ContextInfo = sb.builtContextInfo()
+	return &waE2E.Message{StickerMessage: sticker}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package msgbuilder contains fluent builders for the most common kinds of WhatsApp messages.
+//
+// All builders produce a *waE2E.Message that can be sent with Client.SendMessage:
+//
+//	msg := msgbuilder.Text("Hello @1234567890").
+//		Mention(types.NewJID("1234567890", types.DefaultUserServer)).
+//		ReplyTo(&evt.Info, evt.Message).
+//		Build()
+//	resp, err := cli.SendMessage(context.Background(), evt.Info.Chat, msg)
+package msgbuilder
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+)
+
+// contextBuilder contains the ContextInfo methods shared by all builders.
+//
+// B is the type of the builder embedding this, so that the methods can be chained.
+type contextBuilder[B any] struct {
+	self B
+	ci   *waE2E.ContextInfo
+}
+
+func (cb *contextBuilder[B]) contextInfo() *waE2E.ContextInfo {
+	if cb.ci == nil {
+		cb.ci = &waE2E.ContextInfo{}
+	}
+	return cb.ci
+}
+
+// builtContextInfo returns the context info to put in the built message, or nil if nothing was set.
+func (cb *contextBuilder[B]) builtContextInfo() *waE2E.ContextInfo {
+	if cb.ci == nil {
+		return nil
+	}
+	return proto.Clone(cb.ci).(*waE2E.ContextInfo)
+}
+
+// ReplyTo makes the message a reply to the given message.
+//
+// The info and message should be the Info and Message fields of the *events.Message being replied to.
+func (cb *contextBuilder[B]) ReplyTo(info *types.MessageInfo, quoted *waE2E.Message) B {
+	ci := cb.contextInfo()
+	ci.StanzaID = proto.String(info.ID)
+	ci.Participant = proto.String(info.Sender.ToNonAD().String())
+	ci.QuotedMessage = stripQuote(quoted)
+	return cb.self
+}
+
+// ReplyToInChat is like ReplyTo, but for replying to a message from a different chat,
+// such as privately replying to a group message.
+func (cb *contextBuilder[B]) ReplyToInChat(info *types.MessageInfo, quoted *waE2E.Message) B {
+	cb.ReplyTo(info, quoted)
+	cb.ci.RemoteJID = proto.String(info.Chat.String())
+	return cb.self
+}
+
+// Mention marks the given users as mentioned in the message.
+//
+// The text or caption of the message should contain the mentions as @ followed by the user part of the JID (e.g. @1234567890).
+func (cb *contextBuilder[B]) Mention(jids ...types.JID) B {
+	ci := cb.contextInfo()
+	for _, jid := range jids {
+		ci.MentionedJID = append(ci.MentionedJID, jid.ToNonAD().String())
+	}
+	return cb.self
+}
+
+// Expiration sets the disappearing message timer of the message.
+// It should match the disappearing timer of the chat the message is sent to.
+func (cb *contextBuilder[B]) Expiration(timer time.Duration) B {
+	cb.contextInfo().Expiration = proto.Uint32(uint32(timer.Seconds()))
+	return cb.self
+}
+
+// WithContextInfo replaces the context info of the message with the given one,
+// discarding anything previously set with ReplyTo, Mention or Expiration.
+func (cb *contextBuilder[B]) WithContextInfo(ci *waE2E.ContextInfo) B {
+	cb.ci = ci
+	return cb.self
+}
+
+// stripQuote returns a copy of the given message without its own reply info, so that quotes don't nest indefinitely.
+func stripQuote(msg *waE2E.Message) *waE2E.Message {
+	if msg == nil {
+		return nil
+	}
+	msg = proto.Clone(msg).(*waE2E.Message)
+	msg.MessageContextInfo = nil
+	if ci := ContextInfoOf(msg); ci != nil {
+		ci.StanzaID = nil
+		ci.Participant = nil
+		ci.QuotedMessage = nil
+		ci.RemoteJID = nil
+	}
+	return msg
+}
+
+var contextInfoFieldName protoreflect.Name = "contextInfo"
+
+// ContextInfoOf returns the ContextInfo of the first message field that has one, or nil if no such field is set.
+func ContextInfoOf(msg *waE2E.Message) *waE2E.ContextInfo {
+	if msg == nil {
+		return nil
+	}
+	var ci *waE2E.ContextInfo
+	msg.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, val protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return true
+		}
+		ciField := fd.Message().Fields().ByName(contextInfoFieldName)
+		if ciField == nil || !val.Message().Has(ciField) {
+			return true
+		}
+		ci, _ = val.Message().Get(ciField).Message().Interface().(*waE2E.ContextInfo)
+		return ci == nil
+	})
+	return ci
+}
+
+// SetExpiration sets the disappearing message timer of an already built message, adding a ContextInfo if necessary.
+//
+// Plain text messages are converted to extended text messages, as they can't have a ContextInfo.
+// It returns false if the message doesn't support a ContextInfo at all.
+func SetExpiration(msg *waE2E.Message, timer time.Duration) bool {
+	if msg.Conversation != nil && msg.ExtendedTextMessage == nil {
+		msg.ExtendedTextMessage = &waE2E.ExtendedTextMessage{Text: msg.Conversation}
+		msg.Conversation = nil
+	}
+	ci := ContextInfoOf(msg)
+	if ci == nil {
+		ci = &waE2E.ContextInfo{}
+		if !setContextInfo(msg, ci) {
+			return false
+		}
+	}
+	ci.Expiration = proto.Uint32(uint32(timer.Seconds()))
+	return true
+}
+
+// setContextInfo sets the ContextInfo of the first message field that supports one. It returns false if there is no such field.
+func setContextInfo(msg *waE2E.Message, ci *waE2E.ContextInfo) (found bool) {
+	msg.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, val protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return true
+		}
+		ciField := fd.Message().Fields().ByName(contextInfoFieldName)
+		if ciField == nil || ciField.Message() == nil || ciField.Message().FullName() != ci.ProtoReflect().Descriptor().FullName() {
+			return true
+		}
+		val.Message().Set(ciField, protoreflect.ValueOfMessage(ci.ProtoReflect()))
+		found = true
+		return false
+	})
+	return
+}
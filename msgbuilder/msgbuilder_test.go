@@ -0,0 +1,206 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package msgbuilder_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/msgbuilder"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+var (
+	testUser  = types.NewJID("1234567890", types.DefaultUserServer)
+	testGroup = types.NewJID("123456789-987654321", types.GroupServer)
+	testMedia = msgbuilder.Media{
+		URL:           "https://mmg.whatsapp.net/test",
+		DirectPath:    "/v/test",
+		MediaKey:      []byte("media key"),
+		FileEncSHA256: []byte("enc sha256"),
+		FileSHA256:    []byte("sha256"),
+		FileLength:    1234,
+	}
+)
+
+// roundTrip encodes the message like it would be sent and parses it like an incoming message event.
+func roundTrip(t *testing.T, msg *waE2E.Message) *events.Message {
+	t.Helper()
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+	var parsed waE2E.Message
+	err = proto.Unmarshal(data, &parsed)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+	return (&events.Message{RawMessage: &parsed}).UnwrapRaw()
+}
+
+func TestText(t *testing.T) {
+	evt := roundTrip(t, msgbuilder.Text("hello").Build())
+	if evt.Message.GetConversation() != "hello" {
+		t.Errorf("Expected plain conversation, got %v", evt.Message)
+	}
+
+	evt = roundTrip(t, msgbuilder.Text("hi @1234567890, see https://example.com").
+		Mention(types.NewADJID(testUser.User, 0, 2)).
+		LinkPreview(msgbuilder.LinkPreview{URL: "https://example.com", Title: "Example"}).
+		Build())
+	etm := evt.Message.GetExtendedTextMessage()
+	if etm.GetText() != "hi @1234567890, see https://example.com" {
+		t.Errorf("Unexpected text %q", etm.GetText())
+	}
+	if mentions := etm.GetContextInfo().GetMentionedJID(); len(mentions) != 1 || mentions[0] != testUser.String() {
+		t.Errorf("Unexpected mentions %v", mentions)
+	}
+	if etm.GetMatchedText() != "https://example.com" || etm.GetTitle() != "Example" {
+		t.Errorf("Unexpected link preview %q / %q", etm.GetMatchedText(), etm.GetTitle())
+	}
+}
+
+func TestReply(t *testing.T) {
+	quoted := msgbuilder.Text("original").ReplyTo(&types.MessageInfo{ID: "OLDER"}, &waE2E.Message{Conversation: proto.String("older")}).Build()
+	info := &types.MessageInfo{
+		MessageSource: types.MessageSource{Chat: testGroup, Sender: types.NewADJID(testUser.User, 0, 5), IsGroup: true},
+		ID:            "ORIGINAL",
+	}
+	evt := roundTrip(t, msgbuilder.Text("reply").ReplyTo(info, quoted).Build())
+	ci := evt.Message.GetExtendedTextMessage().GetContextInfo()
+	if ci.GetStanzaID() != "ORIGINAL" || ci.GetParticipant() != testUser.String() || ci.RemoteJID != nil {
+		t.Errorf("Unexpected reply context %v", ci)
+	}
+	quotedCI := ci.GetQuotedMessage().GetExtendedTextMessage()
+	if quotedCI.GetText() != "original" || quotedCI.GetContextInfo().GetQuotedMessage() != nil {
+		t.Errorf("Unexpected quoted message %v", ci.GetQuotedMessage())
+	}
+
+	evt = roundTrip(t, msgbuilder.Location(1, 2).ReplyToInChat(info, quoted).Build())
+	if evt.Message.GetLocationMessage().GetContextInfo().GetRemoteJID() != testGroup.String() {
+		t.Errorf("Expected remote JID in private reply context")
+	}
+}
+
+func TestForward(t *testing.T) {
+	original := msgbuilder.Text("forward me").Mention(testUser).Build()
+	evt := roundTrip(t, msgbuilder.Forward(original).Build())
+	ci := evt.Message.GetExtendedTextMessage().GetContextInfo()
+	if !ci.GetIsForwarded() || ci.GetForwardingScore() != 1 || len(ci.GetMentionedJID()) != 0 {
+		t.Errorf("Unexpected forward context %v", ci)
+	}
+	evt = roundTrip(t, msgbuilder.Forward(evt.Message).Build())
+	if score := msgbuilder.ContextInfoOf(evt.Message).GetForwardingScore(); score != 2 {
+		t.Errorf("Expected forwarding score 2, got %d", score)
+	}
+	evt = roundTrip(t, msgbuilder.Forward(&waE2E.Message{Conversation: proto.String("plain")}).Build())
+	if evt.Message.GetExtendedTextMessage().GetText() != "plain" || !msgbuilder.ContextInfoOf(evt.Message).GetIsForwarded() {
+		t.Errorf("Plain text wasn't converted for forwarding: %v", evt.Message)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	evt := roundTrip(t, msgbuilder.Location(60.17, 24.94).Name("Helsinki").Address("Finland").Build())
+	loc := evt.Message.GetLocationMessage()
+	if loc.GetDegreesLatitude() != 60.17 || loc.GetDegreesLongitude() != 24.94 || loc.GetName() != "Helsinki" || loc.GetAddress() != "Finland" {
+		t.Errorf("Unexpected location %v", loc)
+	}
+
+	evt = roundTrip(t, msgbuilder.LiveLocation(60.17, 24.94).Accuracy(10).Sequence(3).TimeOffset(time.Minute).Caption("omw").Build())
+	live := evt.Message.GetLiveLocationMessage()
+	if live.GetAccuracyInMeters() != 10 || live.GetSequenceNumber() != 3 || live.GetTimeOffset() != 60 || live.GetCaption() != "omw" {
+		t.Errorf("Unexpected live location %v", live)
+	}
+}
+
+func TestContacts(t *testing.T) {
+	vcard := msgbuilder.VCard{
+		FullName: "Test; User",
+		Phones:   []msgbuilder.VCardPhone{{Number: "+1 234 567 890", WhatsApp: true}},
+	}.String()
+	if !strings.Contains(vcard, "FN:Test\\; User\n") || !strings.Contains(vcard, "waid=1234567890:+1 234 567 890\n") {
+		t.Errorf("Unexpected vCard %q", vcard)
+	}
+	evt := roundTrip(t, msgbuilder.Contact("Test User", vcard).Build())
+	if evt.Message.GetContactMessage().GetVcard() != vcard {
+		t.Errorf("Unexpected contact %v", evt.Message.GetContactMessage())
+	}
+
+	evt = roundTrip(t, msgbuilder.Contacts("2 contacts").Add("A", vcard).Add("B", vcard).Build())
+	if contacts := evt.Message.GetContactsArrayMessage().GetContacts(); len(contacts) != 2 || contacts[1].GetDisplayName() != "B" {
+		t.Errorf("Unexpected contacts %v", contacts)
+	}
+}
+
+func TestMedia(t *testing.T) {
+	evt := roundTrip(t, msgbuilder.Image(testMedia, "image/jpeg").Caption("cat").Dimensions(640, 480).Build())
+	img := evt.Message.GetImageMessage()
+	if evt.IsViewOnce || img.GetCaption() != "cat" || img.GetWidth() != 640 || img.GetFileLength() != 1234 || img.GetDirectPath() != "/v/test" {
+		t.Errorf("Unexpected image %v", img)
+	}
+
+	evt = roundTrip(t, msgbuilder.Video(testMedia, "video/mp4").ViewOnce().Build())
+	if !evt.IsViewOnce || !evt.Message.GetVideoMessage().GetViewOnce() {
+		t.Errorf("Expected view-once video, got %v", evt.RawMessage)
+	}
+
+	evt = roundTrip(t, msgbuilder.Audio(testMedia, "audio/ogg; codecs=opus").Voice().Duration(3*time.Second).ViewOnce().Build())
+	if !evt.IsViewOnce || !evt.Message.GetAudioMessage().GetPTT() || evt.Message.GetAudioMessage().GetSeconds() != 3 {
+		t.Errorf("Unexpected voice message %v", evt.RawMessage)
+	}
+
+	evt = roundTrip(t, msgbuilder.Document(testMedia, "application/pdf", "file.pdf").Caption("the file").Build())
+	doc := evt.Message.GetDocumentMessage()
+	if !evt.IsDocumentWithCaption || doc.GetFileName() != "file.pdf" || doc.GetCaption() != "the file" {
+		t.Errorf("Unexpected document %v", evt.RawMessage)
+	}
+
+	evt = roundTrip(t, msgbuilder.Sticker(testMedia).Animated().ReplyTo(&types.MessageInfo{ID: "X"}, nil).Build())
+	sticker := evt.Message.GetStickerMessage()
+	if sticker.GetMimetype() != "image/webp" || !sticker.GetIsAnimated() || sticker.GetContextInfo().GetStanzaID() != "X" {
+		t.Errorf("Unexpected sticker %v", sticker)
+	}
+}
+
+func TestBuildIsRepeatable(t *testing.T) {
+	builder := msgbuilder.Text("hi").Mention(testUser)
+	first := builder.Build()
+	first.ExtendedTextMessage.ContextInfo.MentionedJID = nil
+	if len(builder.Build().GetExtendedTextMessage().GetContextInfo().GetMentionedJID()) != 1 {
+		t.Errorf("Modifying a built message affected the builder")
+	}
+}
+
+func TestSetExpiration(t *testing.T) {
+	msg := msgbuilder.Text("hello").Build()
+	if !msgbuilder.SetExpiration(msg, 24*time.Hour) {
+		t.Fatal("Expected plain text message to support expiration")
+	}
+	evt := roundTrip(t, msg)
+	if evt.Message.GetExtendedTextMessage().GetText() != "hello" || evt.Message.Conversation != nil {
+		t.Errorf("Expected plain text to be converted to extended text, got %v", evt.Message)
+	}
+	if exp := msgbuilder.ContextInfoOf(evt.Message).GetExpiration(); exp != 86400 {
+		t.Errorf("Unexpected expiration %d", exp)
+	}
+
+	msg = msgbuilder.Text("hi").Mention(testUser).Build()
+	msgbuilder.SetExpiration(msg, 7*24*time.Hour)
+	ci := msg.GetExtendedTextMessage().GetContextInfo()
+	if ci.GetExpiration() != 604800 || len(ci.GetMentionedJID()) != 1 {
+		t.Errorf("Expected expiration to be added to existing context info, got %v", ci)
+	}
+
+	if msgbuilder.SetExpiration(&waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{}}, time.Hour) {
+		t.Errorf("Expected protocol message not to support expiration")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package msgbuilder
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+)
+
+// LinkPreview contains the data of a link preview attached to a text message.
+type LinkPreview struct {
+	// The URL that the preview is for. It should be present in the message text as-is.
+	URL         string
+	Title       string
+	Description string
+	// A small JPEG thumbnail of the linked page.
+	Thumbnail []byte
+}
+
+// TextBuilder builds text messages.
+type TextBuilder struct {
+	contextBuilder[*TextBuilder]
+	text    string
+	preview *LinkPreview
+}
+
+// Text starts building a text message with the given content.
+func Text(text string) *TextBuilder {
+	tb := &TextBuilder{text: text}
+	tb.self = tb
+	return tb
+}
+
+// LinkPreview attaches a link preview to the message.
+func (tb *TextBuilder) LinkPreview(preview LinkPreview) *TextBuilder {
+	tb.preview = &preview
+	return tb
+}
+
+// Build builds the message.
+//
+// Plain text without any extra info uses the Conversation field, anything else uses ExtendedTextMessage.
+func (tb *TextBuilder) Build() *waE2E.Message {
+	if tb.ci == nil && tb.preview == nil {
+		return &waE2E.Message{Conversation: proto.String(tb.text)}
+	}
+	etm := &waE2E.ExtendedTextMessage{
+		Text:        proto.String(tb.text),
+		ContextInfo: tb.builtContextInfo(),
+	}
+	if tb.preview != nil {
+		etm.MatchedText = proto.String(tb.preview.URL)
+		etm.PreviewType = waE2E.ExtendedTextMessage_NONE.Enum()
+		if tb.preview.Title != "" {
+			etm.Title = proto.String(tb.preview.Title)
+		}
+		if tb.preview.Description != "" {
+			etm.Description = proto.String(tb.preview.Description)
+		}
+		etm.JPEGThumbnail = tb.preview.Thumbnail
+	}
+	return &waE2E.Message{ExtendedTextMessage: etm}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package msgmodel
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pbribeiro/whatsmeow-mysql"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+)
+
+// Media describes the attachment of a media message.
+type Media struct {
+	Type       whatsmeow.MediaType
+	MimeType   string
+	FileName   string
+	FileLength uint64
+	Width      uint32
+	Height     uint32
+	Duration   time.Duration
+	Thumbnail  []byte
+
+	IsVoice    bool // Audio recorded as a voice message
+	IsGIF      bool // Video that should be played like a GIF
+	IsRound    bool // Video recorded as a round video message
+	IsAnimated bool // Animated sticker
+
+	// The media sub-message, e.g. *waE2E.ImageMessage, which can be passed to Client.Download directly.
+	Message whatsmeow.DownloadableMessage
+
+	client *whatsmeow.Client
+	info   *types.MessageInfo
+}
+
+// Download downloads and decrypts the media.
+//
+// If the media has expired from the server, the sender's phone is asked to re-upload it (see Client.DownloadWithAutoRetry).
+func (m *Media) Download(ctx context.Context) ([]byte, error) {
+	return m.client.DownloadWithAutoRetry(ctx, m.info, m.Message)
+}
+
+// DownloadStream downloads the media without buffering the whole file in memory. See Client.DownloadStream for details.
+func (m *Media) DownloadStream(ctx context.Context) (io.ReadCloser, error) {
+	return m.client.DownloadStream(ctx, m.Message)
+}
@@ -0,0 +1,445 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package msgmodel contains a normalized, high-level model of incoming messages.
+//
+// Instead of checking dozens of waE2E.Message fields, incoming message events can be converted into a single struct
+// that has the text, mentions, reply info, media and other common properties in the same place for every message type:
+//
+//	func handler(rawEvt any) {
+//		switch evt := rawEvt.(type) {
+//		case *events.Message:
+//			msg := msgmodel.Convert(cli, evt)
+//			switch msg.Kind {
+//			case msgmodel.KindText:
+//				fmt.Println(msg.Info.Sender, "said", msg.Text)
+//			case msgmodel.KindImage:
+//				data, err := msg.Media.Download(context.Background())
+//				...
+//			}
+//		}
+//	}
+package msgmodel
+
+import (
+	"time"
+
+	"github.com/pbribeiro/whatsmeow-mysql"
+	"github.com/pbribeiro/whatsmeow-mysql/msgbuilder"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waCommon"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+// Kind is the type of a normalized message.
+type Kind string
+
+const (
+	KindUnknown          Kind = ""
+	KindText             Kind = "text"
+	KindImage            Kind = "image"
+	KindVideo            Kind = "video"
+	KindAudio            Kind = "audio"
+	KindDocument         Kind = "document"
+	KindSticker          Kind = "sticker"
+	KindLocation         Kind = "location"
+	KindLiveLocation     Kind = "live_location"
+	KindContact          Kind = "contact"
+	KindReaction         Kind = "reaction"
+	KindEdit             Kind = "edit"
+	KindRevoke           Kind = "revoke"
+	KindPoll             Kind = "poll"
+	KindPollUpdate       Kind = "poll_update"
+	KindEphemeralSetting Kind = "ephemeral_setting"
+)
+
+// IsMedia returns true if messages of this kind have downloadable media.
+func (k Kind) IsMedia() bool {
+	switch k {
+	case KindImage, KindVideo, KindAudio, KindDocument, KindSticker:
+		return true
+	default:
+		return false
+	}
+}
+
+// Message is a normalized message.
+//
+// Only the fields relevant to the Kind are set: for example, Reaction is only set for KindReaction
+// and Media is only set for media kinds.
+type Message struct {
+	Info types.MessageInfo
+	Kind Kind
+
+	// The text of the message, or the caption of a media message.
+	Text string
+	// Users mentioned in the text.
+	Mentions []types.JID
+	// The message this message is replying to.
+	Quoted *QuotedMessage
+
+	// Whether the message was forwarded, and how many times it has been forwarded.
+	// Messages with a score of 5 or more are shown as "forwarded many times".
+	IsForwarded     bool
+	ForwardingScore uint32
+
+	// The disappearing timer of the message, or zero if the message isn't disappearing.
+	Expiration  time.Duration
+	IsEphemeral bool
+	IsViewOnce  bool
+
+	Media            *Media
+	Location         *Location
+	Contacts         []Contact
+	Reaction         *Reaction
+	Edit             *Edit
+	Revoke           *Revoke
+	Poll             *Poll
+	PollUpdate       *PollUpdate
+	EphemeralSetting *EphemeralSetting
+
+	// The original event the message was converted from.
+	Raw *events.Message
+}
+
+// MessageRef is a reference to another message, e.g. the target of a reaction.
+type MessageRef struct {
+	Chat types.JID
+	ID   types.MessageID
+	// The sender of the referenced message.
+	// This is empty if the message was sent by us and the client wasn't available to find our own JID.
+	Sender types.JID
+	// Whether the referenced message was sent by our own account.
+	IsFromMe bool
+}
+
+// QuotedMessage is a message that is being replied to.
+type QuotedMessage struct {
+	MessageRef
+	// The quoted message content, if it was included in the reply.
+	Message *waE2E.Message
+}
+
+// Location contains the coordinates of a location message.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+	Name      string
+	Address   string
+	IsLive    bool
+}
+
+// Contact is a contact card in a contact message.
+type Contact struct {
+	DisplayName string
+	VCard       string
+}
+
+// Reaction is a reaction to another message.
+type Reaction struct {
+	Target MessageRef
+	Emoji  string
+	// Whether the reaction removes a previous reaction from the same sender.
+	Removed bool
+}
+
+// Edit is an edit of a previous message.
+type Edit struct {
+	Target MessageRef
+	// The new content of the message. Info is copied from the edit message and Raw is nil.
+	NewContent *Message
+}
+
+// Revoke is a deletion of a previous message for everyone.
+type Revoke struct {
+	Target MessageRef
+}
+
+// Poll is a poll creation message.
+type Poll struct {
+	Name    string
+	Options []string
+	// The maximum number of options that can be selected, or zero for no limit.
+	SelectableCount uint32
+}
+
+// PollUpdate is an encrypted vote in a poll. Use Client.DecryptPollVote with the Raw event to decrypt it.
+type PollUpdate struct {
+	Poll MessageRef
+}
+
+// EphemeralSetting is a change of the disappearing message timer of the chat.
+type EphemeralSetting struct {
+	// The new timer, or zero if disappearing messages were turned off.
+	Timer time.Duration
+}
+
+// Convert converts a message event into a normalized message.
+//
+// The client is used to download media and to figure out which referenced messages were sent by us.
+// It may be nil, in which case media downloads will fail with whatsmeow.ErrClientIsNil.
+func Convert(cli *whatsmeow.Client, evt *events.Message) *Message {
+	msg := convertContent(cli, evt.Info, evt.Message)
+	msg.IsEphemeral = evt.IsEphemeral
+	msg.IsViewOnce = evt.IsViewOnce
+	msg.Raw = evt
+	return msg
+}
+
+func convertContent(cli *whatsmeow.Client, info types.MessageInfo, content *waE2E.Message) *Message {
+	msg := &Message{Info: info}
+	msg.fillContent(cli, content)
+	if msg.Media != nil {
+		msg.Media.client = cli
+		msg.Media.info = &msg.Info
+	}
+	if ci := msgbuilder.ContextInfoOf(content); ci != nil {
+		msg.fillContextInfo(cli, ci)
+	}
+	return msg
+}
+
+func (msg *Message) fillContent(cli *whatsmeow.Client, content *waE2E.Message) {
+	switch {
+	case content == nil:
+		msg.Kind = KindUnknown
+	case content.Conversation != nil:
+		msg.Kind = KindText
+		msg.Text = content.GetConversation()
+	case content.ExtendedTextMessage != nil:
+		msg.Kind = KindText
+		msg.Text = content.GetExtendedTextMessage().GetText()
+	case content.ImageMessage != nil:
+		img := content.GetImageMessage()
+		msg.Kind = KindImage
+		msg.Text = img.GetCaption()
+		msg.Media = &Media{
+			Type:       whatsmeow.MediaImage,
+			MimeType:   img.GetMimetype(),
+			FileLength: img.GetFileLength(),
+			Width:      img.GetWidth(),
+			Height:     img.GetHeight(),
+			Thumbnail:  img.GetJPEGThumbnail(),
+			Message:    img,
+		}
+	case content.VideoMessage != nil, content.PtvMessage != nil:
+		vid := content.GetVideoMessage()
+		if vid == nil {
+			vid = content.GetPtvMessage()
+		}
+		msg.Kind = KindVideo
+		msg.Text = vid.GetCaption()
+		msg.Media = &Media{
+			Type:       whatsmeow.MediaVideo,
+			MimeType:   vid.GetMimetype(),
+			FileLength: vid.GetFileLength(),
+			Width:      vid.GetWidth(),
+			Height:     vid.GetHeight(),
+			Duration:   time.Duration(vid.GetSeconds()) * time.Second,
+			Thumbnail:  vid.GetJPEGThumbnail(),
+			IsGIF:      vid.GetGifPlayback(),
+			IsRound:    content.PtvMessage != nil,
+			Message:    vid,
+		}
+	case content.AudioMessage != nil:
+		aud := content.GetAudioMessage()
+		msg.Kind = KindAudio
+		msg.Media = &Media{
+			Type:       whatsmeow.MediaAudio,
+			MimeType:   aud.GetMimetype(),
+			FileLength: aud.GetFileLength(),
+			Duration:   time.Duration(aud.GetSeconds()) * time.Second,
+			IsVoice:    aud.GetPTT(),
+			Message:    aud,
+		}
+	case content.DocumentMessage != nil:
+		doc := content.GetDocumentMessage()
+		msg.Kind = KindDocument
+		msg.Text = doc.GetCaption()
+		msg.Media = &Media{
+			Type:       whatsmeow.MediaDocument,
+			MimeType:   doc.GetMimetype(),
+			FileName:   doc.GetFileName(),
+			FileLength: doc.GetFileLength(),
+			Thumbnail:  doc.GetJPEGThumbnail(),
+			Message:    doc,
+		}
+	case content.StickerMessage != nil:
+		stk := content.GetStickerMessage()
+		msg.Kind = KindSticker
+		msg.Media = &Media{
+			Type:       whatsmeow.MediaImage,
+			MimeType:   stk.GetMimetype(),
+			FileLength: stk.GetFileLength(),
+			Width:      stk.GetWidth(),
+			Height:     stk.GetHeight(),
+			Thumbnail:  stk.GetPngThumbnail(),
+			IsAnimated: stk.GetIsAnimated(),
+			Message:    stk,
+		}
+	case content.LocationMessage != nil:
+		loc := content.GetLocationMessage()
+		msg.Kind = KindLocation
+		msg.Location = &Location{
+			Latitude:  loc.GetDegreesLatitude(),
+			Longitude: loc.GetDegreesLongitude(),
+			Name:      loc.GetName(),
+			Address:   loc.GetAddress(),
+		}
+	case content.LiveLocationMessage != nil:
+		loc := content.GetLiveLocationMessage()
+		msg.Kind = KindLiveLocation
+		msg.Text = loc.GetCaption()
+		msg.Location = &Location{
+			Latitude:  loc.GetDegreesLatitude(),
+			Longitude: loc.GetDegreesLongitude(),
+			IsLive:    true,
+		}
+	case content.ContactMessage != nil:
+		msg.Kind = KindContact
+		msg.Contacts = []Contact{convertContact(content.GetContactMessage())}
+	case content.ContactsArrayMessage != nil:
+		msg.Kind = KindContact
+		for _, contact := range content.GetContactsArrayMessage().GetContacts() {
+			msg.Contacts = append(msg.Contacts, convertContact(contact))
+		}
+	case content.ReactionMessage != nil:
+		reaction := content.GetReactionMessage()
+		msg.Kind = KindReaction
+		msg.Reaction = &Reaction{
+			Target:  resolveKey(cli, &msg.Info, reaction.GetKey()),
+			Emoji:   reaction.GetText(),
+			Removed: reaction.GetText() == "",
+		}
+	case content.PollCreationMessage != nil, content.PollCreationMessageV2 != nil, content.PollCreationMessageV3 != nil:
+		poll := content.GetPollCreationMessage()
+		if poll == nil {
+			poll = content.GetPollCreationMessageV2()
+		}
+		if poll == nil {
+			poll = content.GetPollCreationMessageV3()
+		}
+		msg.Kind = KindPoll
+		msg.Poll = &Poll{
+			Name:            poll.GetName(),
+			Options:         make([]string, len(poll.GetOptions())),
+			SelectableCount: poll.GetSelectableOptionsCount(),
+		}
+		for i, opt := range poll.GetOptions() {
+			msg.Poll.Options[i] = opt.GetOptionName()
+		}
+	case content.PollUpdateMessage != nil:
+		msg.Kind = KindPollUpdate
+		msg.PollUpdate = &PollUpdate{
+			Poll: resolveKey(cli, &msg.Info, content.GetPollUpdateMessage().GetPollCreationMessageKey()),
+		}
+	case content.ProtocolMessage != nil:
+		msg.fillProtocolMessage(cli, content.GetProtocolMessage())
+	}
+}
+
+func (msg *Message) fillProtocolMessage(cli *whatsmeow.Client, protoMsg *waE2E.ProtocolMessage) {
+	switch protoMsg.GetType() {
+	case waE2E.ProtocolMessage_REVOKE:
+		msg.Kind = KindRevoke
+		msg.Revoke = &Revoke{Target: resolveKey(cli, &msg.Info, protoMsg.GetKey())}
+	case waE2E.ProtocolMessage_MESSAGE_EDIT:
+		msg.Kind = KindEdit
+		newContent := convertContent(cli, msg.Info, protoMsg.GetEditedMessage())
+		msg.Edit = &Edit{
+			Target:     resolveKey(cli, &msg.Info, protoMsg.GetKey()),
+			NewContent: newContent,
+		}
+		msg.Text = newContent.Text
+		msg.Mentions = newContent.Mentions
+	case waE2E.ProtocolMessage_EPHEMERAL_SETTING:
+		msg.Kind = KindEphemeralSetting
+		msg.EphemeralSetting = &EphemeralSetting{
+			Timer: time.Duration(protoMsg.GetEphemeralExpiration()) * time.Second,
+		}
+	}
+}
+
+func (msg *Message) fillContextInfo(cli *whatsmeow.Client, ci *waE2E.ContextInfo) {
+	for _, mention := range ci.GetMentionedJID() {
+		jid, err := types.ParseJID(mention)
+		if err == nil {
+			msg.Mentions = append(msg.Mentions, jid)
+		}
+	}
+	if ci.GetStanzaID() != "" {
+		msg.Quoted = &QuotedMessage{
+			MessageRef: MessageRef{
+				Chat: msg.Info.Chat,
+				ID:   ci.GetStanzaID(),
+			},
+			Message: ci.GetQuotedMessage(),
+		}
+		if ci.RemoteJID != nil {
+			msg.Quoted.Chat, _ = types.ParseJID(ci.GetRemoteJID())
+		}
+		msg.Quoted.Sender, _ = types.ParseJID(ci.GetParticipant())
+		msg.Quoted.IsFromMe = isOwnJID(cli, msg.Quoted.Sender)
+	}
+	msg.IsForwarded = ci.GetIsForwarded()
+	msg.ForwardingScore = ci.GetForwardingScore()
+	msg.Expiration = time.Duration(ci.GetExpiration()) * time.Second
+}
+
+func convertContact(contact *waE2E.ContactMessage) Contact {
+	return Contact{
+		DisplayName: contact.GetDisplayName(),
+		VCard:       contact.GetVcard(),
+	}
+}
+
+// resolveKey converts a message key in the given message into a reference.
+//
+// Keys are relative to the sender of the message containing them: FromMe means the referenced message
+// was sent by the same user, and the participant is omitted in private chats.
+func resolveKey(cli *whatsmeow.Client, info *types.MessageInfo, key *waCommon.MessageKey) MessageRef {
+	ref := MessageRef{
+		Chat: info.Chat,
+		ID:   key.GetID(),
+	}
+	switch {
+	case key.GetFromMe():
+		ref.Sender = info.Sender
+		ref.IsFromMe = info.IsFromMe
+	case key.GetParticipant() != "":
+		ref.Sender, _ = types.ParseJID(key.GetParticipant())
+		ref.IsFromMe = isOwnJID(cli, ref.Sender)
+	case info.IsFromMe:
+		// We sent a message referencing a message sent by the other user in a private chat
+		ref.Sender = info.Chat
+	default:
+		// The other user in a private chat sent a message referencing one of our messages
+		ref.IsFromMe = true
+		ref.Sender = ownJIDFor(cli, info.Chat)
+	}
+	return ref
+}
+
+func isOwnJID(cli *whatsmeow.Client, jid types.JID) bool {
+	if cli == nil || cli.Store == nil || jid.IsEmpty() {
+		return false
+	}
+	jid = jid.ToNonAD()
+	return (cli.Store.ID != nil && jid == cli.Store.ID.ToNonAD()) || jid == cli.Store.LID.ToNonAD()
+}
+
+// ownJIDFor returns our own JID in the same namespace (phone number or LID) as the given chat.
+func ownJIDFor(cli *whatsmeow.Client, chat types.JID) types.JID {
+	if cli == nil || cli.Store == nil {
+		return types.EmptyJID
+	} else if chat.Server == types.HiddenUserServer {
+		return cli.Store.LID.ToNonAD()
+	} else if cli.Store.ID != nil {
+		return cli.Store.ID.ToNonAD()
+	}
+	return types.EmptyJID
+}
@@ -0,0 +1,449 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package msgmodel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waCommon"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+var (
+	alice = types.NewJID("1111111111", types.DefaultUserServer)
+	bob   = types.NewJID("2222222222", types.DefaultUserServer)
+	group = types.NewJID("123456789-987654321", types.GroupServer)
+
+	incomingDM = types.MessageInfo{
+		MessageSource: types.MessageSource{Chat: alice, Sender: alice},
+		ID:            "3EB0INCOMING",
+	}
+	outgoingDM = types.MessageInfo{
+		MessageSource: types.MessageSource{Chat: alice, Sender: bob, IsFromMe: true},
+		ID:            "3EB0OUTGOING",
+	}
+	incomingGroup = types.MessageInfo{
+		MessageSource: types.MessageSource{Chat: group, Sender: alice, IsGroup: true},
+		ID:            "3EB0GROUP",
+	}
+
+	testImage = &waE2E.ImageMessage{
+		URL:           proto.String("https://mmg.whatsapp.net/test"),
+		DirectPath:    proto.String("/v/test"),
+		MediaKey:      []byte("media key"),
+		FileEncSHA256: []byte("enc sha256"),
+		FileSHA256:    []byte("sha256"),
+		Mimetype:      proto.String("image/jpeg"),
+		FileLength:    proto.Uint64(1234),
+		Width:         proto.Uint32(640),
+		Height:        proto.Uint32(480),
+		JPEGThumbnail: []byte("thumbnail"),
+	}
+)
+
+// parse encodes the message like it would be sent and decodes it like an incoming message event.
+func parse(t *testing.T, info types.MessageInfo, msg *waE2E.Message) *events.Message {
+	t.Helper()
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+	var parsed waE2E.Message
+	err = proto.Unmarshal(data, &parsed)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+	return (&events.Message{Info: info, RawMessage: &parsed}).UnwrapRaw()
+}
+
+// stripForComparison clears the fields that point back at the input protobufs, so that the rest can be compared directly.
+func stripForComparison(msg *Message) *Message {
+	if msg == nil {
+		return nil
+	}
+	msg.Info = types.MessageInfo{}
+	msg.Raw = nil
+	if msg.Media != nil {
+		msg.Media.Message = nil
+		msg.Media.client = nil
+		msg.Media.info = nil
+	}
+	if msg.Quoted != nil {
+		msg.Quoted.Message = nil
+	}
+	if msg.Edit != nil {
+		stripForComparison(msg.Edit.NewContent)
+	}
+	return msg
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name string
+		info types.MessageInfo
+		msg  *waE2E.Message
+		want *Message
+	}{{
+		name: "Conversation",
+		info: incomingDM,
+		msg:  &waE2E.Message{Conversation: proto.String("hello")},
+		want: &Message{Kind: KindText, Text: "hello"},
+	}, {
+		name: "ExtendedTextReply",
+		info: incomingGroup,
+		msg: &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text: proto.String("hi @2222222222"),
+			ContextInfo: &waE2E.ContextInfo{
+				StanzaID:      proto.String("3EB0QUOTED"),
+				Participant:   proto.String(bob.String()),
+				QuotedMessage: &waE2E.Message{Conversation: proto.String("original")},
+				MentionedJID:  []string{bob.String()},
+			},
+		}},
+		want: &Message{
+			Kind:     KindText,
+			Text:     "hi @2222222222",
+			Mentions: []types.JID{bob},
+			Quoted: &QuotedMessage{MessageRef: MessageRef{
+				Chat:   group,
+				ID:     "3EB0QUOTED",
+				Sender: bob,
+			}},
+		},
+	}, {
+		name: "ForwardedManyTimes",
+		info: incomingDM,
+		msg: &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text: proto.String("chain letter"),
+			ContextInfo: &waE2E.ContextInfo{
+				IsForwarded:     proto.Bool(true),
+				ForwardingScore: proto.Uint32(127),
+			},
+		}},
+		want: &Message{Kind: KindText, Text: "chain letter", IsForwarded: true, ForwardingScore: 127},
+	}, {
+		name: "ImageWithCaption",
+		info: incomingDM,
+		msg: &waE2E.Message{ImageMessage: func() *waE2E.ImageMessage {
+			img := proto.Clone(testImage).(*waE2E.ImageMessage)
+			img.Caption = proto.String("look")
+			return img
+		}()},
+		want: &Message{
+			Kind: KindImage,
+			Text: "look",
+			Media: &Media{
+				Type:       whatsmeow.MediaImage,
+				MimeType:   "image/jpeg",
+				FileLength: 1234,
+				Width:      640,
+				Height:     480,
+				Thumbnail:  []byte("thumbnail"),
+			},
+		},
+	}, {
+		name: "DisappearingViewOnceImage",
+		info: incomingDM,
+		msg: &waE2E.Message{EphemeralMessage: &waE2E.FutureProofMessage{Message: &waE2E.Message{
+			ViewOnceMessageV2: &waE2E.FutureProofMessage{Message: &waE2E.Message{
+				ImageMessage: func() *waE2E.ImageMessage {
+					img := proto.Clone(testImage).(*waE2E.ImageMessage)
+					img.ContextInfo = &waE2E.ContextInfo{Expiration: proto.Uint32(86400)}
+					return img
+				}(),
+			}},
+		}}},
+		want: &Message{
+			Kind:        KindImage,
+			Expiration:  24 * time.Hour,
+			IsEphemeral: true,
+			IsViewOnce:  true,
+			Media: &Media{
+				Type:       whatsmeow.MediaImage,
+				MimeType:   "image/jpeg",
+				FileLength: 1234,
+				Width:      640,
+				Height:     480,
+				Thumbnail:  []byte("thumbnail"),
+			},
+		},
+	}, {
+		name: "VoiceMessage",
+		info: incomingDM,
+		msg: &waE2E.Message{AudioMessage: &waE2E.AudioMessage{
+			DirectPath: proto.String("/v/test"),
+			Mimetype:   proto.String("audio/ogg; codecs=opus"),
+			FileLength: proto.Uint64(4321),
+			Seconds:    proto.Uint32(7),
+			PTT:        proto.Bool(true),
+		}},
+		want: &Message{
+			Kind: KindAudio,
+			Media: &Media{
+				Type:       whatsmeow.MediaAudio,
+				MimeType:   "audio/ogg; codecs=opus",
+				FileLength: 4321,
+				Duration:   7 * time.Second,
+				IsVoice:    true,
+			},
+		},
+	}, {
+		name: "RoundVideo",
+		info: incomingDM,
+		msg: &waE2E.Message{PtvMessage: &waE2E.VideoMessage{
+			Mimetype: proto.String("video/mp4"),
+			Seconds:  proto.Uint32(12),
+		}},
+		want: &Message{
+			Kind: KindVideo,
+			Media: &Media{
+				Type:     whatsmeow.MediaVideo,
+				MimeType: "video/mp4",
+				Duration: 12 * time.Second,
+				IsRound:  true,
+			},
+		},
+	}, {
+		name: "DocumentWithCaption",
+		info: incomingDM,
+		msg: &waE2E.Message{DocumentWithCaptionMessage: &waE2E.FutureProofMessage{Message: &waE2E.Message{
+			DocumentMessage: &waE2E.DocumentMessage{
+				Mimetype: proto.String("application/pdf"),
+				FileName: proto.String("report.pdf"),
+				Caption:  proto.String("the report"),
+			},
+		}}},
+		want: &Message{
+			Kind: KindDocument,
+			Text: "the report",
+			Media: &Media{
+				Type:     whatsmeow.MediaDocument,
+				MimeType: "application/pdf",
+				FileName: "report.pdf",
+			},
+		},
+	}, {
+		name: "AnimatedSticker",
+		info: incomingDM,
+		msg: &waE2E.Message{StickerMessage: &waE2E.StickerMessage{
+			Mimetype:   proto.String("image/webp"),
+			IsAnimated: proto.Bool(true),
+		}},
+		want: &Message{
+			Kind: KindSticker,
+			Media: &Media{
+				Type:       whatsmeow.MediaImage,
+				MimeType:   "image/webp",
+				IsAnimated: true,
+			},
+		},
+	}, {
+		name: "Location",
+		info: incomingDM,
+		msg: &waE2E.Message{LocationMessage: &waE2E.LocationMessage{
+			DegreesLatitude:  proto.Float64(60.17),
+			DegreesLongitude: proto.Float64(24.94),
+			Name:             proto.String("Helsinki"),
+		}},
+		want: &Message{Kind: KindLocation, Location: &Location{Latitude: 60.17, Longitude: 24.94, Name: "Helsinki"}},
+	}, {
+		name: "Contacts",
+		info: incomingDM,
+		msg: &waE2E.Message{ContactsArrayMessage: &waE2E.ContactsArrayMessage{
+			Contacts: []*waE2E.ContactMessage{
+				{DisplayName: proto.String("Alice"), Vcard: proto.String("BEGIN:VCARD\nFN:Alice\nEND:VCARD")},
+				{DisplayName: proto.String("Bob"), Vcard: proto.String("BEGIN:VCARD\nFN:Bob\nEND:VCARD")},
+			},
+		}},
+		want: &Message{Kind: KindContact, Contacts: []Contact{
+			{DisplayName: "Alice", VCard: "BEGIN:VCARD\nFN:Alice\nEND:VCARD"},
+			{DisplayName: "Bob", VCard: "BEGIN:VCARD\nFN:Bob\nEND:VCARD"},
+		}},
+	}, {
+		name: "ReactionToOwnMessageInDM",
+		info: incomingDM,
+		msg: &waE2E.Message{ReactionMessage: &waE2E.ReactionMessage{
+			Key: &waCommon.MessageKey{
+				RemoteJID: proto.String(bob.String()),
+				FromMe:    proto.Bool(false),
+				ID:        proto.String("3EB0TARGET"),
+			},
+			Text: proto.String("🐈"),
+		}},
+		want: &Message{Kind: KindReaction, Reaction: &Reaction{
+			Target: MessageRef{Chat: alice, ID: "3EB0TARGET", IsFromMe: true},
+			Emoji:  "🐈",
+		}},
+	}, {
+		name: "RemovedReactionInGroup",
+		info: incomingGroup,
+		msg: &waE2E.Message{ReactionMessage: &waE2E.ReactionMessage{
+			Key: &waCommon.MessageKey{
+				RemoteJID:   proto.String(group.String()),
+				FromMe:      proto.Bool(false),
+				ID:          proto.String("3EB0TARGET"),
+				Participant: proto.String(bob.String()),
+			},
+			Text: proto.String(""),
+		}},
+		want: &Message{Kind: KindReaction, Reaction: &Reaction{
+			Target:  MessageRef{Chat: group, ID: "3EB0TARGET", Sender: bob},
+			Removed: true,
+		}},
+	}, {
+		name: "OutgoingReactionInDM",
+		info: outgoingDM,
+		msg: &waE2E.Message{ReactionMessage: &waE2E.ReactionMessage{
+			Key: &waCommon.MessageKey{
+				RemoteJID: proto.String(alice.String()),
+				FromMe:    proto.Bool(false),
+				ID:        proto.String("3EB0TARGET"),
+			},
+			Text: proto.String("👍"),
+		}},
+		want: &Message{Kind: KindReaction, Reaction: &Reaction{
+			Target: MessageRef{Chat: alice, ID: "3EB0TARGET", Sender: alice},
+			Emoji:  "👍",
+		}},
+	}, {
+		name: "Edit",
+		info: incomingDM,
+		msg: &waE2E.Message{EditedMessage: &waE2E.FutureProofMessage{Message: &waE2E.Message{
+			ProtocolMessage: &waE2E.ProtocolMessage{
+				Key: &waCommon.MessageKey{
+					RemoteJID: proto.String(bob.String()),
+					FromMe:    proto.Bool(true),
+					ID:        proto.String("3EB0TARGET"),
+				},
+				Type:          waE2E.ProtocolMessage_MESSAGE_EDIT.Enum(),
+				EditedMessage: &waE2E.Message{Conversation: proto.String("fixed typo")},
+			},
+		}}},
+		want: &Message{
+			Kind: KindEdit,
+			Text: "fixed typo",
+			Edit: &Edit{
+				Target:     MessageRef{Chat: alice, ID: "3EB0TARGET", Sender: alice},
+				NewContent: &Message{Kind: KindText, Text: "fixed typo"},
+			},
+		},
+	}, {
+		name: "EditWithoutPayload",
+		info: incomingDM,
+		msg: &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{
+			Key: &waCommon.MessageKey{
+				RemoteJID: proto.String(bob.String()),
+				FromMe:    proto.Bool(true),
+				ID:        proto.String("3EB0TARGET"),
+			},
+			Type: waE2E.ProtocolMessage_MESSAGE_EDIT.Enum(),
+		}},
+		want: &Message{
+			Kind: KindEdit,
+			Edit: &Edit{
+				Target:     MessageRef{Chat: alice, ID: "3EB0TARGET", Sender: alice},
+				NewContent: &Message{Kind: KindUnknown},
+			},
+		},
+	}, {
+		name: "Revoke",
+		info: incomingGroup,
+		msg: &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{
+			Key: &waCommon.MessageKey{
+				RemoteJID: proto.String(group.String()),
+				FromMe:    proto.Bool(true),
+				ID:        proto.String("3EB0TARGET"),
+			},
+			Type: waE2E.ProtocolMessage_REVOKE.Enum(),
+		}},
+		want: &Message{Kind: KindRevoke, Revoke: &Revoke{
+			Target: MessageRef{Chat: group, ID: "3EB0TARGET", Sender: alice},
+		}},
+	}, {
+		name: "PollCreation",
+		info: incomingGroup,
+		msg: &waE2E.Message{PollCreationMessageV3: &waE2E.PollCreationMessage{
+			Name: proto.String("meow?"),
+			Options: []*waE2E.PollCreationMessage_Option{
+				{OptionName: proto.String("yes")},
+				{OptionName: proto.String("no")},
+			},
+			SelectableOptionsCount: proto.Uint32(1),
+		}},
+		want: &Message{Kind: KindPoll, Poll: &Poll{Name: "meow?", Options: []string{"yes", "no"}, SelectableCount: 1}},
+	}, {
+		name: "PollUpdate",
+		info: incomingGroup,
+		msg: &waE2E.Message{PollUpdateMessage: &waE2E.PollUpdateMessage{
+			PollCreationMessageKey: &waCommon.MessageKey{
+				RemoteJID:   proto.String(group.String()),
+				FromMe:      proto.Bool(false),
+				ID:          proto.String("3EB0POLL"),
+				Participant: proto.String(bob.String()),
+			},
+			Vote: &waE2E.PollEncValue{EncPayload: []byte("payload"), EncIV: []byte("iv")},
+		}},
+		want: &Message{Kind: KindPollUpdate, PollUpdate: &PollUpdate{
+			Poll: MessageRef{Chat: group, ID: "3EB0POLL", Sender: bob},
+		}},
+	}, {
+		name: "EphemeralSetting",
+		info: incomingDM,
+		msg: &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{
+			Type:                waE2E.ProtocolMessage_EPHEMERAL_SETTING.Enum(),
+			EphemeralExpiration: proto.Uint32(604800),
+		}},
+		want: &Message{Kind: KindEphemeralSetting, EphemeralSetting: &EphemeralSetting{Timer: 7 * 24 * time.Hour}},
+	}, {
+		name: "Unknown",
+		info: incomingDM,
+		msg:  &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{Type: waE2E.ProtocolMessage_APP_STATE_SYNC_KEY_SHARE.Enum()}},
+		want: &Message{Kind: KindUnknown},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			evt := parse(t, test.info, test.msg)
+			got := Convert(nil, evt)
+			if got.Raw != evt || got.Info.ID != test.info.ID {
+				t.Errorf("Expected Raw and Info to be copied from the event")
+			}
+			if got.Media != nil && (got.Media.Message == nil || got.Media.info == nil) {
+				t.Errorf("Expected media to have a download handle")
+			}
+			if got = stripForComparison(got); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Unexpected result:\n got: %+v\nwant: %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestConvertWithoutContent(t *testing.T) {
+	got := Convert(nil, &events.Message{Info: incomingDM})
+	if got.Kind != KindUnknown {
+		t.Errorf("Expected message without content to be unknown, got %q", got.Kind)
+	}
+}
+
+func TestMediaDownloadWithoutClient(t *testing.T) {
+	msg := Convert(nil, parse(t, incomingDM, &waE2E.Message{ImageMessage: testImage}))
+	if !msg.Kind.IsMedia() {
+		t.Fatalf("Expected image to be media, got %q", msg.Kind)
+	}
+	_, err := msg.Media.Download(context.Background())
+	if !errors.Is(err, whatsmeow.ErrClientIsNil) {
+		t.Errorf("Expected ErrClientIsNil, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+// GetPollTally returns the current results of a poll.
+//
+// Polls are tracked automatically when they're sent or received, and incoming votes are decrypted and counted
+// as they arrive (see events.PollTallyChanged). Only the latest vote of each user is counted.
+// If the poll isn't tracked, ErrPollNotFound is returned.
+func (cli *Client) GetPollTally(chat, sender types.JID, id types.MessageID) (*types.PollTally, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	poll, err := cli.Store.Polls.GetPoll(chat, sender, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	} else if poll == nil {
+		return nil, ErrPollNotFound
+	}
+	votes, err := cli.Store.Polls.GetPollVotes(chat, sender, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll votes: %w", err)
+	}
+	return tallyPoll(poll, votes), nil
+}
+
+func tallyPoll(poll *store.Poll, votes []store.PollVote) *types.PollTally {
+	tally := &types.PollTally{
+		Chat:    poll.Chat,
+		Sender:  poll.Sender,
+		ID:      poll.ID,
+		Name:    poll.Name,
+		Options: make([]types.PollOptionTally, len(poll.Options)),
+	}
+	optionHashes := HashPollOptions(poll.Options)
+	for i, name := range poll.Options {
+		tally.Options[i].Name = name
+	}
+	for _, vote := range votes {
+		for i, hash := range optionHashes {
+			if containsHash(vote.SelectedOptions, hash) {
+				tally.Options[i].Voters = append(tally.Options[i].Voters, vote.Voter)
+			}
+		}
+	}
+	return tally
+}
+
+func containsHash(hashes [][]byte, hash []byte) bool {
+	for _, item := range hashes {
+		if bytes.Equal(item, hash) {
+			return true
+		}
+	}
+	return false
+}
+
+func getPollCreation(msg *waE2E.Message) *waE2E.PollCreationMessage {
+	if poll := msg.GetPollCreationMessage(); poll != nil {
+		return poll
+	} else if poll = msg.GetPollCreationMessageV2(); poll != nil {
+		return poll
+	}
+	return msg.GetPollCreationMessageV3()
+}
+
+func (cli *Client) trackPollCreation(info *types.MessageInfo, poll *waE2E.PollCreationMessage) {
+	options := make([]string, len(poll.GetOptions()))
+	for i, opt := range poll.GetOptions() {
+		options[i] = opt.GetOptionName()
+	}
+	err := cli.Store.Polls.PutPoll(store.Poll{
+		Chat:            info.Chat,
+		Sender:          info.Sender,
+		ID:              info.ID,
+		Name:            poll.GetName(),
+		Options:         options,
+		SelectableCount: int(poll.GetSelectableOptionsCount()),
+		CreatedAt:       info.Timestamp,
+	})
+	if err != nil {
+		cli.Log.Warnf("Failed to store poll %s from %s: %v", info.ID, info.SourceString(), err)
+	}
+}
+
+func (cli *Client) trackPollVote(evt *events.Message) {
+	pollUpdate := evt.Message.GetPollUpdateMessage()
+	pollSender, err := getOrigSenderFromKey(evt, pollUpdate.GetPollCreationMessageKey())
+	if err != nil {
+		cli.Log.Warnf("Failed to get poll sender for vote %s from %s: %v", evt.Info.ID, evt.Info.SourceString(), err)
+		return
+	}
+	pollID := pollUpdate.GetPollCreationMessageKey().GetID()
+	poll, err := cli.Store.Polls.GetPoll(evt.Info.Chat, pollSender, pollID)
+	if err != nil {
+		cli.Log.Warnf("Failed to get poll %s for vote %s: %v", pollID, evt.Info.ID, err)
+		return
+	} else if poll == nil {
+		cli.Log.Debugf("Ignoring vote %s from %s for untracked poll %s", evt.Info.ID, evt.Info.SourceString(), pollID)
+		return
+	}
+	vote, err := cli.DecryptPollVote(evt)
+	if err != nil {
+		cli.Log.Warnf("Failed to decrypt vote %s from %s: %v", evt.Info.ID, evt.Info.SourceString(), err)
+		return
+	}
+	updated, err := cli.Store.Polls.PutPollVote(evt.Info.Chat, pollSender, pollID, store.PollVote{
+		Voter:           evt.Info.Sender.ToNonAD(),
+		SelectedOptions: vote.GetSelectedOptions(),
+		Timestamp:       timestampOrDefault(pollUpdate.GetSenderTimestampMS(), evt.Info.Timestamp),
+	})
+	if err != nil {
+		cli.Log.Warnf("Failed to store vote %s from %s: %v", evt.Info.ID, evt.Info.SourceString(), err)
+		return
+	} else if !updated {
+		cli.Log.Debugf("Ignoring outdated vote %s from %s in poll %s", evt.Info.ID, evt.Info.SourceString(), pollID)
+		return
+	}
+	votes, err := cli.Store.Polls.GetPollVotes(evt.Info.Chat, pollSender, pollID)
+	if err != nil {
+		cli.Log.Warnf("Failed to get votes of poll %s: %v", pollID, err)
+		return
+	}
+	selected := make([]string, 0, len(vote.GetSelectedOptions()))
+	for i, hash := range HashPollOptions(poll.Options) {
+		if containsHash(vote.GetSelectedOptions(), hash) {
+			selected = append(selected, poll.Options[i])
+		}
+	}
+	cli.dispatchEvent(&events.PollTallyChanged{
+		PollTally: *tallyPoll(poll, votes),
+		Voter:     evt.Info.Sender.ToNonAD(),
+		Selected:  selected,
+		Vote:      evt,
+	})
+}
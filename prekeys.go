@@ -10,6 +10,7 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"slices"
 	"time"
 
 	"go.mau.fi/libsignal/ecc"
@@ -94,7 +95,22 @@ type preKeyResp struct {
 	err    error
 }
 
+// preKeyFetchChunkSize is the maximum number of devices whose prekeys are requested in a single query.
+// Larger lists (e.g. status broadcasts to all contacts) are split into multiple queries to avoid timeouts.
+const preKeyFetchChunkSize = 200
+
 func (cli *Client) fetchPreKeys(ctx context.Context, users []types.JID) (map[types.JID]preKeyResp, error) {
+	respData := make(map[types.JID]preKeyResp, len(users))
+	for chunk := range slices.Chunk(users, preKeyFetchChunkSize) {
+		err := cli.fetchPreKeysChunk(ctx, chunk, respData)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return respData, nil
+}
+
+func (cli *Client) fetchPreKeysChunk(ctx context.Context, users []types.JID, respData map[types.JID]preKeyResp) error {
 	requests := make([]waBinary.Node, len(users))
 	for i, user := range users {
 		requests[i].Tag = "user"
@@ -114,12 +130,11 @@ func (cli *Client) fetchPreKeys(ctx context.Context, users []types.JID) (map[typ
 		}},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to send prekey request: %w", err)
+		return fmt.Errorf("failed to send prekey request: %w", err)
 	} else if len(resp.GetChildren()) == 0 {
-		return nil, fmt.Errorf("got empty response to prekey request")
+		return fmt.Errorf("got empty response to prekey request")
 	}
 	list := resp.GetChildByTag("list")
-	respData := make(map[types.JID]preKeyResp)
 	for _, child := range list.GetChildren() {
 		if child.Tag != "user" {
 			continue
@@ -128,7 +143,7 @@ func (cli *Client) fetchPreKeys(ctx context.Context, users []types.JID) (map[typ
 		bundle, err := nodeToPreKeyBundle(uint32(jid.Device), child)
 		respData[jid] = preKeyResp{bundle, err}
 	}
-	return respData, nil
+	return nil
 }
 
 func preKeyToNode(key *keys.PreKey) waBinary.Node {
@@ -7,6 +7,7 @@
 package whatsmeow
 
 import (
+	"context"
 	"fmt"
 
 	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
@@ -27,6 +28,7 @@ func (cli *Client) handleChatState(node *waBinary.Node) {
 			cli.Log.Warnf("Unrecognized chat presence state %s", child.Tag)
 		}
 		media := types.ChatPresenceMedia(child.AttrGetter().OptionalString("media"))
+		cli.trackChatPresence(source, presence, media)
 		cli.dispatchEvent(&events.ChatPresence{
 			MessageSource: source,
 			State:         presence,
@@ -52,6 +54,7 @@ func (cli *Client) handlePresence(node *waBinary.Node) {
 	if !ag.OK() {
 		cli.Log.Warnf("Error parsing presence event: %+v", ag.Errors)
 	} else {
+		cli.trackPresence(&evt)
 		cli.dispatchEvent(&evt)
 	}
 }
@@ -60,10 +63,16 @@ func (cli *Client) handlePresence(node *waBinary.Node) {
 //
 // You should call this at least once after connecting so that the server has your pushname.
 // Otherwise, other users will see "-" as the name.
+//
+// If Client.RateLimiter is set, this waits until it allows another action.
+// If that would take longer than RateLimitConfig.MaxQueryWait, ErrRateLimited is returned instead.
 func (cli *Client) SendPresence(state types.Presence) error {
 	if len(cli.Store.PushName) == 0 {
 		return ErrNoPushName
 	}
+	if err := cli.waitRateLimit(context.TODO()); err != nil {
+		return err
+	}
 	if state == types.PresenceAvailable {
 		cli.sendActiveReceipts.CompareAndSwap(0, 1)
 	} else {
@@ -81,6 +90,7 @@ func (cli *Client) SendPresence(state types.Presence) error {
 // SubscribePresence asks the WhatsApp servers to send presence updates of a specific user to this client.
 //
 // After subscribing to this event, you should start receiving *events.Presence for that user in normal event handlers.
+// The subscription is renewed automatically after reconnecting, and the latest presence can be queried with GetPresence.
 //
 // Also, it seems that the WhatsApp servers require you to be online to receive presence status from other users,
 // so you should mark yourself as online before trying to use this function:
@@ -110,17 +120,34 @@ func (cli *Client) SubscribePresence(jid types.JID) error {
 			Content: privacyToken.Token,
 		}}
 	}
-	return cli.sendNode(req)
+	err = cli.sendNode(req)
+	if err != nil {
+		return err
+	}
+	cli.presenceLock.Lock()
+	cli.presenceSubscriptions[jid.ToNonAD()] = struct{}{}
+	cli.presenceLock.Unlock()
+	return nil
 }
 
 // SendChatPresence updates the user's typing status in a specific chat.
 //
 // The media parameter can be set to indicate the user is recording media (like a voice message) rather than typing a text message.
+//
+// If Client.RateLimiter is set, this waits until it allows another action.
+// If that would take longer than RateLimitConfig.MaxQueryWait, ErrRateLimited is returned instead.
 func (cli *Client) SendChatPresence(jid types.JID, state types.ChatPresence, media types.ChatPresenceMedia) error {
+	return cli.sendChatPresence(context.TODO(), jid, state, media)
+}
+
+func (cli *Client) sendChatPresence(ctx context.Context, jid types.JID, state types.ChatPresence, media types.ChatPresenceMedia) error {
 	ownID := cli.getOwnID()
 	if ownID.IsEmpty() {
 		return ErrNotLoggedIn
 	}
+	if err := cli.waitRateLimit(ctx); err != nil {
+		return err
+	}
 	content := []waBinary.Node{{Tag: string(state)}}
 	if state == types.ChatPresenceComposing && len(media) > 0 {
 		content[0].Attrs = waBinary.Attrs{
@@ -0,0 +1,193 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+// TypingStateTimeout specifies how long a typing state is considered current without being refreshed.
+// Other clients stop sending composing updates without sending a paused update in some cases (e.g. when going offline),
+// so old typing states are ignored by GetTypingUsers.
+var TypingStateTimeout = 25 * time.Second
+
+// DefaultPresenceHistoryRetention is the default value of Client.PresenceHistoryRetention.
+const DefaultPresenceHistoryRetention = 30 * 24 * time.Hour
+
+const presenceHistoryPruneInterval = 1 * time.Hour
+
+// GetPresence returns the latest presence received from the given user.
+//
+// Presence updates are only received for users who have been subscribed to with SubscribePresence.
+// The second return value is false if no presence has been received from the user since the client was created.
+func (cli *Client) GetPresence(jid types.JID) (types.UserPresence, bool) {
+	if cli == nil {
+		return types.UserPresence{}, false
+	}
+	cli.presenceLock.Lock()
+	defer cli.presenceLock.Unlock()
+	presence, ok := cli.presences[jid.ToNonAD()]
+	return presence, ok
+}
+
+// GetTypingUsers returns the users who are currently typing or recording audio in the given chat.
+func (cli *Client) GetTypingUsers(chat types.JID) []types.TypingState {
+	if cli == nil {
+		return nil
+	}
+	chat = chat.ToNonAD()
+	cli.presenceLock.Lock()
+	defer cli.presenceLock.Unlock()
+	states := make([]types.TypingState, 0, len(cli.typingStates[chat]))
+	for sender, state := range cli.typingStates[chat] {
+		if time.Since(state.UpdatedAt) > TypingStateTimeout {
+			delete(cli.typingStates[chat], sender)
+			continue
+		}
+		states = append(states, state)
+	}
+	if len(cli.typingStates[chat]) == 0 {
+		delete(cli.typingStates, chat)
+	}
+	slices.SortFunc(states, func(a, b types.TypingState) int {
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	})
+	return states
+}
+
+// GetPresenceSubscriptions returns the users whose presence is subscribed to with SubscribePresence.
+func (cli *Client) GetPresenceSubscriptions() []types.JID {
+	if cli == nil {
+		return nil
+	}
+	cli.presenceLock.Lock()
+	defer cli.presenceLock.Unlock()
+	jids := make([]types.JID, 0, len(cli.presenceSubscriptions))
+	for jid := range cli.presenceSubscriptions {
+		jids = append(jids, jid)
+	}
+	return jids
+}
+
+// ForgetPresenceSubscription stops renewing the presence subscription of the given user after reconnecting.
+//
+// There's no way to unsubscribe on the server, so presence updates will still be received until the connection is closed.
+func (cli *Client) ForgetPresenceSubscription(jid types.JID) {
+	if cli == nil {
+		return
+	}
+	cli.presenceLock.Lock()
+	delete(cli.presenceSubscriptions, jid.ToNonAD())
+	cli.presenceLock.Unlock()
+}
+
+// GetPresenceHistory returns the presence updates received from the given user between since and until.
+//
+// Presence updates are only stored if StorePresenceHistory is enabled, and are deleted after PresenceHistoryRetention.
+func (cli *Client) GetPresenceHistory(jid types.JID, since, until time.Time) ([]types.UserPresence, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	history, err := cli.Store.PresenceHistory.GetPresenceHistory(jid, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presence history: %w", err)
+	}
+	return history, nil
+}
+
+// presenceHistoryPruneLoop periodically deletes presence history older than PresenceHistoryRetention,
+// until the given (socket) context is cancelled.
+func (cli *Client) presenceHistoryPruneLoop(ctx context.Context) {
+	if cli.PresenceHistoryRetention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(presenceHistoryPruneInterval)
+	defer ticker.Stop()
+	for {
+		cli.prunePresenceHistory()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (cli *Client) prunePresenceHistory() {
+	deleted, err := cli.Store.PresenceHistory.DeletePresenceHistoryBefore(time.Now().Add(-cli.PresenceHistoryRetention))
+	if err != nil {
+		cli.Log.Warnf("Failed to delete old presence history: %v", err)
+	} else if deleted > 0 {
+		cli.Log.Debugf("Deleted %d presence updates older than %s", deleted, cli.PresenceHistoryRetention)
+	}
+}
+
+func (cli *Client) resubscribePresences() {
+	for _, jid := range cli.GetPresenceSubscriptions() {
+		err := cli.SubscribePresence(jid)
+		if err != nil {
+			cli.Log.Warnf("Failed to renew presence subscription of %s: %v", jid, err)
+		}
+	}
+}
+
+func (cli *Client) trackPresence(evt *events.Presence) {
+	presence := types.UserPresence{
+		JID:       evt.From.ToNonAD(),
+		Online:    !evt.Unavailable,
+		LastSeen:  evt.LastSeen,
+		UpdatedAt: time.Now(),
+	}
+	cli.presenceLock.Lock()
+	cli.presences[presence.JID] = presence
+	if !presence.Online {
+		// Users who went offline aren't typing anywhere anymore
+		for chat, states := range cli.typingStates {
+			delete(states, presence.JID)
+			if len(states) == 0 {
+				delete(cli.typingStates, chat)
+			}
+		}
+	}
+	cli.presenceLock.Unlock()
+	if cli.StorePresenceHistory {
+		err := cli.Store.PresenceHistory.PutPresenceHistory(presence)
+		if err != nil {
+			cli.Log.Warnf("Failed to store presence of %s: %v", presence.JID, err)
+		}
+	}
+}
+
+func (cli *Client) trackChatPresence(source types.MessageSource, state types.ChatPresence, media types.ChatPresenceMedia) {
+	chat, sender := source.Chat.ToNonAD(), source.Sender.ToNonAD()
+	cli.presenceLock.Lock()
+	defer cli.presenceLock.Unlock()
+	if state != types.ChatPresenceComposing {
+		delete(cli.typingStates[chat], sender)
+		if len(cli.typingStates[chat]) == 0 {
+			delete(cli.typingStates, chat)
+		}
+		return
+	}
+	states, ok := cli.typingStates[chat]
+	if !ok {
+		states = make(map[types.JID]types.TypingState)
+		cli.typingStates[chat] = states
+	}
+	states[sender] = types.TypingState{
+		Chat:      chat,
+		Sender:    sender,
+		Media:     media,
+		UpdatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+)
+
+// RateLimitConfig contains the settings for a RateLimiter.
+//
+// Rates are in actions per second. A zero rate disables the corresponding token bucket.
+type RateLimitConfig struct {
+	// The sustained rate and burst size shared by all rate-limited actions
+	// (sending messages and presences, including simulated typing, IsOnWhatsApp queries and group queries).
+	// Queries made internally by the client, like fetching group members when sending a message, don't use tokens.
+	GlobalRate  float64
+	GlobalBurst int
+	// The sustained rate and burst size of messages sent to a single chat.
+	PerRecipientRate  float64
+	PerRecipientBurst int
+
+	// A random delay between MinSendDelay and MaxSendDelay is added before every message send.
+	MinSendDelay time.Duration
+	MaxSendDelay time.Duration
+
+	// The maximum time that presences and queries wait for the rate limiter. Most of those methods don't take a context,
+	// so if a token won't be available in time, they fail with ErrRateLimited instead of waiting. Defaults to 30 seconds.
+	MaxQueryWait time.Duration
+
+	// If true, a composing chat presence is sent before text messages and kept for a duration
+	// based on the length of the text, like a human typing the message.
+	SimulateTyping bool
+	// The typing speed used for SimulateTyping. Defaults to 8 characters per second.
+	TypingCharsPerSecond float64
+	// The maximum duration of a simulated typing state. Defaults to 10 seconds.
+	MaxTypingDuration time.Duration
+
+	// When the server indicates that we're sending too much (a TemporaryBan event or a 429 rate-overlimit error),
+	// all rates are divided by ThrottleFactor and all delays are multiplied by it for ThrottleDuration
+	// (or until ThrottleDuration after the ban expires). Defaults to a factor of 4 for one hour.
+	ThrottleFactor   float64
+	ThrottleDuration time.Duration
+}
+
+// DefaultRateLimitConfig is a conservative rate limit configuration for bulk senders.
+var DefaultRateLimitConfig = RateLimitConfig{
+	GlobalRate:        1,
+	GlobalBurst:       10,
+	PerRecipientRate:  0.2,
+	PerRecipientBurst: 3,
+	MinSendDelay:      500 * time.Millisecond,
+	MaxSendDelay:      2 * time.Second,
+	SimulateTyping:    true,
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token from the bucket and returns how long the caller has to wait before the token is actually available.
+func (tb *tokenBucket) reserve(now time.Time, rate float64, burst int) time.Duration {
+	tb.refill(now, rate, burst)
+	tb.tokens--
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / rate * float64(time.Second))
+}
+
+func (tb *tokenBucket) refill(now time.Time, rate float64, burst int) {
+	if tb.last.IsZero() {
+		tb.tokens = float64(burst)
+	} else if elapsed := now.Sub(tb.last); elapsed > 0 {
+		tb.tokens += elapsed.Seconds() * rate
+		if tb.tokens > float64(burst) {
+			tb.tokens = float64(burst)
+		}
+	}
+	tb.last = now
+}
+
+// maxRecipientBuckets is the number of per-recipient buckets after which full (idle) buckets are pruned.
+const maxRecipientBuckets = 4096
+
+// RateLimiter paces outgoing actions using a global token bucket, per-recipient token buckets and randomized delays.
+//
+// Set Client.RateLimiter to enable rate limiting:
+//
+//	cli.RateLimiter = whatsmeow.NewRateLimiter(whatsmeow.DefaultRateLimitConfig)
+type RateLimiter struct {
+	config RateLimitConfig
+
+	lock           sync.Mutex
+	global         tokenBucket
+	recipients     map[types.JID]*tokenBucket
+	throttledUntil time.Time
+}
+
+// NewRateLimiter creates a new rate limiter with the given config.
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	if config.GlobalBurst <= 0 {
+		config.GlobalBurst = 1
+	}
+	if config.PerRecipientBurst <= 0 {
+		config.PerRecipientBurst = 1
+	}
+	if config.MaxSendDelay < config.MinSendDelay {
+		config.MaxSendDelay = config.MinSendDelay
+	}
+	if config.MaxQueryWait <= 0 {
+		config.MaxQueryWait = 30 * time.Second
+	}
+	if config.TypingCharsPerSecond <= 0 {
+		config.TypingCharsPerSecond = 8
+	}
+	if config.MaxTypingDuration <= 0 {
+		config.MaxTypingDuration = 10 * time.Second
+	}
+	if config.ThrottleFactor < 1 {
+		config.ThrottleFactor = 4
+	}
+	if config.ThrottleDuration <= 0 {
+		config.ThrottleDuration = time.Hour
+	}
+	return &RateLimiter{
+		config:     config,
+		recipients: make(map[types.JID]*tokenBucket),
+	}
+}
+
+// Throttle slows down all rates by the configured ThrottleFactor until the given duration has passed.
+// If the duration is zero, the configured ThrottleDuration is used.
+//
+// The client calls this automatically after TemporaryBan events and rate-overlimit errors.
+func (rl *RateLimiter) Throttle(duration time.Duration) {
+	if duration <= 0 {
+		duration = rl.config.ThrottleDuration
+	}
+	until := time.Now().Add(duration)
+	rl.lock.Lock()
+	if until.After(rl.throttledUntil) {
+		rl.throttledUntil = until
+	}
+	rl.lock.Unlock()
+}
+
+// ThrottledUntil returns the time until which the rate limiter is slowed down, or the zero time if it's not throttled.
+func (rl *RateLimiter) ThrottledUntil() time.Time {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	if time.Now().After(rl.throttledUntil) {
+		return time.Time{}
+	}
+	return rl.throttledUntil
+}
+
+func (rl *RateLimiter) slowdown(now time.Time) float64 {
+	if now.Before(rl.throttledUntil) {
+		return rl.config.ThrottleFactor
+	}
+	return 1
+}
+
+func (rl *RateLimiter) reserve(recipient types.JID) (wait time.Duration, slowdown float64) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	now := time.Now()
+	slowdown = rl.slowdown(now)
+	if rl.config.GlobalRate > 0 {
+		wait = rl.global.reserve(now, rl.config.GlobalRate/slowdown, rl.config.GlobalBurst)
+	}
+	if !recipient.IsEmpty() && rl.config.PerRecipientRate > 0 {
+		rate := rl.config.PerRecipientRate / slowdown
+		if len(rl.recipients) >= maxRecipientBuckets {
+			for jid, bucket := range rl.recipients {
+				bucket.refill(now, rate, rl.config.PerRecipientBurst)
+				if bucket.tokens >= float64(rl.config.PerRecipientBurst) {
+					delete(rl.recipients, jid)
+				}
+			}
+		}
+		bucket, ok := rl.recipients[recipient]
+		if !ok {
+			bucket = &tokenBucket{}
+			rl.recipients[recipient] = bucket
+		}
+		wait = max(wait, bucket.reserve(now, rate, rl.config.PerRecipientBurst))
+	}
+	return
+}
+
+// reserveWithin takes a token from the buckets if one will be available within maxWait and returns how long the
+// caller has to wait for it. Otherwise, nothing is taken and the returned wait is longer than maxWait.
+func (rl *RateLimiter) reserveWithin(recipient types.JID, maxWait time.Duration) time.Duration {
+	wait, _ := rl.reserve(recipient)
+	if wait > maxWait {
+		rl.cancelReservation(recipient)
+	}
+	return wait
+}
+
+func (rl *RateLimiter) cancelReservation(recipient types.JID) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	if rl.config.GlobalRate > 0 {
+		rl.global.tokens++
+	}
+	if bucket, ok := rl.recipients[recipient]; ok {
+		bucket.tokens++
+	}
+}
+
+// Wait blocks until the token buckets allow another action. If recipient is empty, only the global bucket is used.
+func (rl *RateLimiter) Wait(ctx context.Context, recipient types.JID) error {
+	_, err := rl.wait(ctx, recipient.ToNonAD())
+	return err
+}
+
+func (rl *RateLimiter) wait(ctx context.Context, recipient types.JID) (float64, error) {
+	wait, slowdown := rl.reserve(recipient)
+	if wait <= 0 {
+		return slowdown, ctx.Err()
+	}
+	select {
+	case <-time.After(wait):
+		return slowdown, nil
+	case <-ctx.Done():
+		rl.cancelReservation(recipient)
+		return slowdown, ctx.Err()
+	}
+}
+
+func (rl *RateLimiter) randomSendDelay(slowdown float64) time.Duration {
+	delay := rl.config.MinSendDelay
+	if spread := rl.config.MaxSendDelay - rl.config.MinSendDelay; spread > 0 {
+		delay += time.Duration(rand.Int63n(int64(spread)))
+	}
+	return time.Duration(float64(delay) * slowdown)
+}
+
+func (rl *RateLimiter) typingDuration(text string) time.Duration {
+	duration := time.Duration(float64(len([]rune(text))) / rl.config.TypingCharsPerSecond * float64(time.Second))
+	return min(duration, rl.config.MaxTypingDuration)
+}
+
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func getTextForTypingSimulation(msg *waE2E.Message) string {
+	switch {
+	case msg.Conversation != nil:
+		return msg.GetConversation()
+	case msg.ExtendedTextMessage != nil:
+		return msg.GetExtendedTextMessage().GetText()
+	default:
+		return ""
+	}
+}
+
+// waitRateLimit waits for the rate limiter (if one is set) before a non-message action like a query or
+// presence update. Most of the methods using it don't take a context, so instead of blocking for a long time
+// without a way to cancel, it fails with ErrRateLimited if a token won't be available within MaxQueryWait.
+func (cli *Client) waitRateLimit(ctx context.Context) error {
+	rl := cli.RateLimiter
+	if rl == nil {
+		return nil
+	}
+	wait := rl.reserveWithin(types.EmptyJID, rl.config.MaxQueryWait)
+	if wait > rl.config.MaxQueryWait {
+		return fmt.Errorf("%w, try again in %s", ErrRateLimited, wait.Round(time.Millisecond))
+	} else if err := sleepContext(ctx, wait); err != nil {
+		rl.cancelReservation(types.EmptyJID)
+		return err
+	}
+	return nil
+}
+
+// waitMessageRateLimit waits for the rate limiter (if one is set) before sending a message,
+// including the random human-like delay and simulated typing.
+func (cli *Client) waitMessageRateLimit(ctx context.Context, to types.JID, message *waE2E.Message) error {
+	rl := cli.RateLimiter
+	if rl == nil {
+		return nil
+	}
+	slowdown, err := rl.wait(ctx, to.ToNonAD())
+	if err != nil {
+		return err
+	}
+	delay := rl.randomSendDelay(slowdown)
+	text := getTextForTypingSimulation(message)
+	if !rl.config.SimulateTyping || text == "" || to.Server == types.NewsletterServer || to.Server == types.BroadcastServer {
+		return sleepContext(ctx, delay)
+	}
+	err = cli.sendChatPresence(ctx, to, types.ChatPresenceComposing, types.ChatPresenceMediaText)
+	if err != nil {
+		cli.Log.Debugf("Failed to send composing presence to %s before message: %v", to, err)
+	}
+	err = sleepContext(ctx, max(delay, rl.typingDuration(text)))
+	// The typing state is cleared even if the send was canceled
+	pausedErr := cli.sendChatPresence(context.WithoutCancel(ctx), to, types.ChatPresencePaused, types.ChatPresenceMediaText)
+	if pausedErr != nil {
+		cli.Log.Debugf("Failed to send paused presence to %s before message: %v", to, pausedErr)
+	}
+	return err
+}
+
+// throttleRateLimiter slows down the rate limiter (if one is set) after the server indicated we're sending too much.
+func (cli *Client) throttleRateLimiter(reason string, duration time.Duration) {
+	if cli.RateLimiter == nil {
+		return
+	}
+	cli.RateLimiter.Throttle(duration)
+	cli.Log.Warnf("Throttling outgoing actions until %s due to %s", cli.RateLimiter.ThrottledUntil().Format(time.RFC3339), reason)
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+)
+
+func TestTokenBucket(t *testing.T) {
+	var tb tokenBucket
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	const rate, burst = 2.0, 3
+	steps := []struct {
+		name   string
+		offset time.Duration
+		wait   time.Duration
+	}{
+		{"burst 1", 0, 0},
+		{"burst 2", 0, 0},
+		{"burst 3", 0, 0},
+		{"empty bucket", 0, 500 * time.Millisecond},
+		{"queued behind previous reservation", 0, time.Second},
+		{"partially refilled after waiting", time.Second, 500 * time.Millisecond},
+		{"refill is capped at burst", time.Hour, 0},
+	}
+	for _, step := range steps {
+		if wait := tb.reserve(start.Add(step.offset), rate, burst); wait != step.wait {
+			t.Errorf("%s: expected wait %s, got %s", step.name, step.wait, wait)
+		}
+	}
+	if tb.tokens != burst-1 {
+		t.Errorf("expected %d tokens after refilling to burst and taking one, got %f", burst-1, tb.tokens)
+	}
+}
+
+func TestRateLimiterPerRecipient(t *testing.T) {
+	rl := NewRateLimiter(RateLimitConfig{PerRecipientRate: 0.01, PerRecipientBurst: 1})
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	bob := types.NewJID("2222", types.DefaultUserServer)
+	if wait := rl.reserveWithin(alice, 0); wait != 0 {
+		t.Errorf("expected first action to alice to be allowed, got wait %s", wait)
+	}
+	if wait := rl.reserveWithin(alice, 0); wait <= 0 {
+		t.Error("expected second action to alice to be limited")
+	}
+	if wait := rl.reserveWithin(bob, 0); wait != 0 {
+		t.Errorf("expected first action to bob to be allowed, got wait %s", wait)
+	}
+	// A failed reservation must not take a token, so the wait stays the same instead of growing.
+	first, second := rl.reserveWithin(alice, 0), rl.reserveWithin(alice, 0)
+	if second > first+time.Millisecond {
+		t.Errorf("expected failed reservations not to queue up, got %s then %s", first, second)
+	}
+}
+
+func TestWaitRateLimitPacesQueries(t *testing.T) {
+	cli := &Client{RateLimiter: NewRateLimiter(RateLimitConfig{GlobalRate: 20, GlobalBurst: 1})}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := cli.waitRateLimit(context.Background()); err != nil {
+			t.Fatalf("expected query %d to be paced instead of failing, got %v", i+1, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected queries to be spread out, but they took only %s", elapsed)
+	}
+}
+
+func TestWaitRateLimitMaxWait(t *testing.T) {
+	cli := &Client{RateLimiter: NewRateLimiter(RateLimitConfig{GlobalRate: 0.01, GlobalBurst: 1, MaxQueryWait: time.Second})}
+	if err := cli.waitRateLimit(context.Background()); err != nil {
+		t.Fatalf("expected first query to be allowed, got %v", err)
+	}
+	start := time.Now()
+	if err := cli.waitRateLimit(context.Background()); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	} else if time.Since(start) > time.Second {
+		t.Error("expected waitRateLimit to return without waiting when the wait is longer than MaxQueryWait")
+	}
+	// A query that gave up must not push back the next one
+	if wait := cli.RateLimiter.reserveWithin(types.EmptyJID, 0); wait > 100*time.Second {
+		t.Errorf("expected failed query not to take a token, got wait %s", wait)
+	}
+}
+
+func TestGroupQueriesUseRateLimiter(t *testing.T) {
+	cli := &Client{RateLimiter: NewRateLimiter(RateLimitConfig{GlobalRate: 0.01, GlobalBurst: 1, MaxQueryWait: time.Millisecond})}
+	cli.RateLimiter.reserveWithin(types.EmptyJID, 0)
+	group := types.NewJID("123456789", types.GroupServer)
+	if err := cli.SetGroupName(group, "Test"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected SetGroupName to be rate limited, got %v", err)
+	}
+	if err := cli.LeaveGroup(group); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected LeaveGroup to be rate limited, got %v", err)
+	}
+}
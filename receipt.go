@@ -17,6 +17,8 @@ import (
 
 func (cli *Client) handleReceipt(node *waBinary.Node) {
 	defer cli.maybeDeferredAck(node)()
+	// Receipts from LIDs may include the phone number, which is needed to match them to the stored recipients
+	cli.learnLIDMappingsFromNode(node)
 	receipt, err := cli.parseReceipt(node)
 	if err != nil {
 		cli.Log.Warnf("Failed to parse receipt: %v", err)
@@ -30,6 +32,7 @@ func (cli *Client) handleReceipt(node *waBinary.Node) {
 			}()
 		}
 		cli.dispatchEvent(receipt)
+		cli.trackReceipt(receipt)
 	}
 }
 
@@ -49,7 +52,10 @@ func (cli *Client) handleGroupedReceipt(partialReceipt events.Receipt, participa
 			cli.Log.Warnf("Failed to parse user node %s in grouped receipt: %v", child.XMLString(), ag.Error())
 			continue
 		}
-		go cli.dispatchEvent(&receipt)
+		go func() {
+			cli.dispatchEvent(&receipt)
+			cli.trackReceipt(&receipt)
+		}()
 	}
 }
 
@@ -97,16 +103,31 @@ func (cli *Client) parseReceipt(node *waBinary.Node) (*events.Receipt, error) {
 }
 
 func (cli *Client) maybeDeferredAck(node *waBinary.Node) func() {
+	cli.pendingAcks.Add(1)
 	if cli.SynchronousAck {
 		return func() {
+			defer cli.pendingAcks.Add(-1)
 			cli.sendAck(node)
 		}
 	} else {
-		go cli.sendAck(node)
+		go func() {
+			defer cli.pendingAcks.Add(-1)
+			cli.sendAck(node)
+		}()
 		return func() {}
 	}
 }
 
+// goSendReceipt runs the given receipt sending function in a background goroutine,
+// keeping track of it so that Shutdown can wait for the receipt to be sent.
+func (cli *Client) goSendReceipt(fn func()) {
+	cli.pendingReceipts.Add(1)
+	go func() {
+		defer cli.pendingReceipts.Add(-1)
+		fn()
+	}()
+}
+
 func (cli *Client) sendAck(node *waBinary.Node) {
 	attrs := waBinary.Attrs{
 		"class": node.Tag,
@@ -150,6 +171,8 @@ func (cli *Client) sendAck(node *waBinary.Node) {
 func (cli *Client) MarkRead(ids []types.MessageID, timestamp time.Time, chat, sender types.JID, receiptTypeExtra ...types.ReceiptType) error {
 	if len(ids) == 0 {
 		return fmt.Errorf("no message IDs specified")
+	} else if cli.IsShuttingDown() {
+		return ErrClientShuttingDown
 	}
 	receiptType := types.ReceiptTypeRead
 	if len(receiptTypeExtra) == 1 {
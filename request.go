@@ -8,6 +8,7 @@ package whatsmeow
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -169,7 +170,11 @@ func (cli *Client) sendIQ(query infoQuery) (*waBinary.Node, error) {
 		if res.Tag != "iq" || (resType != "result" && resType != "error") {
 			return res, &IQError{RawNode: res}
 		} else if resType == "error" {
-			return res, parseIQError(res)
+			err = parseIQError(res)
+			if errors.Is(err, ErrIQRateOverLimit) {
+				cli.throttleRateLimiter(fmt.Sprintf("rate-overlimit error in %s info query", query.Namespace), 0)
+			}
+			return res, err
 		}
 		return res, nil
 	case <-query.Context.Done():
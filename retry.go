@@ -351,6 +351,7 @@ func (cli *Client) delayedRequestMessageFromPhone(info *types.MessageInfo) {
 		cli.Log.Warnf("Failed to send request for unavailable message %s to phone: %v", info.ID, err)
 	} else {
 		cli.Log.Debugf("Requested message %s from phone", info.ID)
+		cli.trackPhoneRequestSent(info)
 	}
 }
 
@@ -382,6 +383,7 @@ func (cli *Client) sendRetryReceipt(node *waBinary.Node, info *types.MessageInfo
 	cli.messageRetriesLock.Unlock()
 	if retryCount >= 5 {
 		cli.Log.Warnf("Not sending any more retry receipts for %s", id)
+		cli.trackRetriesExhausted(info)
 		return
 	}
 	if retryCount == 1 {
@@ -436,5 +438,7 @@ func (cli *Client) sendRetryReceipt(node *waBinary.Node, info *types.MessageInfo
 	err := cli.sendNode(payload)
 	if err != nil {
 		cli.Log.Errorf("Failed to send retry receipt for %s: %v", id, err)
+	} else {
+		cli.trackRetryReceiptSent(info)
 	}
 }
@@ -0,0 +1,248 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+var (
+	// MaxScheduledMessageAttempts is the number of times sending a scheduled message is attempted before giving up.
+	MaxScheduledMessageAttempts = 5
+	// ScheduledMessageRetryDelay is the delay before the first retry of a failed scheduled message.
+	// The delay is doubled after each failed attempt.
+	ScheduledMessageRetryDelay = 30 * time.Second
+)
+
+// ScheduleMessage stores the given message to be sent to the given chat at the given time.
+//
+// Scheduled messages are stored in the device store, so they survive restarts. Messages are sent through SendMessage
+// when they're due and the client is connected. If the client isn't connected at the scheduled time, the message is
+// sent as soon as it connects. The time zone of sendAt doesn't matter, so e.g. 9:00 in the recipient's local time
+// can be scheduled using time.Date with the recipient's time.Location.
+//
+// The returned ID is the ID that the message will have when it's sent, and can be used to cancel or reschedule it.
+// When the message is sent, an *events.ScheduledMessageSent is dispatched. If sending fails permanently,
+// an *events.ScheduledMessageFailed is dispatched instead.
+func (cli *Client) ScheduleMessage(to types.JID, message *waE2E.Message, sendAt time.Time) (types.MessageID, error) {
+	if cli == nil {
+		return "", ErrClientIsNil
+	} else if to.Device > 0 {
+		return "", ErrRecipientADJID
+	}
+	id := cli.GenerateMessageID()
+	err := cli.Store.ScheduledMessages.PutScheduledMessage(store.ScheduledMessage{
+		ID:      id,
+		Chat:    to,
+		Message: message,
+		SendAt:  sendAt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store scheduled message: %w", err)
+	}
+	cli.wakeScheduledMessageLoop()
+	return id, nil
+}
+
+// CancelScheduledMessage removes a message scheduled with ScheduleMessage.
+//
+// If the message is being sent right now, this waits for the attempt to finish. If the attempt succeeded,
+// ErrScheduledMessageNotFound is returned, otherwise the message is removed before it's retried.
+func (cli *Client) CancelScheduledMessage(id types.MessageID) error {
+	if cli == nil {
+		return ErrClientIsNil
+	}
+	defer cli.lockScheduledMessage(id)()
+	if msg, err := cli.Store.ScheduledMessages.GetScheduledMessage(id); err != nil {
+		return err
+	} else if msg == nil {
+		return ErrScheduledMessageNotFound
+	}
+	return cli.Store.ScheduledMessages.DeleteScheduledMessage(id)
+}
+
+// RescheduleMessage changes the send time of a message scheduled with ScheduleMessage.
+//
+// Like with CancelScheduledMessage, if the message is being sent right now, this waits for the attempt to finish.
+func (cli *Client) RescheduleMessage(id types.MessageID, sendAt time.Time) error {
+	if cli == nil {
+		return ErrClientIsNil
+	}
+	defer cli.lockScheduledMessage(id)()
+	msg, err := cli.Store.ScheduledMessages.GetScheduledMessage(id)
+	if err != nil {
+		return err
+	} else if msg == nil {
+		return ErrScheduledMessageNotFound
+	}
+	msg.SendAt = sendAt
+	msg.Attempts = 0
+	err = cli.Store.ScheduledMessages.PutScheduledMessage(*msg)
+	if err != nil {
+		return err
+	}
+	cli.wakeScheduledMessageLoop()
+	return nil
+}
+
+// GetScheduledMessages returns all messages that are scheduled to be sent, ordered by the send time.
+func (cli *Client) GetScheduledMessages() ([]store.ScheduledMessage, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	return cli.Store.ScheduledMessages.GetAllScheduledMessages()
+}
+
+type scheduledMessageLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lockScheduledMessage locks the scheduled message with the given ID and returns a function to unlock it.
+//
+// The lock is held for the whole duration of each send attempt, so that the message can't be cancelled or
+// rescheduled in the middle of an attempt, and the result of the attempt doesn't overwrite such changes.
+func (cli *Client) lockScheduledMessage(id types.MessageID) (unlock func()) {
+	cli.scheduledMessageLocksLock.Lock()
+	lock, ok := cli.scheduledMessageLocks[id]
+	if !ok {
+		lock = &scheduledMessageLock{}
+		cli.scheduledMessageLocks[id] = lock
+	}
+	lock.refs++
+	cli.scheduledMessageLocksLock.Unlock()
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		cli.scheduledMessageLocksLock.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(cli.scheduledMessageLocks, id)
+		}
+		cli.scheduledMessageLocksLock.Unlock()
+	}
+}
+
+func (cli *Client) wakeScheduledMessageLoop() {
+	select {
+	case cli.scheduledMessageWake <- struct{}{}:
+	default:
+	}
+}
+
+// scheduledMessageLoop sends scheduled messages as they become due until the given (socket) context is cancelled.
+func (cli *Client) scheduledMessageLoop(ctx context.Context) {
+	for {
+		wait := time.Hour
+		next, err := cli.sendDueScheduledMessages(ctx)
+		if err != nil {
+			cli.Log.Errorf("Failed to send scheduled messages: %v", err)
+			wait = time.Minute
+		} else if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-cli.scheduledMessageWake:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// sendDueScheduledMessages sends all scheduled messages that are due and returns the send time of the next scheduled message.
+func (cli *Client) sendDueScheduledMessages(ctx context.Context) (time.Time, error) {
+	due, err := cli.Store.ScheduledMessages.GetScheduledMessagesBefore(time.Now())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get due messages: %w", err)
+	}
+	for _, msg := range due {
+		if ctx.Err() != nil || !cli.IsLoggedIn() {
+			return time.Time{}, nil
+		}
+		cli.sendScheduledMessage(ctx, msg.ID)
+	}
+	next, err := cli.Store.ScheduledMessages.GetNextScheduledMessage()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get next scheduled message: %w", err)
+	} else if next == nil {
+		return time.Time{}, nil
+	}
+	return next.SendAt, nil
+}
+
+func isPermanentScheduledSendError(err error) bool {
+	return errors.Is(err, ErrRecipientADJID) ||
+		errors.Is(err, ErrUnknownServer) ||
+		errors.Is(err, ErrBroadcastListNotFound) ||
+		errors.Is(err, ErrServerReturnedError) ||
+		errors.Is(err, ErrInvalidInlineBotID)
+}
+
+func (cli *Client) sendScheduledMessage(ctx context.Context, id types.MessageID) {
+	defer cli.lockScheduledMessage(id)()
+	// Re-fetch the message while holding the lock in case it was cancelled or rescheduled after the due list was fetched
+	current, err := cli.Store.ScheduledMessages.GetScheduledMessage(id)
+	if err != nil {
+		cli.Log.Errorf("Failed to get scheduled message %s: %v", id, err)
+		return
+	} else if current == nil || current.SendAt.After(time.Now()) {
+		return
+	}
+	msg := *current
+	// The ID is reused for retries, so the server will deduplicate the message if a previous attempt actually went through
+	resp, err := cli.SendMessage(ctx, msg.Chat, msg.Message, SendRequestExtra{ID: msg.ID})
+	if err == nil {
+		cli.Log.Debugf("Sent scheduled message %s to %s", msg.ID, msg.Chat)
+		if dbErr := cli.Store.ScheduledMessages.DeleteScheduledMessage(msg.ID); dbErr != nil {
+			cli.Log.Errorf("Failed to delete sent scheduled message %s: %v", msg.ID, dbErr)
+		}
+		cli.dispatchEvent(&events.ScheduledMessageSent{
+			ID:        msg.ID,
+			Chat:      msg.Chat,
+			Message:   msg.Message,
+			Timestamp: resp.Timestamp,
+		})
+		return
+	} else if ctx.Err() != nil {
+		// Disconnected while sending, try again after reconnecting without counting an attempt
+		return
+	}
+	msg.Attempts++
+	if isPermanentScheduledSendError(err) || msg.Attempts >= MaxScheduledMessageAttempts {
+		cli.Log.Warnf("Failed to send scheduled message %s to %s (attempt #%d), giving up: %v", msg.ID, msg.Chat, msg.Attempts, err)
+		if dbErr := cli.Store.ScheduledMessages.DeleteScheduledMessage(msg.ID); dbErr != nil {
+			cli.Log.Errorf("Failed to delete failed scheduled message %s: %v", msg.ID, dbErr)
+		}
+		cli.dispatchEvent(&events.ScheduledMessageFailed{
+			ID:       msg.ID,
+			Chat:     msg.Chat,
+			Message:  msg.Message,
+			Attempts: msg.Attempts,
+			Error:    err,
+		})
+		return
+	}
+	retryDelay := ScheduledMessageRetryDelay << (msg.Attempts - 1)
+	cli.Log.Warnf("Failed to send scheduled message %s to %s (attempt #%d), retrying in %s: %v", msg.ID, msg.Chat, msg.Attempts, retryDelay, err)
+	msg.SendAt = time.Now().Add(retryDelay)
+	if dbErr := cli.Store.ScheduledMessages.PutScheduledMessage(msg); dbErr != nil {
+		cli.Log.Errorf("Failed to update scheduled message %s after failed attempt: %v", msg.ID, dbErr)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+type memoryScheduledMessageStore struct {
+	lock     sync.Mutex
+	messages map[types.MessageID]store.ScheduledMessage
+}
+
+func (m *memoryScheduledMessageStore) PutScheduledMessage(msg store.ScheduledMessage) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.messages[msg.ID] = msg
+	return nil
+}
+
+func (m *memoryScheduledMessageStore) DeleteScheduledMessage(id types.MessageID) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.messages, id)
+	return nil
+}
+
+func (m *memoryScheduledMessageStore) GetScheduledMessage(id types.MessageID) (*store.ScheduledMessage, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	msg, ok := m.messages[id]
+	if !ok {
+		return nil, nil
+	}
+	return &msg, nil
+}
+
+func (m *memoryScheduledMessageStore) GetScheduledMessagesBefore(until time.Time) ([]store.ScheduledMessage, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var output []store.ScheduledMessage
+	for _, msg := range m.messages {
+		if !msg.SendAt.After(until) {
+			output = append(output, msg)
+		}
+	}
+	return output, nil
+}
+
+func (m *memoryScheduledMessageStore) GetNextScheduledMessage() (*store.ScheduledMessage, error) {
+	all, _ := m.GetAllScheduledMessages()
+	var next *store.ScheduledMessage
+	for i, msg := range all {
+		if next == nil || msg.SendAt.Before(next.SendAt) {
+			next = &all[i]
+		}
+	}
+	return next, nil
+}
+
+func (m *memoryScheduledMessageStore) GetAllScheduledMessages() ([]store.ScheduledMessage, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	output := make([]store.ScheduledMessage, 0, len(m.messages))
+	for _, msg := range m.messages {
+		output = append(output, msg)
+	}
+	return output, nil
+}
+
+func newSchedulerTestClient() (*Client, *memoryScheduledMessageStore, *[]any) {
+	ownID := types.JID{User: "1000", Device: 2, Server: types.DefaultUserServer}
+	device := *store.NoopDevice
+	device.ID = &ownID
+	scheduled := &memoryScheduledMessageStore{messages: make(map[types.MessageID]store.ScheduledMessage)}
+	device.ScheduledMessages = scheduled
+	cli := NewClient(&device, waLog.Noop)
+	var evts []any
+	cli.AddEventHandler(func(evt any) {
+		evts = append(evts, evt)
+	})
+	return cli, scheduled, &evts
+}
+
+func TestSendScheduledMessageAfterCancel(t *testing.T) {
+	cli, scheduled, evts := newSchedulerTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	id, err := cli.ScheduleMessage(alice, &waE2E.Message{Conversation: proto.String("Hello")}, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("failed to schedule message: %v", err)
+	}
+	// The scheduler loop fetched the due list before the message was cancelled
+	if err = cli.CancelScheduledMessage(id); err != nil {
+		t.Fatalf("failed to cancel message: %v", err)
+	}
+	cli.sendScheduledMessage(context.Background(), id)
+	if len(scheduled.messages) != 0 {
+		t.Errorf("expected cancelled message not to be stored again, got %+v", scheduled.messages)
+	} else if len(*evts) != 0 {
+		t.Errorf("expected cancelled message not to be sent, got events %+v", *evts)
+	}
+}
+
+func TestSendScheduledMessageAfterReschedule(t *testing.T) {
+	cli, scheduled, evts := newSchedulerTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	id, err := cli.ScheduleMessage(alice, &waE2E.Message{Conversation: proto.String("Hello")}, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("failed to schedule message: %v", err)
+	}
+	sendAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err = cli.RescheduleMessage(id, sendAt); err != nil {
+		t.Fatalf("failed to reschedule message: %v", err)
+	}
+	cli.sendScheduledMessage(context.Background(), id)
+	if msg := scheduled.messages[id]; !msg.SendAt.Equal(sendAt) || msg.Attempts != 0 {
+		t.Errorf("expected rescheduled message to be kept as is, got %+v", msg)
+	} else if len(*evts) != 0 {
+		t.Errorf("expected rescheduled message not to be sent yet, got events %+v", *evts)
+	}
+}
+
+func TestSendScheduledMessageFailure(t *testing.T) {
+	cli, scheduled, evts := newSchedulerTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	id, err := cli.ScheduleMessage(alice, &waE2E.Message{Conversation: proto.String("Hello")}, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("failed to schedule message: %v", err)
+	}
+	// There's no websocket, so sending fails with a temporary error
+	cli.sendScheduledMessage(context.Background(), id)
+	if msg, ok := scheduled.messages[id]; !ok {
+		t.Fatal("expected message to be kept for retrying")
+	} else if msg.Attempts != 1 || time.Until(msg.SendAt) < ScheduledMessageRetryDelay/2 {
+		t.Errorf("expected message to be retried after the retry delay, got %+v", msg)
+	} else if len(*evts) != 0 {
+		t.Errorf("expected no events after a temporary failure, got %+v", *evts)
+	}
+
+	scheduled.messages[id] = store.ScheduledMessage{ID: id, Chat: alice, Message: scheduled.messages[id].Message, Attempts: MaxScheduledMessageAttempts - 1}
+	cli.sendScheduledMessage(context.Background(), id)
+	if _, ok := scheduled.messages[id]; ok {
+		t.Error("expected message to be removed after the last attempt")
+	} else if len(*evts) != 1 {
+		t.Fatalf("expected exactly one event, got %+v", *evts)
+	} else if failed, ok := (*evts)[0].(*events.ScheduledMessageFailed); !ok || !errors.Is(failed.Error, ErrNotConnected) {
+		t.Errorf("expected ScheduledMessageFailed event, got %+v", (*evts)[0])
+	}
+}
+
+func TestCancelScheduledMessageWaitsForSend(t *testing.T) {
+	cli, scheduled, _ := newSchedulerTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	id, err := cli.ScheduleMessage(alice, &waE2E.Message{Conversation: proto.String("Hello")}, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("failed to schedule message: %v", err)
+	}
+	// Pretend a send attempt is in progress
+	unlock := cli.lockScheduledMessage(id)
+	cancelled := make(chan error, 1)
+	go func() {
+		cancelled <- cli.CancelScheduledMessage(id)
+	}()
+	select {
+	case err = <-cancelled:
+		t.Fatalf("expected cancel to wait for the send attempt, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	// The attempt fails and stores the message for retrying before releasing the lock
+	msg := scheduled.messages[id]
+	msg.Attempts = 1
+	_ = scheduled.PutScheduledMessage(msg)
+	unlock()
+	if err = <-cancelled; err != nil {
+		t.Fatalf("failed to cancel message: %v", err)
+	} else if len(scheduled.messages) != 0 {
+		t.Errorf("expected message to be cancelled after the failed attempt, got %+v", scheduled.messages)
+	} else if len(cli.scheduledMessageLocks) != 0 {
+		t.Errorf("expected locks to be cleaned up, got %d", len(cli.scheduledMessageLocks))
+	}
+}
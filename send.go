@@ -71,7 +71,8 @@ func GenerateMessageID() types.MessageID {
 }
 
 type MessageDebugTimings struct {
-	Queue time.Duration
+	RateLimit time.Duration
+	Queue     time.Duration
 
 	Marshal         time.Duration
 	GetParticipants time.Duration
@@ -85,6 +86,9 @@ type MessageDebugTimings struct {
 }
 
 func (mdt MessageDebugTimings) MarshalZerologObject(evt *zerolog.Event) {
+	if mdt.RateLimit != 0 {
+		evt.Dur("rate_limit", mdt.RateLimit)
+	}
 	evt.Dur("queue", mdt.Queue)
 	evt.Dur("marshal", mdt.Marshal)
 	if mdt.GetParticipants != 0 {
@@ -162,6 +166,9 @@ type SendRequestExtra struct {
 //
 // For uploading and sending media/attachments, see the Upload method.
 //
+// Messages can also be sent to broadcast lists created with CreateBroadcastList by using the list JID as the recipient.
+// The message is then sent to each recipient as a normal 1:1 message with the same ID.
+//
 // For other message types, you'll have to figure it out yourself. Looking at the protobuf schema
 // in binary/proto/def.proto may be useful to find out all the allowed fields. Printing the RawMessage
 // field in incoming message events to figure out what it contains is also a good way to learn how to
@@ -170,6 +177,9 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waE2E
 	if cli == nil {
 		err = ErrClientIsNil
 		return
+	} else if cli.IsShuttingDown() {
+		err = ErrClientShuttingDown
+		return
 	}
 	var req SendRequestExtra
 	if len(extra) > 1 {
@@ -203,6 +213,9 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waE2E
 		}
 	}
 	resp.ID = req.ID
+	if to.IsBroadcastList() {
+		return cli.sendBroadcastList(ctx, to, message, req)
+	}
 
 	isInlineBotMode := false
 
@@ -270,7 +283,19 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waE2E
 		}
 	}
 
+	if !req.Peer {
+		message = cli.applyDisappearingTimer(to, message)
+	}
+
 	start := time.Now()
+	if !req.Peer {
+		err = cli.waitMessageRateLimit(ctx, to, message)
+		resp.DebugTimings.RateLimit = time.Since(start)
+		if err != nil {
+			return
+		}
+		start = time.Now()
+	}
 	// Sending multiple messages at a time can cause weird issues and makes it harder to retry safely
 	cli.messageSendLock.Lock()
 	resp.DebugTimings.Queue = time.Since(start)
@@ -292,14 +317,16 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waE2E
 	}
 	var phash string
 	var data []byte
+	var recipients []types.JID
 	switch to.Server {
 	case types.GroupServer, types.BroadcastServer:
-		phash, data, err = cli.sendGroup(ctx, to, ownID, req.ID, message, &resp.DebugTimings, botNode)
+		phash, recipients, data, err = cli.sendGroup(ctx, to, ownID, req.ID, message, &resp.DebugTimings, botNode)
 	case types.DefaultUserServer, types.BotServer:
 		if req.Peer {
 			data, err = cli.sendPeerMessage(to, req.ID, message, &resp.DebugTimings)
 		} else {
 			data, err = cli.sendDM(ctx, to, ownID, req.ID, message, &resp.DebugTimings, botNode)
+			recipients = []types.JID{to}
 		}
 	case types.NewsletterServer:
 		data, err = cli.sendNewsletter(to, req.ID, message, req.MediaHandle, &resp.DebugTimings)
@@ -343,6 +370,12 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waE2E
 	resp.Timestamp = ag.UnixTime("t")
 	if errorCode := ag.Int("error"); errorCode != 0 {
 		err = fmt.Errorf("%w %d", ErrServerReturnedError, errorCode)
+		if errorCode == 429 {
+			cli.throttleRateLimiter("rate-overlimit error when sending message", 0)
+		}
+	} else if !req.Peer {
+		cli.trackOutgoingMessage(to, ownID, req.ID, message, resp.Timestamp)
+		cli.trackSentMessage(to, req.ID, message, recipients, resp.Timestamp)
 	}
 	expectedPHash := ag.OptionalString("phash")
 	if len(expectedPHash) > 0 && phash != expectedPHash {
@@ -544,6 +577,9 @@ func (cli *Client) SetDisappearingTimer(chat types.JID, timer time.Duration) (er
 				err = wrapIQError(ErrInvalidDisappearingTimer, err)
 			}
 		}
+		if err == nil {
+			cli.putDisappearingTimer(chat, timer, time.Now())
+		}
 	default:
 		err = fmt.Errorf("can't set disappearing time in a %s chat", chat.Server)
 	}
@@ -607,20 +643,20 @@ func (cli *Client) sendNewsletter(to types.JID, id types.MessageID, message *waE
 	return data, nil
 }
 
-func (cli *Client) sendGroup(ctx context.Context, to, ownID types.JID, id types.MessageID, message *waE2E.Message, timings *MessageDebugTimings, botNode *waBinary.Node) (string, []byte, error) {
+func (cli *Client) sendGroup(ctx context.Context, to, ownID types.JID, id types.MessageID, message *waE2E.Message, timings *MessageDebugTimings, botNode *waBinary.Node) (string, []types.JID, []byte, error) {
 	var participants []types.JID
 	var err error
 	start := time.Now()
 	if to.Server == types.GroupServer {
 		participants, err = cli.getGroupMembers(ctx, to)
 		if err != nil {
-			return "", nil, fmt.Errorf("failed to get group members: %w", err)
+			return "", nil, nil, fmt.Errorf("failed to get group members: %w", err)
 		}
 	} else {
 		// TODO use context
 		participants, err = cli.getBroadcastListParticipants(to)
 		if err != nil {
-			return "", nil, fmt.Errorf("failed to get broadcast list members: %w", err)
+			return "", nil, nil, fmt.Errorf("failed to get broadcast list members: %w", err)
 		}
 	}
 	timings.GetParticipants = time.Since(start)
@@ -628,7 +664,7 @@ func (cli *Client) sendGroup(ctx context.Context, to, ownID types.JID, id types.
 	plaintext, _, err := marshalMessage(to, message)
 	timings.Marshal = time.Since(start)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 
 	start = time.Now()
@@ -636,7 +672,7 @@ func (cli *Client) sendGroup(ctx context.Context, to, ownID types.JID, id types.
 	senderKeyName := protocol.NewSenderKeyName(to.String(), ownID.SignalAddress())
 	signalSKDMessage, err := builder.Create(senderKeyName)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to create sender key distribution message to send %s to %s: %w", id, to, err)
+		return "", nil, nil, fmt.Errorf("failed to create sender key distribution message to send %s to %s: %w", id, to, err)
 	}
 	skdMessage := &waE2E.Message{
 		SenderKeyDistributionMessage: &waE2E.SenderKeyDistributionMessage{
@@ -646,20 +682,20 @@ func (cli *Client) sendGroup(ctx context.Context, to, ownID types.JID, id types.
 	}
 	skdPlaintext, err := proto.Marshal(skdMessage)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to marshal sender key distribution message to send %s to %s: %w", id, to, err)
+		return "", nil, nil, fmt.Errorf("failed to marshal sender key distribution message to send %s to %s: %w", id, to, err)
 	}
 
 	cipher := groups.NewGroupCipher(builder, senderKeyName, cli.Store)
 	encrypted, err := cipher.Encrypt(padMessage(plaintext))
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to encrypt group message to send %s to %s: %w", id, to, err)
+		return "", nil, nil, fmt.Errorf("failed to encrypt group message to send %s to %s: %w", id, to, err)
 	}
 	ciphertext := encrypted.SignedSerialize()
 	timings.GroupEncrypt = time.Since(start)
 
 	node, allDevices, err := cli.prepareMessageNode(ctx, to, ownID, id, message, participants, skdPlaintext, nil, timings, botNode)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 
 	phash := participantListHashV2(allDevices)
@@ -678,9 +714,45 @@ func (cli *Client) sendGroup(ctx context.Context, to, ownID types.JID, id types.
 	data, err := cli.sendNodeAndGetData(*node)
 	timings.Send = time.Since(start)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to send message node: %w", err)
+		return "", nil, nil, fmt.Errorf("failed to send message node: %w", err)
+	}
+	return phash, participants, data, nil
+}
+
+// sendBroadcastList sends a message to a broadcast list created with CreateBroadcastList.
+//
+// Broadcast lists only exist locally, so the message is sent as a separate 1:1 message to each recipient,
+// all using the same message ID. If sending fails for some recipients, the message is still sent to the rest,
+// and the returned error lists the recipients that failed.
+func (cli *Client) sendBroadcastList(ctx context.Context, to types.JID, message *waE2E.Message, req SendRequestExtra) (resp SendResponse, err error) {
+	resp.ID = req.ID
+	start := time.Now()
+	recipients, err := cli.getStoredBroadcastListRecipients(to)
+	resp.DebugTimings.GetParticipants = time.Since(start)
+	if err != nil {
+		err = fmt.Errorf("failed to get broadcast list members: %w", err)
+		return
+	}
+	ownID := cli.getOwnID()
+	var errs []error
+	for _, recipient := range recipients {
+		if recipient.User == ownID.User {
+			continue
+		}
+		recipientResp, sendErr := cli.SendMessage(ctx, recipient, message, req)
+		if sendErr != nil {
+			errs = append(errs, fmt.Errorf("failed to send to %s: %w", recipient, sendErr))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if recipientResp.Timestamp.After(resp.Timestamp) {
+			resp.Timestamp = recipientResp.Timestamp
+		}
 	}
-	return phash, data, nil
+	err = errors.Join(errs...)
+	return
 }
 
 func (cli *Client) sendPeerMessage(to types.JID, id types.MessageID, message *waE2E.Message, timings *MessageDebugTimings) ([]byte, error) {
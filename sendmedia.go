@@ -0,0 +1,234 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pbribeiro/whatsmeow-mysql/msgbuilder"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+)
+
+// MediaSendOptions contains optional parameters for SendImage, SendVideo, SendAudio, SendDocument and SendSticker.
+//
+// Fields that are left empty are filled automatically where possible.
+type MediaSendOptions struct {
+	// The caption of the image, video or document.
+	Caption string
+	// The MIME type of the file. If empty, it's detected from the file contents (or the file name for documents).
+	MimeType string
+	// The file name of a document. Also used for detecting the MIME type if sniffing the contents doesn't work.
+	FileName string
+
+	// A small inline JPEG thumbnail. If empty, one is generated for JPEG, PNG and GIF images.
+	// Videos and documents don't get automatic thumbnails, as generating them requires decoders that aren't available in pure Go.
+	Thumbnail []byte
+	// The dimensions of an image, video or sticker. If zero, they're read from the file for images, WebP stickers and MP4 videos.
+	Width, Height uint32
+	// The length of a video or audio file. If zero, it's read from the file for MP4 videos and Ogg audio.
+	Duration time.Duration
+
+	// Send audio as a voice message.
+	Voice bool
+	// Send a video as a looping GIF without sound.
+	GIF bool
+	// Allow the recipient to view the image, video or voice message only once.
+	ViewOnce bool
+
+	// Context info for replies, mentions and other message metadata (see also the msgbuilder package).
+	ContextInfo *waE2E.ContextInfo
+	// Extra parameters for SendMessage. MediaHandle is filled automatically when sending to newsletters.
+	Extra SendRequestExtra
+}
+
+// uploadForSend uploads the given media for sending to the given chat, using the unencrypted newsletter upload if necessary.
+func (cli *Client) uploadForSend(ctx context.Context, to types.JID, data []byte, mediaType MediaType, opts *MediaSendOptions) (msgbuilder.Media, error) {
+	var uploaded UploadResponse
+	var err error
+	if to.Server == types.NewsletterServer {
+		uploaded, err = cli.UploadNewsletter(ctx, data, mediaType)
+		opts.Extra.MediaHandle = uploaded.Handle
+	} else {
+		uploaded, err = cli.Upload(ctx, data, mediaType)
+	}
+	if err != nil {
+		return msgbuilder.Media{}, fmt.Errorf("failed to upload %s: %w", mediaType, err)
+	}
+	return msgbuilder.Media(uploaded), nil
+}
+
+func readMediaForSend(r io.Reader, opts *MediaSendOptions) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media: %w", err)
+	} else if len(data) == 0 {
+		return nil, fmt.Errorf("media is empty")
+	}
+	if opts.MimeType == "" {
+		opts.MimeType = detectMimeType(data, opts.FileName)
+	}
+	return data, nil
+}
+
+// SendImage uploads the given image and sends it as an image message.
+//
+// The MIME type, dimensions and thumbnail are filled automatically unless specified in the options.
+//
+//	file, _ := os.Open("cat.jpg")
+//	resp, err := cli.SendImage(ctx, chatJID, file, whatsmeow.MediaSendOptions{Caption: "meow"})
+func (cli *Client) SendImage(ctx context.Context, to types.JID, r io.Reader, opts MediaSendOptions) (SendResponse, error) {
+	data, err := readMediaForSend(r, &opts)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	if info, ok := getImageInfo(data); ok {
+		if opts.Width == 0 || opts.Height == 0 {
+			opts.Width, opts.Height = info.Width, info.Height
+		}
+		if opts.Thumbnail == nil {
+			opts.Thumbnail = info.Thumbnail
+		}
+	}
+	media, err := cli.uploadForSend(ctx, to, data, MediaImage, &opts)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	builder := msgbuilder.Image(media, opts.MimeType).
+		Caption(opts.Caption).
+		Dimensions(opts.Width, opts.Height).
+		Thumbnail(opts.Thumbnail).
+		WithContextInfo(opts.ContextInfo)
+	if opts.ViewOnce {
+		builder.ViewOnce()
+	}
+	return cli.SendMessage(ctx, to, builder.Build(), opts.Extra)
+}
+
+// SendVideo uploads the given video and sends it as a video message.
+//
+// The MIME type is detected automatically, and the duration and dimensions are read from MP4 files unless specified in the options.
+// A thumbnail is not generated automatically.
+func (cli *Client) SendVideo(ctx context.Context, to types.JID, r io.Reader, opts MediaSendOptions) (SendResponse, error) {
+	data, err := readMediaForSend(r, &opts)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	if info, ok := getMP4Info(data); ok {
+		if opts.Width == 0 || opts.Height == 0 {
+			opts.Width, opts.Height = info.Width, info.Height
+		}
+		if opts.Duration == 0 {
+			opts.Duration = info.Duration
+		}
+	}
+	media, err := cli.uploadForSend(ctx, to, data, MediaVideo, &opts)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	builder := msgbuilder.Video(media, opts.MimeType).
+		Caption(opts.Caption).
+		Dimensions(opts.Width, opts.Height).
+		Duration(opts.Duration).
+		Thumbnail(opts.Thumbnail).
+		WithContextInfo(opts.ContextInfo)
+	if opts.GIF {
+		builder.GIF()
+	}
+	if opts.ViewOnce {
+		builder.ViewOnce()
+	}
+	return cli.SendMessage(ctx, to, builder.Build(), opts.Extra)
+}
+
+// SendAudio uploads the given audio file and sends it as an audio or voice message.
+//
+// The MIME type is detected automatically, and the duration is read from Ogg files unless specified in the options.
+// Voice messages should be Opus in an Ogg container.
+func (cli *Client) SendAudio(ctx context.Context, to types.JID, r io.Reader, opts MediaSendOptions) (SendResponse, error) {
+	data, err := readMediaForSend(r, &opts)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	if opts.MimeType == "application/ogg" {
+		opts.MimeType = "audio/ogg"
+	}
+	if opts.Voice && opts.MimeType == "audio/ogg" {
+		opts.MimeType = "audio/ogg; codecs=opus"
+	}
+	if opts.Duration == 0 {
+		opts.Duration, _ = getOggDuration(data)
+	}
+	media, err := cli.uploadForSend(ctx, to, data, MediaAudio, &opts)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	builder := msgbuilder.Audio(media, opts.MimeType).
+		Duration(opts.Duration).
+		WithContextInfo(opts.ContextInfo)
+	if opts.Voice {
+		builder.Voice()
+	}
+	if opts.ViewOnce {
+		builder.ViewOnce()
+	}
+	return cli.SendMessage(ctx, to, builder.Build(), opts.Extra)
+}
+
+// SendDocument uploads the given file and sends it as a document message.
+//
+// The MIME type is detected from the file contents or the file name unless specified in the options.
+func (cli *Client) SendDocument(ctx context.Context, to types.JID, r io.Reader, opts MediaSendOptions) (SendResponse, error) {
+	data, err := readMediaForSend(r, &opts)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	if opts.FileName == "" {
+		opts.FileName = "file"
+	}
+	media, err := cli.uploadForSend(ctx, to, data, MediaDocument, &opts)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	msg := msgbuilder.Document(media, opts.MimeType, opts.FileName).
+		Caption(opts.Caption).
+		Thumbnail(opts.Thumbnail, opts.Width, opts.Height).
+		WithContextInfo(opts.ContextInfo).
+		Build()
+	return cli.SendMessage(ctx, to, msg, opts.Extra)
+}
+
+// SendSticker uploads the given WebP image and sends it as a sticker.
+//
+// The dimensions and animation flag are read from the WebP header.
+func (cli *Client) SendSticker(ctx context.Context, to types.JID, r io.Reader, opts MediaSendOptions) (SendResponse, error) {
+	data, err := readMediaForSend(r, &opts)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	width, height, animated, isWebP := getWebPInfo(data)
+	if !isWebP {
+		return SendResponse{}, ErrInvalidStickerFormat
+	}
+	if opts.Width == 0 || opts.Height == 0 {
+		opts.Width, opts.Height = width, height
+	}
+	media, err := cli.uploadForSend(ctx, to, data, MediaImage, &opts)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	builder := msgbuilder.Sticker(media).
+		Dimensions(opts.Width, opts.Height).
+		WithContextInfo(opts.ContextInfo)
+	if animated {
+		builder.Animated()
+	}
+	return cli.SendMessage(ctx, to, builder.Build(), opts.Extra)
+}
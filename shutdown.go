@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"time"
+
+	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
+)
+
+// ShutdownReport describes the work that was still pending when Client.Shutdown gave up waiting.
+//
+// All fields are zero if the shutdown drained everything before the deadline.
+type ShutdownReport struct {
+	// The number of incoming nodes that were still queued or being handled.
+	UnhandledNodes int
+	// The number of incoming nodes that hadn't been acknowledged yet.
+	UnsentAcks int
+	// The number of delivery or protocol receipts that hadn't been sent yet.
+	UnsentReceipts int
+	// The number of info queries and message sends still waiting for a response from the server.
+	PendingRequests int
+}
+
+// Complete returns true if there was no pending work left when the client was disconnected.
+func (sr ShutdownReport) Complete() bool {
+	return sr.UnhandledNodes == 0 && sr.UnsentAcks == 0 && sr.UnsentReceipts == 0 && sr.PendingRequests == 0
+}
+
+// shutdownPollInterval is how often Shutdown checks whether the pending work has been drained.
+const shutdownPollInterval = 50 * time.Millisecond
+
+// Shutdown gracefully disconnects the client.
+//
+// Unlike Disconnect, which tears down the websocket immediately, Shutdown goes through the following steps in order:
+//
+//  1. Incoming nodes are no longer queued for handling. They're not acknowledged either,
+//     so the server will send them again after the next connect.
+//  2. New sends are rejected with ErrClientShuttingDown. This includes messages, presences, read receipts and
+//     info queries. Only acks, delivery receipts and keepalives are still sent, so that the drain can complete.
+//  3. Incoming nodes that are already queued are handled and acknowledged.
+//  4. Pending delivery receipts are sent.
+//  5. Outstanding info queries and message sends are given a chance to receive their response.
+//  6. The websocket is closed.
+//
+// Event handlers that run during the shutdown will also get ErrClientShuttingDown if they try to send something.
+//
+// If the context is canceled or its deadline passes before everything is drained, the client is disconnected
+// anyway, and the returned report contains the amount of work that couldn't be completed along with the context error.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+//	defer cancel()
+//	report, err := cli.Shutdown(ctx)
+//	if err != nil {
+//		log.Printf("Shutdown didn't finish cleanly: %+v", report)
+//	}
+//
+// Calling Connect after Shutdown will make the client accept new sends again.
+func (cli *Client) Shutdown(ctx context.Context) (report ShutdownReport, err error) {
+	if cli == nil {
+		err = ErrClientIsNil
+		return
+	}
+	cli.shuttingDown.Store(true)
+	defer cli.Disconnect()
+	if !cli.IsConnected() {
+		report = cli.getShutdownReport()
+		return
+	}
+	cli.Log.Debugf("Shutting down, waiting for pending work to finish")
+	steps := []func() bool{
+		func() bool { return cli.pendingNodes.Load() == 0 },
+		func() bool { return cli.pendingAcks.Load() == 0 },
+		func() bool { return cli.pendingReceipts.Load() == 0 },
+		func() bool {
+			cli.responseWaitersLock.Lock()
+			defer cli.responseWaitersLock.Unlock()
+			return len(cli.responseWaiters) == 0
+		},
+	}
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for _, isDone := range steps {
+		for !isDone() {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				err = ctx.Err()
+				report = cli.getShutdownReport()
+				cli.Log.Warnf("Shutdown deadline reached with pending work: %+v", report)
+				return
+			}
+		}
+	}
+	cli.Log.Debugf("All pending work finished, disconnecting")
+	return
+}
+
+// IsShuttingDown returns true if Shutdown has been called and Connect hasn't been called after it.
+func (cli *Client) IsShuttingDown() bool {
+	return cli != nil && cli.shuttingDown.Load()
+}
+
+// isAllowedDuringShutdown returns true if the given outgoing node is needed to finish the work that
+// Shutdown is waiting for. Everything else is rejected with ErrClientShuttingDown.
+//
+// Public methods that send receipts (like MarkRead) check IsShuttingDown themselves,
+// as the receipt tag is allowed here for delivery receipts of the nodes being drained.
+func isAllowedDuringShutdown(node *waBinary.Node) bool {
+	switch node.Tag {
+	case "ack", "receipt":
+		return true
+	case "iq":
+		switch node.Attrs["type"] {
+		case "result", "error":
+			return true
+		case "get":
+			// Keepalives, so that the connection isn't dropped while draining
+			return node.Attrs["xmlns"] == "w:p"
+		}
+	}
+	return false
+}
+
+// getShutdownReport reads the pending work counters. The counters are never reset: each unit of work decrements
+// the counter it incremented, even if it finishes after a reconnect, so work left over from a previous connection
+// stays counted until it's actually done.
+func (cli *Client) getShutdownReport() ShutdownReport {
+	cli.responseWaitersLock.Lock()
+	pendingRequests := len(cli.responseWaiters)
+	cli.responseWaitersLock.Unlock()
+	return ShutdownReport{
+		UnhandledNodes:  int(cli.pendingNodes.Load()),
+		UnsentAcks:      int(cli.pendingAcks.Load()),
+		UnsentReceipts:  int(cli.pendingReceipts.Load()),
+		PendingRequests: pendingRequests,
+	}
+}
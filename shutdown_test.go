@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+func newShutdownTestClient(handlers map[string]nodeHandler) *Client {
+	ownID := types.JID{User: "1000", Device: 2, Server: types.DefaultUserServer}
+	return &Client{
+		Log:             waLog.Noop,
+		recvLog:         waLog.Noop,
+		sendLog:         waLog.Noop,
+		Store:           &store.Device{ID: &ownID, PushName: "Test"},
+		SynchronousAck:  true,
+		handlerQueue:    make(chan *waBinary.Node, 8),
+		nodeHandlers:    handlers,
+		responseWaiters: make(map[string]chan<- *waBinary.Node),
+	}
+}
+
+func marshalFrame(t *testing.T, node waBinary.Node) []byte {
+	t.Helper()
+	data, err := waBinary.Marshal(node)
+	if err != nil {
+		t.Fatalf("failed to marshal node: %v", err)
+	}
+	return data
+}
+
+func TestShutdownStopsIntake(t *testing.T) {
+	cli := newShutdownTestClient(map[string]nodeHandler{"message": func(node *waBinary.Node) {}})
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	waiter := cli.waitResponse("req-1")
+	cli.shuttingDown.Store(true)
+
+	cli.handleFrame(marshalFrame(t, waBinary.Node{Tag: "message", Attrs: waBinary.Attrs{"id": "msg-1", "from": alice}}))
+	if queued := len(cli.handlerQueue); queued != 0 {
+		t.Errorf("expected message received during shutdown to not be queued, got %d queued nodes", queued)
+	} else if pending := cli.pendingNodes.Load(); pending != 0 {
+		t.Errorf("expected no pending nodes, got %d", pending)
+	}
+
+	cli.handleFrame(marshalFrame(t, waBinary.Node{Tag: "iq", Attrs: waBinary.Attrs{"id": "req-1", "type": "result"}}))
+	select {
+	case resp := <-waiter:
+		if resp.Attrs["id"] != "req-1" {
+			t.Errorf("unexpected response %s", resp.XMLString())
+		}
+	default:
+		t.Errorf("expected response to be delivered during shutdown")
+	}
+}
+
+func TestShutdownRejectsSends(t *testing.T) {
+	cli := newShutdownTestClient(nil)
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	cli.shuttingDown.Store(true)
+
+	if err := cli.SendPresence(types.PresenceAvailable); !errors.Is(err, ErrClientShuttingDown) {
+		t.Errorf("expected SendPresence to be rejected, got %v", err)
+	}
+	if err := cli.SendChatPresence(alice, types.ChatPresenceComposing, ""); !errors.Is(err, ErrClientShuttingDown) {
+		t.Errorf("expected SendChatPresence to be rejected, got %v", err)
+	}
+	if err := cli.MarkRead([]types.MessageID{"msg-1"}, time.Now(), alice, alice); !errors.Is(err, ErrClientShuttingDown) {
+		t.Errorf("expected MarkRead to be rejected, got %v", err)
+	}
+	if _, err := cli.sendIQ(infoQuery{Namespace: "w:g2", Type: iqGet, To: types.ServerJID}); !errors.Is(err, ErrClientShuttingDown) {
+		t.Errorf("expected info query to be rejected, got %v", err)
+	}
+	// Acks for nodes that are being drained must still go out (they fail here only because there's no socket)
+	if err := cli.sendNode(waBinary.Node{Tag: "ack", Attrs: waBinary.Attrs{"id": "msg-1", "class": "message"}}); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("expected ack to be allowed during shutdown, got %v", err)
+	}
+	if _, err := cli.sendIQ(infoQuery{Namespace: "w:p", Type: iqGet, To: types.ServerJID}); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("expected keepalive to be allowed during shutdown, got %v", err)
+	}
+
+	cli.shuttingDown.Store(false)
+	if err := cli.SendChatPresence(alice, types.ChatPresenceComposing, ""); errors.Is(err, ErrClientShuttingDown) {
+		t.Errorf("expected sends to be accepted again after reconnecting, got %v", err)
+	}
+}
+
+func TestShutdownCountersSurviveRestart(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	cli := newShutdownTestClient(map[string]nodeHandler{"message": func(node *waBinary.Node) {
+		close(started)
+		<-unblock
+	}})
+	alice := types.NewJID("1111", types.DefaultUserServer)
+
+	cli.handleFrame(marshalFrame(t, waBinary.Node{Tag: "message", Attrs: waBinary.Attrs{"id": "msg-1", "from": alice}}))
+	ctx, cancel := context.WithCancel(context.Background())
+	go cli.handlerQueueLoop(ctx)
+	<-started
+	sendAck := cli.maybeDeferredAck(&waBinary.Node{Tag: "message", Attrs: waBinary.Attrs{"id": "msg-1", "from": alice}})
+
+	// Reconnect while the handler of the previous connection is still running
+	cancel()
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go cli.handlerQueueLoop(ctx)
+
+	if report := cli.getShutdownReport(); report.UnhandledNodes != 1 || report.UnsentAcks != 1 {
+		t.Errorf("expected work from the previous connection to still be counted, got %+v", report)
+	}
+	close(unblock)
+	sendAck()
+	deadline := time.Now().Add(time.Second)
+	for cli.pendingNodes.Load() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if report := cli.getShutdownReport(); !report.Complete() {
+		t.Errorf("expected all work to be finished without going negative, got %+v", report)
+	}
+}
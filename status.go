@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+// StatusExpiry is how long statuses are visible after being posted.
+const StatusExpiry = 24 * time.Hour
+
+// statusPruneInterval is the minimum time between deleting expired statuses from the database.
+const statusPruneInterval = 1 * time.Hour
+
+// TextStatusOptions contains optional parameters for SendTextStatus.
+type TextStatusOptions struct {
+	// The background color as an ARGB value, e.g. 0xFF1E6E4F. Defaults to a dark green.
+	BackgroundColor uint32
+	// The text color as an ARGB value. Defaults to white.
+	TextColor uint32
+	// The font of the text.
+	Font waE2E.ExtendedTextMessage_FontType
+}
+
+// SendTextStatus posts a text status with the given colors and font.
+//
+// Like all status messages, it's sent to the recipients allowed by the default status privacy setting (see GetStatusPrivacy).
+func (cli *Client) SendTextStatus(ctx context.Context, text string, opts TextStatusOptions) (SendResponse, error) {
+	if opts.BackgroundColor == 0 {
+		opts.BackgroundColor = 0xFF1E6E4F
+	}
+	if opts.TextColor == 0 {
+		opts.TextColor = 0xFFFFFFFF
+	}
+	return cli.SendMessage(ctx, types.StatusBroadcastJID, &waE2E.Message{
+		ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text:           proto.String(text),
+			BackgroundArgb: proto.Uint32(opts.BackgroundColor),
+			TextArgb:       proto.Uint32(opts.TextColor),
+			Font:           opts.Font.Enum(),
+		},
+	})
+}
+
+// SendImageStatus uploads the given image and posts it as a status. See SendImage for details about the options.
+func (cli *Client) SendImageStatus(ctx context.Context, r io.Reader, opts MediaSendOptions) (SendResponse, error) {
+	return cli.SendImage(ctx, types.StatusBroadcastJID, r, opts)
+}
+
+// SendVideoStatus uploads the given video and posts it as a status. See SendVideo for details about the options.
+func (cli *Client) SendVideoStatus(ctx context.Context, r io.Reader, opts MediaSendOptions) (SendResponse, error) {
+	return cli.SendVideo(ctx, types.StatusBroadcastJID, r, opts)
+}
+
+// DeleteStatus deletes one of our own statuses for everyone who received it.
+func (cli *Client) DeleteStatus(ctx context.Context, id types.MessageID) (SendResponse, error) {
+	return cli.SendMessage(ctx, types.StatusBroadcastJID, cli.BuildRevoke(types.StatusBroadcastJID, types.EmptyJID, id))
+}
+
+// MarkStatusViewed sends a view receipt for the given status, which shows up in the sender's list of viewers.
+//
+// If read receipts are disabled in the privacy settings, the receipt is only sent to our own devices.
+func (cli *Client) MarkStatusViewed(info *types.MessageInfo) error {
+	return cli.MarkRead([]types.MessageID{info.ID}, time.Now(), types.StatusBroadcastJID, info.Sender)
+}
+
+// GetReceivedStatuses returns the received statuses that haven't expired yet, sorted from oldest to newest.
+// If sender is not empty, only statuses from that user are returned.
+//
+// Statuses are stored in the database as they're received, so the list is kept across restarts.
+// Deleted statuses are removed from the list.
+func (cli *Client) GetReceivedStatuses(sender types.JID) ([]store.ReceivedStatus, error) {
+	if !sender.IsEmpty() {
+		sender = cli.normalizeLID(sender.ToNonAD())
+	}
+	return cli.Store.Statuses.GetReceivedStatuses(sender, time.Now().Add(-StatusExpiry))
+}
+
+// pruneReceivedStatuses deletes expired statuses from the database, at most once per statusPruneInterval.
+func (cli *Client) pruneReceivedStatuses() {
+	lastPrune := cli.lastStatusPrune.Load()
+	if time.Since(time.Unix(lastPrune, 0)) < statusPruneInterval || !cli.lastStatusPrune.CompareAndSwap(lastPrune, time.Now().Unix()) {
+		return
+	}
+	deleted, err := cli.Store.Statuses.DeleteReceivedStatusesBefore(time.Now().Add(-StatusExpiry))
+	if err != nil {
+		cli.Log.Warnf("Failed to delete expired statuses: %v", err)
+	} else if deleted > 0 {
+		cli.Log.Debugf("Deleted %d expired statuses", deleted)
+	}
+}
+
+func (cli *Client) trackStatus(evt *events.Message) {
+	// Statuses are keyed by sender, as the IDs are only unique per sender
+	sender := cli.normalizeLID(evt.Info.Sender.ToNonAD())
+	if protoMsg := evt.Message.GetProtocolMessage(); protoMsg != nil {
+		if protoMsg.GetType() == waE2E.ProtocolMessage_REVOKE {
+			err := cli.Store.Statuses.DeleteReceivedStatus(sender, protoMsg.GetKey().GetID())
+			if err != nil {
+				cli.Log.Warnf("Failed to delete revoked status %s from %s: %v", protoMsg.GetKey().GetID(), sender, err)
+			}
+		}
+		return
+	} else if isOnlySenderKeyDistribution(evt.Message) {
+		// The sender key is sent in a separate encrypted part with the same message ID as the status itself
+		return
+	}
+	err := cli.Store.Statuses.PutReceivedStatus(store.ReceivedStatus{
+		Sender:    sender,
+		ID:        evt.Info.ID,
+		PushName:  evt.Info.PushName,
+		Timestamp: evt.Info.Timestamp,
+		Message:   evt.Message,
+	})
+	if err != nil {
+		cli.Log.Warnf("Failed to store status %s from %s: %v", evt.Info.ID, sender, err)
+	}
+	cli.pruneReceivedStatuses()
+}
+
+func isOnlySenderKeyDistribution(msg *waE2E.Message) bool {
+	if msg.GetSenderKeyDistributionMessage() == nil {
+		return false
+	}
+	content := proto.Clone(msg).(*waE2E.Message)
+	content.SenderKeyDistributionMessage = nil
+	content.MessageContextInfo = nil
+	return proto.Size(content) == 0
+}
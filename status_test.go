@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waCommon"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/store"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+type receivedStatusKey struct {
+	Sender types.JID
+	ID     types.MessageID
+}
+
+type memoryStatusStore struct {
+	statuses map[receivedStatusKey]store.ReceivedStatus
+}
+
+func (m *memoryStatusStore) PutReceivedStatus(status store.ReceivedStatus) error {
+	m.statuses[receivedStatusKey{status.Sender, status.ID}] = status
+	return nil
+}
+
+func (m *memoryStatusStore) DeleteReceivedStatus(sender types.JID, id types.MessageID) error {
+	delete(m.statuses, receivedStatusKey{sender, id})
+	return nil
+}
+
+func (m *memoryStatusStore) GetReceivedStatuses(sender types.JID, since time.Time) ([]store.ReceivedStatus, error) {
+	var output []store.ReceivedStatus
+	for _, status := range m.statuses {
+		if (sender.IsEmpty() || status.Sender == sender) && status.Timestamp.After(since) {
+			output = append(output, status)
+		}
+	}
+	slices.SortFunc(output, func(a, b store.ReceivedStatus) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return output, nil
+}
+
+func (m *memoryStatusStore) DeleteReceivedStatusesBefore(before time.Time) (int64, error) {
+	var deleted int64
+	for key, status := range m.statuses {
+		if status.Timestamp.Before(before) {
+			delete(m.statuses, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newStatusTestClient() (*Client, *memoryStatusStore) {
+	ownID := types.JID{User: "1000", Device: 2, Server: types.DefaultUserServer}
+	device := *store.NoopDevice
+	device.ID = &ownID
+	statuses := &memoryStatusStore{statuses: make(map[receivedStatusKey]store.ReceivedStatus)}
+	device.Statuses = statuses
+	return NewClient(&device, waLog.Noop), statuses
+}
+
+func makeStatusEvent(sender types.JID, id types.MessageID, timestamp time.Time, msg *waE2E.Message) *events.Message {
+	return &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{Chat: types.StatusBroadcastJID, Sender: sender, IsGroup: true},
+			ID:            id,
+			Timestamp:     timestamp,
+		},
+		Message: msg,
+	}
+}
+
+func TestReceivedStatusesKeyedBySender(t *testing.T) {
+	cli, statuses := newStatusTestClient()
+	alice := types.JID{User: "1111", Device: 3, Server: types.DefaultUserServer}
+	bob := types.NewJID("2222", types.DefaultUserServer)
+	now := time.Now().Truncate(time.Second)
+	cli.trackStatus(makeStatusEvent(bob, "status-old", now.Add(-StatusExpiry-time.Minute), &waE2E.Message{Conversation: proto.String("Old")}))
+	// Status IDs are only unique per sender
+	cli.trackStatus(makeStatusEvent(alice, "status-1", now.Add(-time.Hour), &waE2E.Message{Conversation: proto.String("Hello")}))
+	cli.trackStatus(makeStatusEvent(bob, "status-1", now, &waE2E.Message{Conversation: proto.String("Hi")}))
+	if received, _ := cli.GetReceivedStatuses(types.EmptyJID); len(received) != 2 || received[0].Sender != alice.ToNonAD() || received[1].Sender != bob {
+		t.Fatalf("expected unexpired statuses from both senders, got %+v", received)
+	} else if len(statuses.statuses) != 2 {
+		t.Errorf("expected expired status to be pruned, got %+v", statuses.statuses)
+	}
+
+	cli.trackStatus(makeStatusEvent(bob, "revoke", now, &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{
+		Type: waE2E.ProtocolMessage_REVOKE.Enum(),
+		Key:  &waCommon.MessageKey{RemoteJID: proto.String(types.StatusBroadcastJID.String()), ID: proto.String("status-1")},
+	}}))
+	if received, _ := cli.GetReceivedStatuses(types.EmptyJID); len(received) != 1 || received[0].Sender != alice.ToNonAD() {
+		t.Errorf("expected revoke to only delete the sender's own status, got %+v", received)
+	}
+}
+
+func TestReceivedStatusesFromLID(t *testing.T) {
+	cli, _ := newStatusTestClient()
+	alice := types.NewJID("1111", types.DefaultUserServer)
+	aliceLID := types.NewJID("98765", types.HiddenUserServer)
+	cli.storeLIDMapping(aliceLID, alice)
+	cli.trackStatus(makeStatusEvent(types.JID{User: "98765", Device: 4, Server: types.HiddenUserServer}, "status-1", time.Now(), &waE2E.Message{Conversation: proto.String("Hello")}))
+	cli.trackStatus(makeStatusEvent(aliceLID, "status-2", time.Now(), &waE2E.Message{
+		SenderKeyDistributionMessage: &waE2E.SenderKeyDistributionMessage{GroupID: proto.String(types.StatusBroadcastJID.String())},
+	}))
+	for _, sender := range []types.JID{alice, aliceLID} {
+		if received, _ := cli.GetReceivedStatuses(sender); len(received) != 1 || received[0].ID != "status-1" || received[0].Sender != alice {
+			t.Errorf("expected status from %s to be stored under the phone number, got %+v", sender, received)
+		}
+	}
+}
@@ -25,17 +25,26 @@ var NoopDevice = &Device{
 	NoiseKey:    nilKey,
 	IdentityKey: nilKey,
 
-	Identities:    nilStore,
-	Sessions:      nilStore,
-	PreKeys:       nilStore,
-	SenderKeys:    nilStore,
-	AppStateKeys:  nilStore,
-	AppState:      nilStore,
-	Contacts:      nilStore,
-	ChatSettings:  nilStore,
-	MsgSecrets:    nilStore,
-	PrivacyTokens: nilStore,
-	Container:     nilStore,
+	Identities:           nilStore,
+	Sessions:             nilStore,
+	PreKeys:              nilStore,
+	SenderKeys:           nilStore,
+	AppStateKeys:         nilStore,
+	AppState:             nilStore,
+	Contacts:             nilStore,
+	ChatSettings:         nilStore,
+	MsgSecrets:           nilStore,
+	PrivacyTokens:        nilStore,
+	Statuses:             nilStore,
+	BroadcastLists:       nilStore,
+	ScheduledMessages:    nilStore,
+	Polls:                nilStore,
+	MessageStates:        nilStore,
+	DecryptionFailures:   nilStore,
+	MessageStatuses:      nilStore,
+	PresenceHistory:      nilStore,
+	DisappearingMessages: nilStore,
+	Container:            nilStore,
 }
 
 var _ AllStores = (*NoopStore)(nil)
@@ -205,6 +214,178 @@ func (n *NoopStore) GetPrivacyToken(user types.JID) (*PrivacyToken, error) {
 	return nil, n.Error
 }
 
+func (n *NoopStore) PutReceivedStatus(status ReceivedStatus) error {
+	return n.Error
+}
+
+func (n *NoopStore) DeleteReceivedStatus(sender types.JID, id types.MessageID) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetReceivedStatuses(sender types.JID, since time.Time) ([]ReceivedStatus, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) DeleteReceivedStatusesBefore(before time.Time) (int64, error) {
+	return 0, n.Error
+}
+
+func (n *NoopStore) PutBroadcastList(list BroadcastList) error {
+	return n.Error
+}
+
+func (n *NoopStore) RenameBroadcastList(list types.JID, name string) error {
+	return n.Error
+}
+
+func (n *NoopStore) DeleteBroadcastList(list types.JID) error {
+	return n.Error
+}
+
+func (n *NoopStore) AddBroadcastListRecipients(list types.JID, recipients []types.JID) error {
+	return n.Error
+}
+
+func (n *NoopStore) RemoveBroadcastListRecipients(list types.JID, recipients []types.JID) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetBroadcastList(list types.JID) (*BroadcastList, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) GetAllBroadcastLists() ([]BroadcastList, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) PutScheduledMessage(msg ScheduledMessage) error {
+	return n.Error
+}
+
+func (n *NoopStore) DeleteScheduledMessage(id types.MessageID) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetScheduledMessage(id types.MessageID) (*ScheduledMessage, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) GetScheduledMessagesBefore(until time.Time) ([]ScheduledMessage, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) GetNextScheduledMessage() (*ScheduledMessage, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) GetAllScheduledMessages() ([]ScheduledMessage, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) PutPoll(poll Poll) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetPoll(chat, sender types.JID, id types.MessageID) (*Poll, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) PutPollVote(chat, sender types.JID, id types.MessageID, vote PollVote) (bool, error) {
+	return false, n.Error
+}
+
+func (n *NoopStore) GetPollVotes(chat, sender types.JID, id types.MessageID) ([]PollVote, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) PutMessageEdit(chat, sender types.JID, id types.MessageID, text string, timestamp time.Time) (bool, error) {
+	return false, n.Error
+}
+
+func (n *NoopStore) PutMessageReaction(chat, sender types.JID, id types.MessageID, reaction types.MessageReaction) (bool, error) {
+	return false, n.Error
+}
+
+func (n *NoopStore) PutMessageRevoked(chat, sender types.JID, id types.MessageID) error {
+	return n.Error
+}
+
+func (n *NoopStore) PutMessageDeletedForMe(chat, sender types.JID, id types.MessageID) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetMessageState(chat, sender types.JID, id types.MessageID) (*types.MessageState, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) PutDecryptionFailure(failure types.DecryptionFailure) error {
+	return n.Error
+}
+
+func (n *NoopStore) DeleteDecryptionFailure(chat, sender types.JID, id types.MessageID) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetAllDecryptionFailures() ([]types.DecryptionFailure, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) IncrementDecryptionStats(cause types.DecryptionFailureCause, delta types.DecryptionRecoveryStats) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetDecryptionStats() (map[types.DecryptionFailureCause]types.DecryptionRecoveryStats, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) PutMessageRecipients(chat types.JID, id types.MessageID, recipients []types.JID, sentAt time.Time) error {
+	return n.Error
+}
+
+func (n *NoopStore) PutMessageReceipt(id types.MessageID, recipient types.JID, receiptType types.ReceiptType, timestamp time.Time) (bool, error) {
+	return false, n.Error
+}
+
+func (n *NoopStore) GetMessageStatus(id types.MessageID) (*types.MessageStatus, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) DeleteMessageStatusesBefore(before time.Time) (int64, error) {
+	return 0, n.Error
+}
+
+func (n *NoopStore) PutPresenceHistory(presence types.UserPresence) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetPresenceHistory(user types.JID, since, until time.Time) ([]types.UserPresence, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) DeletePresenceHistoryBefore(before time.Time) (int64, error) {
+	return 0, n.Error
+}
+
+func (n *NoopStore) PutDisappearingTimer(chat types.JID, timer time.Duration, settingTimestamp time.Time) (bool, error) {
+	return false, n.Error
+}
+
+func (n *NoopStore) GetDisappearingTimer(chat types.JID) (time.Duration, error) {
+	return 0, n.Error
+}
+
+func (n *NoopStore) PutExpiringMessage(msg types.ExpiringMessage) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetExpiredMessages(before time.Time, limit int) ([]types.ExpiringMessage, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) DeleteExpiringMessage(chat, sender types.JID, id types.MessageID) error {
+	return n.Error
+}
+
 func (n *NoopStore) PutDevice(store *Device) error {
 	return n.Error
 }
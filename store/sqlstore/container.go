@@ -151,6 +151,15 @@ func (c *Container) scanDevice(row scannable) (*store.Device, error) {
 	device.ChatSettings = innerStore
 	device.MsgSecrets = innerStore
 	device.PrivacyTokens = innerStore
+	device.Statuses = innerStore
+	device.BroadcastLists = innerStore
+	device.ScheduledMessages = innerStore
+	device.Polls = innerStore
+	device.MessageStates = innerStore
+	device.DecryptionFailures = innerStore
+	device.MessageStatuses = innerStore
+	device.PresenceHistory = innerStore
+	device.DisappearingMessages = innerStore
 	device.Container = c
 	device.Initialized = true
 
@@ -273,6 +282,15 @@ func (c *Container) PutDevice(device *store.Device) error {
 		device.ChatSettings = innerStore
 		device.MsgSecrets = innerStore
 		device.PrivacyTokens = innerStore
+		device.Statuses = innerStore
+		device.BroadcastLists = innerStore
+		device.ScheduledMessages = innerStore
+		device.Polls = innerStore
+		device.MessageStates = innerStore
+		device.DecryptionFailures = innerStore
+		device.MessageStatuses = innerStore
+		device.PresenceHistory = innerStore
+		device.DisappearingMessages = innerStore
 		device.Initialized = true
 	}
 	return err
@@ -8,14 +8,20 @@
 package sqlstore
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
 	"github.com/pbribeiro/whatsmeow-mysql/store"
 	"github.com/pbribeiro/whatsmeow-mysql/types"
 	"github.com/pbribeiro/whatsmeow-mysql/util/keys"
@@ -795,3 +801,862 @@ func (s *SQLStore) GetPrivacyToken(user types.JID) (*store.PrivacyToken, error)
 		return &token, nil
 	}
 }
+
+const (
+	putReceivedStatusQuery = `
+		INSERT INTO whatsmeow_received_statuses (our_jid, sender_jid, status_id, push_name, timestamp, message)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE push_name=VALUES(push_name), timestamp=VALUES(timestamp), message=VALUES(message)
+	`
+	deleteReceivedStatusQuery = `DELETE FROM whatsmeow_received_statuses WHERE our_jid=? AND sender_jid=? AND status_id=?`
+	getReceivedStatusesQuery  = `
+		SELECT sender_jid, status_id, push_name, timestamp, message FROM whatsmeow_received_statuses
+		WHERE our_jid=? AND timestamp>? ORDER BY timestamp
+	`
+	getReceivedStatusesBySenderQuery = `
+		SELECT sender_jid, status_id, push_name, timestamp, message FROM whatsmeow_received_statuses
+		WHERE our_jid=? AND sender_jid=? AND timestamp>? ORDER BY timestamp
+	`
+	deleteReceivedStatusesBeforeQuery = `DELETE FROM whatsmeow_received_statuses WHERE our_jid=? AND timestamp<?`
+)
+
+func (s *SQLStore) PutReceivedStatus(status store.ReceivedStatus) error {
+	data, err := proto.Marshal(status.Message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status: %w", err)
+	}
+	_, err = s.db.Exec(putReceivedStatusQuery, s.JID, status.Sender.ToNonAD().String(), status.ID, status.PushName, status.Timestamp.Unix(), data)
+	return err
+}
+
+func (s *SQLStore) DeleteReceivedStatus(sender types.JID, id types.MessageID) error {
+	_, err := s.db.Exec(deleteReceivedStatusQuery, s.JID, sender.ToNonAD().String(), id)
+	return err
+}
+
+func (s *SQLStore) GetReceivedStatuses(sender types.JID, since time.Time) ([]store.ReceivedStatus, error) {
+	var rows *sql.Rows
+	var err error
+	if sender.IsEmpty() {
+		rows, err = s.db.Query(getReceivedStatusesQuery, s.JID, since.Unix())
+	} else {
+		rows, err = s.db.Query(getReceivedStatusesBySenderQuery, s.JID, sender.ToNonAD().String(), since.Unix())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var statuses []store.ReceivedStatus
+	for rows.Next() {
+		var status store.ReceivedStatus
+		var timestamp int64
+		var data []byte
+		err = rows.Scan(&status.Sender, &status.ID, &status.PushName, &timestamp, &data)
+		if err != nil {
+			return nil, err
+		}
+		status.Timestamp = time.Unix(timestamp, 0)
+		status.Message = &waE2E.Message{}
+		err = proto.Unmarshal(data, status.Message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal status %s: %w", status.ID, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
+
+func (s *SQLStore) DeleteReceivedStatusesBefore(before time.Time) (int64, error) {
+	res, err := s.db.Exec(deleteReceivedStatusesBeforeQuery, s.JID, before.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+const (
+	putBroadcastListQuery = `
+		INSERT INTO whatsmeow_broadcast_lists (our_jid, list_jid, name, created_at)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE name=VALUES(name)
+	`
+	renameBroadcastListQuery          = `UPDATE whatsmeow_broadcast_lists SET name=? WHERE our_jid=? AND list_jid=?`
+	deleteBroadcastListQuery          = `DELETE FROM whatsmeow_broadcast_lists WHERE our_jid=? AND list_jid=?`
+	clearBroadcastListRecipientsQuery = `DELETE FROM whatsmeow_broadcast_list_recipients WHERE our_jid=? AND list_jid=?`
+	putBroadcastListRecipientQuery    = `
+		INSERT INTO whatsmeow_broadcast_list_recipients (our_jid, list_jid, recipient_jid)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE recipient_jid=recipient_jid
+	`
+	deleteBroadcastListRecipientQuery  = `DELETE FROM whatsmeow_broadcast_list_recipients WHERE our_jid=? AND list_jid=? AND recipient_jid=?`
+	getBroadcastListQuery              = `SELECT list_jid, name, created_at FROM whatsmeow_broadcast_lists WHERE our_jid=? AND list_jid=?`
+	getAllBroadcastListsQuery          = `SELECT list_jid, name, created_at FROM whatsmeow_broadcast_lists WHERE our_jid=? ORDER BY created_at`
+	getBroadcastListRecipientsQuery    = `SELECT list_jid, recipient_jid FROM whatsmeow_broadcast_list_recipients WHERE our_jid=? AND list_jid=?`
+	getAllBroadcastListRecipientsQuery = `SELECT list_jid, recipient_jid FROM whatsmeow_broadcast_list_recipients WHERE our_jid=?`
+)
+
+func (s *SQLStore) PutBroadcastList(list store.BroadcastList) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	_, err = tx.Exec(putBroadcastListQuery, s.JID, list.JID.String(), list.Name, list.CreatedAt.Unix())
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to insert broadcast list: %w", err)
+	}
+	_, err = tx.Exec(clearBroadcastListRecipientsQuery, s.JID, list.JID.String())
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to clear old broadcast list recipients: %w", err)
+	}
+	for _, recipient := range list.Recipients {
+		_, err = tx.Exec(putBroadcastListRecipientQuery, s.JID, list.JID.String(), recipient.ToNonAD().String())
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to insert broadcast list recipient %s: %w", recipient, err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLStore) RenameBroadcastList(list types.JID, name string) error {
+	_, err := s.db.Exec(renameBroadcastListQuery, name, s.JID, list.String())
+	return err
+}
+
+func (s *SQLStore) DeleteBroadcastList(list types.JID) error {
+	_, err := s.db.Exec(deleteBroadcastListQuery, s.JID, list.String())
+	return err
+}
+
+func (s *SQLStore) AddBroadcastListRecipients(list types.JID, recipients []types.JID) error {
+	return s.execBroadcastListRecipients(putBroadcastListRecipientQuery, list, recipients)
+}
+
+func (s *SQLStore) RemoveBroadcastListRecipients(list types.JID, recipients []types.JID) error {
+	return s.execBroadcastListRecipients(deleteBroadcastListRecipientQuery, list, recipients)
+}
+
+func (s *SQLStore) execBroadcastListRecipients(query string, list types.JID, recipients []types.JID) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	for _, recipient := range recipients {
+		_, err = tx.Exec(query, s.JID, list.String(), recipient.ToNonAD().String())
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLStore) scanBroadcastListRecipients(rows *sql.Rows, lists map[types.JID]*store.BroadcastList) error {
+	for rows.Next() {
+		var listJID, recipient types.JID
+		err := rows.Scan(&listJID, &recipient)
+		if err != nil {
+			return fmt.Errorf("failed to scan broadcast list recipient: %w", err)
+		}
+		if list, ok := lists[listJID]; ok {
+			list.Recipients = append(list.Recipients, recipient)
+		}
+	}
+	return rows.Err()
+}
+
+func (s *SQLStore) GetBroadcastList(listJID types.JID) (*store.BroadcastList, error) {
+	var list store.BroadcastList
+	var createdAt int64
+	err := s.db.QueryRow(getBroadcastListQuery, s.JID, listJID.String()).Scan(&list.JID, &list.Name, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	list.CreatedAt = time.Unix(createdAt, 0)
+	rows, err := s.db.Query(getBroadcastListRecipientsQuery, s.JID, listJID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	err = s.scanBroadcastListRecipients(rows, map[types.JID]*store.BroadcastList{list.JID: &list})
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (s *SQLStore) GetAllBroadcastLists() ([]store.BroadcastList, error) {
+	rows, err := s.db.Query(getAllBroadcastListsQuery, s.JID)
+	if err != nil {
+		return nil, err
+	}
+	var lists []*store.BroadcastList
+	listMap := make(map[types.JID]*store.BroadcastList)
+	for rows.Next() {
+		var list store.BroadcastList
+		var createdAt int64
+		err = rows.Scan(&list.JID, &list.Name, &createdAt)
+		if err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan broadcast list: %w", err)
+		}
+		list.CreatedAt = time.Unix(createdAt, 0)
+		lists = append(lists, &list)
+		listMap[list.JID] = &list
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows, err = s.db.Query(getAllBroadcastListRecipientsQuery, s.JID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	err = s.scanBroadcastListRecipients(rows, listMap)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]store.BroadcastList, len(lists))
+	for i, list := range lists {
+		output[i] = *list
+	}
+	return output, nil
+}
+
+const (
+	putScheduledMessageQuery = `
+		INSERT INTO whatsmeow_scheduled_messages (our_jid, message_id, chat_jid, message, send_at, attempts)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE chat_jid=VALUES(chat_jid), message=VALUES(message), send_at=VALUES(send_at), attempts=VALUES(attempts)
+	`
+	deleteScheduledMessageQuery     = `DELETE FROM whatsmeow_scheduled_messages WHERE our_jid=? AND message_id=?`
+	getScheduledMessageQuery        = `SELECT message_id, chat_jid, message, send_at, attempts FROM whatsmeow_scheduled_messages WHERE our_jid=? AND message_id=?`
+	getScheduledMessagesBeforeQuery = `SELECT message_id, chat_jid, message, send_at, attempts FROM whatsmeow_scheduled_messages WHERE our_jid=? AND send_at<=? ORDER BY send_at`
+	getNextScheduledMessageQuery    = `SELECT message_id, chat_jid, message, send_at, attempts FROM whatsmeow_scheduled_messages WHERE our_jid=? ORDER BY send_at LIMIT 1`
+	getAllScheduledMessagesQuery    = `SELECT message_id, chat_jid, message, send_at, attempts FROM whatsmeow_scheduled_messages WHERE our_jid=? ORDER BY send_at`
+)
+
+func (s *SQLStore) PutScheduledMessage(msg store.ScheduledMessage) error {
+	data, err := proto.Marshal(msg.Message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	_, err = s.db.Exec(putScheduledMessageQuery, s.JID, msg.ID, msg.Chat.String(), data, msg.SendAt.Unix(), msg.Attempts)
+	return err
+}
+
+func (s *SQLStore) DeleteScheduledMessage(id types.MessageID) error {
+	_, err := s.db.Exec(deleteScheduledMessageQuery, s.JID, id)
+	return err
+}
+
+func scanScheduledMessage(row scannable) (*store.ScheduledMessage, error) {
+	var msg store.ScheduledMessage
+	var data []byte
+	var sendAt int64
+	err := row.Scan(&msg.ID, &msg.Chat, &data, &sendAt, &msg.Attempts)
+	if err != nil {
+		return nil, err
+	}
+	msg.SendAt = time.Unix(sendAt, 0)
+	msg.Message = &waE2E.Message{}
+	err = proto.Unmarshal(data, msg.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scheduled message %s: %w", msg.ID, err)
+	}
+	return &msg, nil
+}
+
+func (s *SQLStore) scanScheduledMessages(rows *sql.Rows, err error) ([]store.ScheduledMessage, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var output []store.ScheduledMessage
+	for rows.Next() {
+		msg, err := scanScheduledMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, *msg)
+	}
+	return output, rows.Err()
+}
+
+func (s *SQLStore) GetScheduledMessage(id types.MessageID) (*store.ScheduledMessage, error) {
+	msg, err := scanScheduledMessage(s.db.QueryRow(getScheduledMessageQuery, s.JID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return msg, err
+}
+
+func (s *SQLStore) GetScheduledMessagesBefore(until time.Time) ([]store.ScheduledMessage, error) {
+	return s.scanScheduledMessages(s.db.Query(getScheduledMessagesBeforeQuery, s.JID, until.Unix()))
+}
+
+func (s *SQLStore) GetNextScheduledMessage() (*store.ScheduledMessage, error) {
+	msg, err := scanScheduledMessage(s.db.QueryRow(getNextScheduledMessageQuery, s.JID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return msg, err
+}
+
+func (s *SQLStore) GetAllScheduledMessages() ([]store.ScheduledMessage, error) {
+	return s.scanScheduledMessages(s.db.Query(getAllScheduledMessagesQuery, s.JID))
+}
+
+const (
+	putPollQuery = `
+		INSERT INTO whatsmeow_polls (our_jid, chat_jid, sender_jid, poll_id, name, options, selectable_count, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE name=VALUES(name), options=VALUES(options), selectable_count=VALUES(selectable_count)
+	`
+	getPollQuery = `
+		SELECT name, options, selectable_count, created_at FROM whatsmeow_polls
+		WHERE our_jid=? AND chat_jid=? AND sender_jid=? AND poll_id=?
+	`
+	putPollVoteQuery = `
+		INSERT INTO whatsmeow_poll_votes (our_jid, chat_jid, sender_jid, poll_id, voter_jid, selected_options, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE selected_options=VALUES(selected_options), timestamp=VALUES(timestamp)
+	`
+	getPollVoteTimestampQuery = `
+		SELECT timestamp FROM whatsmeow_poll_votes
+		WHERE our_jid=? AND chat_jid=? AND sender_jid=? AND poll_id=? AND voter_jid=?
+	`
+	getPollVotesQuery = `
+		SELECT voter_jid, selected_options, timestamp FROM whatsmeow_poll_votes
+		WHERE our_jid=? AND chat_jid=? AND sender_jid=? AND poll_id=?
+		ORDER BY timestamp
+	`
+)
+
+// putIfNewer runs the insert query in a transaction if the timestamp returned by the select query is older than the given one.
+func (s *SQLStore) putIfNewer(selectQuery string, selectArgs []any, timestamp int64, insertQuery string, insertArgs ...any) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	var prevTimestamp int64
+	err = tx.QueryRow(selectQuery, selectArgs...).Scan(&prevTimestamp)
+	if err == nil && prevTimestamp > timestamp {
+		_ = tx.Rollback()
+		return false, nil
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return false, fmt.Errorf("failed to get previous timestamp: %w", err)
+	}
+	_, err = tx.Exec(insertQuery, insertArgs...)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+func (s *SQLStore) PutPoll(poll store.Poll) error {
+	options, err := json.Marshal(poll.Options)
+	if err != nil {
+		return fmt.Errorf("failed to marshal poll options: %w", err)
+	}
+	_, err = s.db.Exec(
+		putPollQuery, s.JID, poll.Chat.ToNonAD().String(), poll.Sender.ToNonAD().String(), poll.ID,
+		poll.Name, string(options), poll.SelectableCount, poll.CreatedAt.Unix(),
+	)
+	return err
+}
+
+func (s *SQLStore) GetPoll(chat, sender types.JID, id types.MessageID) (*store.Poll, error) {
+	poll := store.Poll{Chat: chat.ToNonAD(), Sender: sender.ToNonAD(), ID: id}
+	var options string
+	var createdAt int64
+	err := s.db.QueryRow(getPollQuery, s.JID, poll.Chat.String(), poll.Sender.String(), id).
+		Scan(&poll.Name, &options, &poll.SelectableCount, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(options), &poll.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal poll options: %w", err)
+	}
+	poll.CreatedAt = time.Unix(createdAt, 0)
+	return &poll, nil
+}
+
+func (s *SQLStore) PutPollVote(chat, sender types.JID, id types.MessageID, vote store.PollVote) (bool, error) {
+	chatStr, senderStr, voterStr := chat.ToNonAD().String(), sender.ToNonAD().String(), vote.Voter.ToNonAD().String()
+	return s.putIfNewer(
+		getPollVoteTimestampQuery, []any{s.JID, chatStr, senderStr, id, voterStr}, vote.Timestamp.UnixMilli(),
+		putPollVoteQuery, s.JID, chatStr, senderStr, id, voterStr, bytes.Join(vote.SelectedOptions, nil), vote.Timestamp.UnixMilli(),
+	)
+}
+
+func (s *SQLStore) GetPollVotes(chat, sender types.JID, id types.MessageID) ([]store.PollVote, error) {
+	rows, err := s.db.Query(getPollVotesQuery, s.JID, chat.ToNonAD().String(), sender.ToNonAD().String(), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var votes []store.PollVote
+	for rows.Next() {
+		var vote store.PollVote
+		var selected []byte
+		var timestamp int64
+		err = rows.Scan(&vote.Voter, &selected, &timestamp)
+		if err != nil {
+			return nil, err
+		} else if len(selected)%sha256.Size != 0 {
+			return nil, ErrInvalidLength
+		}
+		for len(selected) > 0 {
+			vote.SelectedOptions = append(vote.SelectedOptions, selected[:sha256.Size:sha256.Size])
+			selected = selected[sha256.Size:]
+		}
+		vote.Timestamp = time.UnixMilli(timestamp)
+		votes = append(votes, vote)
+	}
+	return votes, rows.Err()
+}
+
+const (
+	getMessageEditTimestampQuery = `
+		SELECT COALESCE(edited_at, 0) FROM whatsmeow_message_states WHERE our_jid=? AND chat_jid=? AND sender_jid=? AND message_id=?
+	`
+	putMessageEditQuery = `
+		INSERT INTO whatsmeow_message_states (our_jid, chat_jid, sender_jid, message_id, edited_text, edited_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE edited_text=VALUES(edited_text), edited_at=VALUES(edited_at)
+	`
+	putMessageRevokedQuery = `
+		INSERT INTO whatsmeow_message_states (our_jid, chat_jid, sender_jid, message_id, revoked)
+		VALUES (?, ?, ?, ?, true)
+		ON DUPLICATE KEY UPDATE revoked=true
+	`
+	putMessageDeletedForMeQuery = `
+		INSERT INTO whatsmeow_message_states (our_jid, chat_jid, sender_jid, message_id, deleted_for_me)
+		VALUES (?, ?, ?, ?, true)
+		ON DUPLICATE KEY UPDATE deleted_for_me=true
+	`
+	getMessageStateQuery = `
+		SELECT edited_text, edited_at, revoked, deleted_for_me FROM whatsmeow_message_states
+		WHERE our_jid=? AND chat_jid=? AND sender_jid=? AND message_id=?
+	`
+	getMessageReactionTimestampQuery = `
+		SELECT timestamp FROM whatsmeow_message_reactions
+		WHERE our_jid=? AND chat_jid=? AND sender_jid=? AND message_id=? AND reactor_jid=?
+	`
+	putMessageReactionQuery = `
+		INSERT INTO whatsmeow_message_reactions (our_jid, chat_jid, sender_jid, message_id, reactor_jid, emoji, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE emoji=VALUES(emoji), timestamp=VALUES(timestamp)
+	`
+	getMessageReactionsQuery = `
+		SELECT reactor_jid, emoji, timestamp FROM whatsmeow_message_reactions
+		WHERE our_jid=? AND chat_jid=? AND sender_jid=? AND message_id=? AND emoji<>''
+		ORDER BY timestamp
+	`
+)
+
+func (s *SQLStore) PutMessageEdit(chat, sender types.JID, id types.MessageID, text string, timestamp time.Time) (bool, error) {
+	chatStr, senderStr := chat.ToNonAD().String(), sender.ToNonAD().String()
+	return s.putIfNewer(
+		getMessageEditTimestampQuery, []any{s.JID, chatStr, senderStr, id}, timestamp.UnixMilli(),
+		putMessageEditQuery, s.JID, chatStr, senderStr, id, text, timestamp.UnixMilli(),
+	)
+}
+
+func (s *SQLStore) PutMessageReaction(chat, sender types.JID, id types.MessageID, reaction types.MessageReaction) (bool, error) {
+	chatStr, senderStr, reactorStr := chat.ToNonAD().String(), sender.ToNonAD().String(), reaction.Reactor.ToNonAD().String()
+	return s.putIfNewer(
+		getMessageReactionTimestampQuery, []any{s.JID, chatStr, senderStr, id, reactorStr}, reaction.Timestamp.UnixMilli(),
+		putMessageReactionQuery, s.JID, chatStr, senderStr, id, reactorStr, reaction.Emoji, reaction.Timestamp.UnixMilli(),
+	)
+}
+
+func (s *SQLStore) PutMessageRevoked(chat, sender types.JID, id types.MessageID) error {
+	_, err := s.db.Exec(putMessageRevokedQuery, s.JID, chat.ToNonAD().String(), sender.ToNonAD().String(), id)
+	return err
+}
+
+func (s *SQLStore) PutMessageDeletedForMe(chat, sender types.JID, id types.MessageID) error {
+	_, err := s.db.Exec(putMessageDeletedForMeQuery, s.JID, chat.ToNonAD().String(), sender.ToNonAD().String(), id)
+	return err
+}
+
+func (s *SQLStore) GetMessageState(chat, sender types.JID, id types.MessageID) (*types.MessageState, error) {
+	state := types.MessageState{Chat: chat.ToNonAD(), Sender: sender.ToNonAD(), ID: id}
+	var editedText sql.NullString
+	var editedAt sql.NullInt64
+	err := s.db.QueryRow(getMessageStateQuery, s.JID, state.Chat.String(), state.Sender.String(), id).
+		Scan(&editedText, &editedAt, &state.Revoked, &state.DeletedForMe)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	state.EditedText = editedText.String
+	if editedAt.Int64 > 0 {
+		state.EditedAt = time.UnixMilli(editedAt.Int64)
+	}
+	rows, err := s.db.Query(getMessageReactionsQuery, s.JID, state.Chat.String(), state.Sender.String(), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var reaction types.MessageReaction
+		var timestamp int64
+		err = rows.Scan(&reaction.Reactor, &reaction.Emoji, &timestamp)
+		if err != nil {
+			return nil, err
+		}
+		reaction.Timestamp = time.UnixMilli(timestamp)
+		state.Reactions = append(state.Reactions, reaction)
+	}
+	return &state, rows.Err()
+}
+
+const (
+	putDecryptionFailureQuery = `
+		INSERT INTO whatsmeow_decryption_failures (our_jid, message_id, chat_jid, sender_jid, cause, failed_at, failures, retry_receipts, phone_requested)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE failures=VALUES(failures), retry_receipts=VALUES(retry_receipts), phone_requested=VALUES(phone_requested)
+	`
+	deleteDecryptionFailureQuery  = `DELETE FROM whatsmeow_decryption_failures WHERE our_jid=? AND chat_jid=? AND sender_jid=? AND message_id=?`
+	getAllDecryptionFailuresQuery = `
+		SELECT message_id, chat_jid, sender_jid, cause, failed_at, failures, retry_receipts, phone_requested
+		FROM whatsmeow_decryption_failures WHERE our_jid=? ORDER BY failed_at
+	`
+	incrementDecryptionStatsQuery = `
+		INSERT INTO whatsmeow_decryption_stats (our_jid, cause, failed, recovered, gave_up)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE failed=failed+VALUES(failed), recovered=recovered+VALUES(recovered), gave_up=gave_up+VALUES(gave_up)
+	`
+	getDecryptionStatsQuery = `SELECT cause, failed, recovered, gave_up FROM whatsmeow_decryption_stats WHERE our_jid=?`
+)
+
+func (s *SQLStore) PutDecryptionFailure(failure types.DecryptionFailure) error {
+	_, err := s.db.Exec(
+		putDecryptionFailureQuery, s.JID, failure.ID, failure.Chat.String(), failure.Sender.String(), string(failure.Cause),
+		failure.FailedAt.Unix(), failure.Failures, failure.RetryReceipts, failure.PhoneRequested,
+	)
+	return err
+}
+
+func (s *SQLStore) DeleteDecryptionFailure(chat, sender types.JID, id types.MessageID) error {
+	_, err := s.db.Exec(deleteDecryptionFailureQuery, s.JID, chat.String(), sender.String(), id)
+	return err
+}
+
+func (s *SQLStore) GetAllDecryptionFailures() ([]types.DecryptionFailure, error) {
+	rows, err := s.db.Query(getAllDecryptionFailuresQuery, s.JID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var failures []types.DecryptionFailure
+	for rows.Next() {
+		var failure types.DecryptionFailure
+		var failedAt int64
+		err = rows.Scan(
+			&failure.ID, &failure.Chat, &failure.Sender, &failure.Cause, &failedAt,
+			&failure.Failures, &failure.RetryReceipts, &failure.PhoneRequested,
+		)
+		if err != nil {
+			return nil, err
+		}
+		failure.FailedAt = time.Unix(failedAt, 0)
+		failures = append(failures, failure)
+	}
+	return failures, rows.Err()
+}
+
+func (s *SQLStore) IncrementDecryptionStats(cause types.DecryptionFailureCause, delta types.DecryptionRecoveryStats) error {
+	_, err := s.db.Exec(incrementDecryptionStatsQuery, s.JID, string(cause), delta.Failed, delta.Recovered, delta.GaveUp)
+	return err
+}
+
+func (s *SQLStore) GetDecryptionStats() (map[types.DecryptionFailureCause]types.DecryptionRecoveryStats, error) {
+	rows, err := s.db.Query(getDecryptionStatsQuery, s.JID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	output := make(map[types.DecryptionFailureCause]types.DecryptionRecoveryStats)
+	for rows.Next() {
+		var cause types.DecryptionFailureCause
+		var stats types.DecryptionRecoveryStats
+		err = rows.Scan(&cause, &stats.Failed, &stats.Recovered, &stats.GaveUp)
+		if err != nil {
+			return nil, err
+		}
+		output[cause] = stats
+	}
+	return output, rows.Err()
+}
+
+const (
+	putMessageStatusQuery = `
+		INSERT INTO whatsmeow_message_statuses (our_jid, message_id, chat_jid, sent_at)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE message_id=message_id
+	`
+	putMessageRecipientQuery = `
+		INSERT INTO whatsmeow_message_recipients (our_jid, message_id, recipient_jid)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE recipient_jid=recipient_jid
+	`
+	putMessageDeliveredQuery = `
+		UPDATE whatsmeow_message_recipients SET delivered_at=?
+		WHERE our_jid=? AND message_id=? AND recipient_jid=? AND delivered_at IS NULL
+	`
+	putMessageReadQuery = `
+		UPDATE whatsmeow_message_recipients SET read_at=?, delivered_at=COALESCE(delivered_at, ?)
+		WHERE our_jid=? AND message_id=? AND recipient_jid=? AND read_at IS NULL
+	`
+	putMessagePlayedQuery = `
+		UPDATE whatsmeow_message_recipients SET played_at=?, read_at=COALESCE(read_at, ?), delivered_at=COALESCE(delivered_at, ?)
+		WHERE our_jid=? AND message_id=? AND recipient_jid=? AND played_at IS NULL
+	`
+	getMessageStatusQuery     = `SELECT chat_jid, sent_at FROM whatsmeow_message_statuses WHERE our_jid=? AND message_id=?`
+	getMessageRecipientsQuery = `
+		SELECT recipient_jid, delivered_at, read_at, played_at FROM whatsmeow_message_recipients
+		WHERE our_jid=? AND message_id=?
+	`
+	deleteMessageStatusesBeforeQuery = `DELETE FROM whatsmeow_message_statuses WHERE our_jid=? AND sent_at<?`
+)
+
+func (s *SQLStore) PutMessageRecipients(chat types.JID, id types.MessageID, recipients []types.JID, sentAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	_, err = tx.Exec(putMessageStatusQuery, s.JID, id, chat.ToNonAD().String(), sentAt.Unix())
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to insert message status: %w", err)
+	}
+	for _, recipient := range recipients {
+		_, err = tx.Exec(putMessageRecipientQuery, s.JID, id, recipient.ToNonAD().String())
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to insert message recipient %s: %w", recipient, err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLStore) PutMessageReceipt(id types.MessageID, recipient types.JID, receiptType types.ReceiptType, timestamp time.Time) (bool, error) {
+	var res sql.Result
+	var err error
+	ts, recipientStr := timestamp.Unix(), recipient.ToNonAD().String()
+	switch receiptType {
+	case types.ReceiptTypeDelivered:
+		res, err = s.db.Exec(putMessageDeliveredQuery, ts, s.JID, id, recipientStr)
+	case types.ReceiptTypeRead:
+		res, err = s.db.Exec(putMessageReadQuery, ts, ts, s.JID, id, recipientStr)
+	case types.ReceiptTypePlayed:
+		res, err = s.db.Exec(putMessagePlayedQuery, ts, ts, ts, s.JID, id, recipientStr)
+	default:
+		return false, fmt.Errorf("unsupported receipt type %q", receiptType)
+	}
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+func (s *SQLStore) GetMessageStatus(id types.MessageID) (*types.MessageStatus, error) {
+	status := types.MessageStatus{ID: id}
+	var sentAt int64
+	err := s.db.QueryRow(getMessageStatusQuery, s.JID, id).Scan(&status.Chat, &sentAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	status.SentAt = time.Unix(sentAt, 0)
+	rows, err := s.db.Query(getMessageRecipientsQuery, s.JID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var recipient types.MessageRecipientStatus
+		var deliveredAt, readAt, playedAt sql.NullInt64
+		err = rows.Scan(&recipient.User, &deliveredAt, &readAt, &playedAt)
+		if err != nil {
+			return nil, err
+		}
+		if deliveredAt.Valid {
+			recipient.DeliveredAt = time.Unix(deliveredAt.Int64, 0)
+		}
+		if readAt.Valid {
+			recipient.ReadAt = time.Unix(readAt.Int64, 0)
+		}
+		if playedAt.Valid {
+			recipient.PlayedAt = time.Unix(playedAt.Int64, 0)
+		}
+		status.Recipients = append(status.Recipients, recipient)
+	}
+	return &status, rows.Err()
+}
+
+func (s *SQLStore) DeleteMessageStatusesBefore(before time.Time) (int64, error) {
+	// Recipients are deleted by the foreign key cascade
+	res, err := s.db.Exec(deleteMessageStatusesBeforeQuery, s.JID, before.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+const (
+	// Multiple updates from the same user within a millisecond are collapsed into the latest one
+	putPresenceHistoryQuery = `
+		INSERT INTO whatsmeow_presence_history (our_jid, their_jid, timestamp, online, last_seen)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE online=VALUES(online), last_seen=VALUES(last_seen)
+	`
+	getPresenceHistoryQuery = `
+		SELECT timestamp, online, last_seen FROM whatsmeow_presence_history
+		WHERE our_jid=? AND their_jid=? AND timestamp>=? AND timestamp<=?
+		ORDER BY timestamp
+	`
+	deletePresenceHistoryBeforeQuery = `DELETE FROM whatsmeow_presence_history WHERE our_jid=? AND timestamp<?`
+)
+
+func (s *SQLStore) PutPresenceHistory(presence types.UserPresence) error {
+	var lastSeen sql.NullInt64
+	if !presence.LastSeen.IsZero() {
+		lastSeen = sql.NullInt64{Int64: presence.LastSeen.Unix(), Valid: true}
+	}
+	_, err := s.db.Exec(putPresenceHistoryQuery, s.JID, presence.JID.ToNonAD().String(), presence.UpdatedAt.UnixMilli(), presence.Online, lastSeen)
+	return err
+}
+
+func (s *SQLStore) GetPresenceHistory(user types.JID, since, until time.Time) ([]types.UserPresence, error) {
+	user = user.ToNonAD()
+	rows, err := s.db.Query(getPresenceHistoryQuery, s.JID, user.String(), since.UnixMilli(), until.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var history []types.UserPresence
+	for rows.Next() {
+		presence := types.UserPresence{JID: user}
+		var timestamp int64
+		var lastSeen sql.NullInt64
+		err = rows.Scan(&timestamp, &presence.Online, &lastSeen)
+		if err != nil {
+			return nil, err
+		}
+		presence.UpdatedAt = time.UnixMilli(timestamp)
+		if lastSeen.Valid {
+			presence.LastSeen = time.Unix(lastSeen.Int64, 0)
+		}
+		history = append(history, presence)
+	}
+	return history, rows.Err()
+}
+
+func (s *SQLStore) DeletePresenceHistoryBefore(before time.Time) (int64, error) {
+	res, err := s.db.Exec(deletePresenceHistoryBeforeQuery, s.JID, before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+const (
+	getDisappearingTimerTimestampQuery = `SELECT setting_timestamp FROM whatsmeow_disappearing_timers WHERE our_jid=? AND chat_jid=?`
+	putDisappearingTimerQuery          = `
+		INSERT INTO whatsmeow_disappearing_timers (our_jid, chat_jid, timer, setting_timestamp)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE timer=VALUES(timer), setting_timestamp=VALUES(setting_timestamp)
+	`
+	getDisappearingTimerQuery = `SELECT timer FROM whatsmeow_disappearing_timers WHERE our_jid=? AND chat_jid=?`
+	putExpiringMessageQuery   = `
+		INSERT INTO whatsmeow_expiring_messages (our_jid, chat_jid, sender_jid, message_id, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE expires_at=VALUES(expires_at)
+	`
+	getExpiredMessagesQuery = `
+		SELECT chat_jid, sender_jid, message_id, expires_at FROM whatsmeow_expiring_messages
+		WHERE our_jid=? AND expires_at<=?
+		ORDER BY expires_at
+		LIMIT ?
+	`
+	deleteExpiringMessageQuery = `DELETE FROM whatsmeow_expiring_messages WHERE our_jid=? AND chat_jid=? AND sender_jid=? AND message_id=?`
+)
+
+func (s *SQLStore) PutDisappearingTimer(chat types.JID, timer time.Duration, settingTimestamp time.Time) (bool, error) {
+	chatStr := chat.ToNonAD().String()
+	return s.putIfNewer(
+		getDisappearingTimerTimestampQuery, []any{s.JID, chatStr}, settingTimestamp.Unix(),
+		putDisappearingTimerQuery, s.JID, chatStr, int64(timer.Seconds()), settingTimestamp.Unix(),
+	)
+}
+
+func (s *SQLStore) GetDisappearingTimer(chat types.JID) (time.Duration, error) {
+	var timer int64
+	err := s.db.QueryRow(getDisappearingTimerQuery, s.JID, chat.ToNonAD().String()).Scan(&timer)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return time.Duration(timer) * time.Second, err
+}
+
+func (s *SQLStore) PutExpiringMessage(msg types.ExpiringMessage) error {
+	_, err := s.db.Exec(putExpiringMessageQuery, s.JID, msg.Chat.ToNonAD().String(), msg.Sender.ToNonAD().String(), msg.ID, msg.ExpiresAt.Unix())
+	return err
+}
+
+func (s *SQLStore) GetExpiredMessages(before time.Time, limit int) ([]types.ExpiringMessage, error) {
+	rows, err := s.db.Query(getExpiredMessagesQuery, s.JID, before.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var messages []types.ExpiringMessage
+	for rows.Next() {
+		var msg types.ExpiringMessage
+		var expiresAt int64
+		err = rows.Scan(&msg.Chat, &msg.Sender, &msg.ID, &expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		msg.ExpiresAt = time.Unix(expiresAt, 0)
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+func (s *SQLStore) DeleteExpiringMessage(chat, sender types.JID, id types.MessageID) error {
+	_, err := s.db.Exec(deleteExpiringMessageQuery, s.JID, chat.ToNonAD().String(), sender.ToNonAD().String(), id)
+	return err
+}
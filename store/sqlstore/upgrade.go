@@ -17,7 +17,7 @@ type upgradeFunc func(*sql.Tx, *Container) error
 //
 // This may be of use if you want to manage the database fully manually, but in most cases you
 // should just call Container.Upgrade to let the library handle everything.
-var Upgrades = [...]upgradeFunc{upgradeV1, upgradeV2, upgradeV3, upgradeV4, upgradeV5, upgradeV6, upgradeV7}
+var Upgrades = [...]upgradeFunc{upgradeV1, upgradeV2, upgradeV3, upgradeV4, upgradeV5, upgradeV6, upgradeV7, upgradeV8, upgradeV9, upgradeV10, upgradeV11, upgradeV12, upgradeV13, upgradeV14, upgradeV15, upgradeV16}
 
 func (c *Container) getVersion() (int, error) {
 	_, err := c.db.Exec("CREATE TABLE IF NOT EXISTS whatsmeow_version (version INT)")
@@ -543,3 +543,432 @@ func upgradeV7(tx *sql.Tx, container *Container) error {
 	_, err := tx.Exec(alterTableSQL)
 	return err
 }
+
+func upgradeV8(tx *sql.Tx, container *Container) error {
+	var createTableSQL, createIndexSQL string
+	if container.dialect == "mysql" {
+		createTableSQL = `CREATE TABLE whatsmeow_received_statuses (
+			our_jid VARCHAR(255),
+			sender_jid VARCHAR(255),
+			status_id VARCHAR(255),
+			push_name TEXT NOT NULL,
+			timestamp BIGINT NOT NULL,
+			message MEDIUMBLOB NOT NULL,
+			PRIMARY KEY (our_jid, sender_jid, status_id),
+			INDEX (our_jid, timestamp),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	} else {
+		createTableSQL = `CREATE TABLE whatsmeow_received_statuses (
+			our_jid TEXT,
+			sender_jid TEXT,
+			status_id TEXT,
+			push_name TEXT NOT NULL,
+			timestamp BIGINT NOT NULL,
+			message bytea NOT NULL,
+			PRIMARY KEY (our_jid, sender_jid, status_id),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createIndexSQL = `CREATE INDEX whatsmeow_received_statuses_timestamp ON whatsmeow_received_statuses (our_jid, timestamp)`
+	}
+	_, err := tx.Exec(createTableSQL)
+	if err != nil || createIndexSQL == "" {
+		return err
+	}
+	_, err = tx.Exec(createIndexSQL)
+	return err
+}
+
+func upgradeV9(tx *sql.Tx, container *Container) error {
+	var createListsSQL, createRecipientsSQL string
+	if container.dialect == "mysql" {
+		createListsSQL = `CREATE TABLE whatsmeow_broadcast_lists (
+			our_jid VARCHAR(255),
+			list_jid VARCHAR(255),
+			name VARCHAR(255) NOT NULL,
+			created_at BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, list_jid),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createRecipientsSQL = `CREATE TABLE whatsmeow_broadcast_list_recipients (
+			our_jid VARCHAR(255),
+			list_jid VARCHAR(255),
+			recipient_jid VARCHAR(255),
+			PRIMARY KEY (our_jid, list_jid, recipient_jid),
+			FOREIGN KEY (our_jid, list_jid) REFERENCES whatsmeow_broadcast_lists(our_jid, list_jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	} else {
+		createListsSQL = `CREATE TABLE whatsmeow_broadcast_lists (
+			our_jid TEXT,
+			list_jid TEXT,
+			name TEXT NOT NULL,
+			created_at BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, list_jid),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createRecipientsSQL = `CREATE TABLE whatsmeow_broadcast_list_recipients (
+			our_jid TEXT,
+			list_jid TEXT,
+			recipient_jid TEXT,
+			PRIMARY KEY (our_jid, list_jid, recipient_jid),
+			FOREIGN KEY (our_jid, list_jid) REFERENCES whatsmeow_broadcast_lists(our_jid, list_jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	}
+	_, err := tx.Exec(createListsSQL)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(createRecipientsSQL)
+	return err
+}
+
+func upgradeV10(tx *sql.Tx, container *Container) error {
+	var createTableSQL, createIndexSQL string
+	if container.dialect == "mysql" {
+		createTableSQL = `CREATE TABLE whatsmeow_scheduled_messages (
+			our_jid VARCHAR(255),
+			message_id VARCHAR(255),
+			chat_jid VARCHAR(255) NOT NULL,
+			message MEDIUMBLOB NOT NULL,
+			send_at BIGINT NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			PRIMARY KEY (our_jid, message_id),
+			INDEX (our_jid, send_at),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	} else {
+		createTableSQL = `CREATE TABLE whatsmeow_scheduled_messages (
+			our_jid TEXT,
+			message_id TEXT,
+			chat_jid TEXT NOT NULL,
+			message bytea NOT NULL,
+			send_at BIGINT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (our_jid, message_id),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createIndexSQL = `CREATE INDEX whatsmeow_scheduled_messages_send_at ON whatsmeow_scheduled_messages (our_jid, send_at)`
+	}
+	_, err := tx.Exec(createTableSQL)
+	if err != nil || createIndexSQL == "" {
+		return err
+	}
+	_, err = tx.Exec(createIndexSQL)
+	return err
+}
+
+func upgradeV11(tx *sql.Tx, container *Container) error {
+	var createPollsSQL, createVotesSQL string
+	if container.dialect == "mysql" {
+		createPollsSQL = `CREATE TABLE whatsmeow_polls (
+			our_jid VARCHAR(100),
+			chat_jid VARCHAR(100),
+			sender_jid VARCHAR(100),
+			poll_id VARCHAR(100),
+			name TEXT NOT NULL,
+			options MEDIUMTEXT NOT NULL,
+			selectable_count INT NOT NULL,
+			created_at BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, poll_id),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createVotesSQL = `CREATE TABLE whatsmeow_poll_votes (
+			our_jid VARCHAR(100),
+			chat_jid VARCHAR(100),
+			sender_jid VARCHAR(100),
+			poll_id VARCHAR(100),
+			voter_jid VARCHAR(100),
+			selected_options BLOB NOT NULL,
+			timestamp BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, poll_id, voter_jid),
+			FOREIGN KEY (our_jid, chat_jid, sender_jid, poll_id) REFERENCES whatsmeow_polls(our_jid, chat_jid, sender_jid, poll_id) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	} else {
+		createPollsSQL = `CREATE TABLE whatsmeow_polls (
+			our_jid TEXT,
+			chat_jid TEXT,
+			sender_jid TEXT,
+			poll_id TEXT,
+			name TEXT NOT NULL,
+			options TEXT NOT NULL,
+			selectable_count INTEGER NOT NULL,
+			created_at BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, poll_id),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createVotesSQL = `CREATE TABLE whatsmeow_poll_votes (
+			our_jid TEXT,
+			chat_jid TEXT,
+			sender_jid TEXT,
+			poll_id TEXT,
+			voter_jid TEXT,
+			selected_options bytea NOT NULL,
+			timestamp BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, poll_id, voter_jid),
+			FOREIGN KEY (our_jid, chat_jid, sender_jid, poll_id) REFERENCES whatsmeow_polls(our_jid, chat_jid, sender_jid, poll_id) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	}
+	_, err := tx.Exec(createPollsSQL)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(createVotesSQL)
+	return err
+}
+
+func upgradeV12(tx *sql.Tx, container *Container) error {
+	var createStatesSQL, createReactionsSQL string
+	if container.dialect == "mysql" {
+		createStatesSQL = `CREATE TABLE whatsmeow_message_states (
+			our_jid VARCHAR(100),
+			chat_jid VARCHAR(100),
+			sender_jid VARCHAR(100),
+			message_id VARCHAR(100),
+			edited_text MEDIUMTEXT,
+			edited_at BIGINT,
+			revoked BOOLEAN NOT NULL DEFAULT false,
+			deleted_for_me BOOLEAN NOT NULL DEFAULT false,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, message_id),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createReactionsSQL = `CREATE TABLE whatsmeow_message_reactions (
+			our_jid VARCHAR(100),
+			chat_jid VARCHAR(100),
+			sender_jid VARCHAR(100),
+			message_id VARCHAR(100),
+			reactor_jid VARCHAR(100),
+			emoji VARCHAR(64) NOT NULL,
+			timestamp BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, message_id, reactor_jid),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	} else {
+		createStatesSQL = `CREATE TABLE whatsmeow_message_states (
+			our_jid TEXT,
+			chat_jid TEXT,
+			sender_jid TEXT,
+			message_id TEXT,
+			edited_text TEXT,
+			edited_at BIGINT,
+			revoked BOOLEAN NOT NULL DEFAULT false,
+			deleted_for_me BOOLEAN NOT NULL DEFAULT false,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, message_id),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createReactionsSQL = `CREATE TABLE whatsmeow_message_reactions (
+			our_jid TEXT,
+			chat_jid TEXT,
+			sender_jid TEXT,
+			message_id TEXT,
+			reactor_jid TEXT,
+			emoji TEXT NOT NULL,
+			timestamp BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, message_id, reactor_jid),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	}
+	_, err := tx.Exec(createStatesSQL)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(createReactionsSQL)
+	return err
+}
+
+func upgradeV13(tx *sql.Tx, container *Container) error {
+	var createFailuresSQL, createStatsSQL string
+	if container.dialect == "mysql" {
+		createFailuresSQL = `CREATE TABLE whatsmeow_decryption_failures (
+			our_jid VARCHAR(100),
+			chat_jid VARCHAR(100),
+			sender_jid VARCHAR(100),
+			message_id VARCHAR(100),
+			cause VARCHAR(64) NOT NULL,
+			failed_at BIGINT NOT NULL,
+			failures INT NOT NULL,
+			retry_receipts INT NOT NULL,
+			phone_requested BOOLEAN NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, message_id),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createStatsSQL = `CREATE TABLE whatsmeow_decryption_stats (
+			our_jid VARCHAR(255),
+			cause VARCHAR(64),
+			failed INT NOT NULL,
+			recovered INT NOT NULL,
+			gave_up INT NOT NULL,
+			PRIMARY KEY (our_jid, cause),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	} else {
+		createFailuresSQL = `CREATE TABLE whatsmeow_decryption_failures (
+			our_jid TEXT,
+			chat_jid TEXT,
+			sender_jid TEXT,
+			message_id TEXT,
+			cause TEXT NOT NULL,
+			failed_at BIGINT NOT NULL,
+			failures INTEGER NOT NULL,
+			retry_receipts INTEGER NOT NULL,
+			phone_requested BOOLEAN NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, message_id),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createStatsSQL = `CREATE TABLE whatsmeow_decryption_stats (
+			our_jid TEXT,
+			cause TEXT,
+			failed INTEGER NOT NULL,
+			recovered INTEGER NOT NULL,
+			gave_up INTEGER NOT NULL,
+			PRIMARY KEY (our_jid, cause),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	}
+	_, err := tx.Exec(createFailuresSQL)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(createStatsSQL)
+	return err
+}
+
+func upgradeV14(tx *sql.Tx, container *Container) error {
+	var createStatusesSQL, createRecipientsSQL, createIndexSQL string
+	if container.dialect == "mysql" {
+		createStatusesSQL = `CREATE TABLE whatsmeow_message_statuses (
+			our_jid VARCHAR(100),
+			message_id VARCHAR(100),
+			chat_jid VARCHAR(255) NOT NULL,
+			sent_at BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, message_id),
+			INDEX (our_jid, sent_at),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createRecipientsSQL = `CREATE TABLE whatsmeow_message_recipients (
+			our_jid VARCHAR(100),
+			message_id VARCHAR(100),
+			recipient_jid VARCHAR(100),
+			delivered_at BIGINT,
+			read_at BIGINT,
+			played_at BIGINT,
+			PRIMARY KEY (our_jid, message_id, recipient_jid),
+			FOREIGN KEY (our_jid, message_id) REFERENCES whatsmeow_message_statuses(our_jid, message_id) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	} else {
+		createStatusesSQL = `CREATE TABLE whatsmeow_message_statuses (
+			our_jid TEXT,
+			message_id TEXT,
+			chat_jid TEXT NOT NULL,
+			sent_at BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, message_id),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createRecipientsSQL = `CREATE TABLE whatsmeow_message_recipients (
+			our_jid TEXT,
+			message_id TEXT,
+			recipient_jid TEXT,
+			delivered_at BIGINT,
+			read_at BIGINT,
+			played_at BIGINT,
+			PRIMARY KEY (our_jid, message_id, recipient_jid),
+			FOREIGN KEY (our_jid, message_id) REFERENCES whatsmeow_message_statuses(our_jid, message_id) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createIndexSQL = `CREATE INDEX whatsmeow_message_statuses_sent_at ON whatsmeow_message_statuses (our_jid, sent_at)`
+	}
+	_, err := tx.Exec(createStatusesSQL)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(createRecipientsSQL)
+	if err != nil || createIndexSQL == "" {
+		return err
+	}
+	_, err = tx.Exec(createIndexSQL)
+	return err
+}
+
+func upgradeV15(tx *sql.Tx, container *Container) error {
+	var createTableSQL, createIndexSQL string
+	if container.dialect == "mysql" {
+		createTableSQL = `CREATE TABLE whatsmeow_presence_history (
+			our_jid VARCHAR(255),
+			their_jid VARCHAR(255),
+			timestamp BIGINT,
+			online BOOLEAN NOT NULL,
+			last_seen BIGINT,
+			PRIMARY KEY (our_jid, their_jid, timestamp),
+			INDEX (our_jid, timestamp),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	} else {
+		createTableSQL = `CREATE TABLE whatsmeow_presence_history (
+			our_jid TEXT,
+			their_jid TEXT,
+			timestamp BIGINT,
+			online BOOLEAN NOT NULL,
+			last_seen BIGINT,
+			PRIMARY KEY (our_jid, their_jid, timestamp),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createIndexSQL = `CREATE INDEX whatsmeow_presence_history_timestamp ON whatsmeow_presence_history (our_jid, timestamp)`
+	}
+	_, err := tx.Exec(createTableSQL)
+	if err != nil || createIndexSQL == "" {
+		return err
+	}
+	_, err = tx.Exec(createIndexSQL)
+	return err
+}
+
+func upgradeV16(tx *sql.Tx, container *Container) error {
+	var createTimersSQL, createExpiringSQL, createIndexSQL string
+	if container.dialect == "mysql" {
+		createTimersSQL = `CREATE TABLE whatsmeow_disappearing_timers (
+			our_jid VARCHAR(255),
+			chat_jid VARCHAR(255),
+			timer BIGINT NOT NULL,
+			setting_timestamp BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createExpiringSQL = `CREATE TABLE whatsmeow_expiring_messages (
+			our_jid VARCHAR(100),
+			chat_jid VARCHAR(100),
+			sender_jid VARCHAR(100),
+			message_id VARCHAR(100),
+			expires_at BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, message_id),
+			INDEX (our_jid, expires_at),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+	} else {
+		createTimersSQL = `CREATE TABLE whatsmeow_disappearing_timers (
+			our_jid TEXT,
+			chat_jid TEXT,
+			timer BIGINT NOT NULL,
+			setting_timestamp BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createExpiringSQL = `CREATE TABLE whatsmeow_expiring_messages (
+			our_jid TEXT,
+			chat_jid TEXT,
+			sender_jid TEXT,
+			message_id TEXT,
+			expires_at BIGINT NOT NULL,
+			PRIMARY KEY (our_jid, chat_jid, sender_jid, message_id),
+			FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+		)`
+		createIndexSQL = `CREATE INDEX whatsmeow_expiring_messages_expires_at ON whatsmeow_expiring_messages (our_jid, expires_at)`
+	}
+	_, err := tx.Exec(createTimersSQL)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(createExpiringSQL)
+	if err != nil || createIndexSQL == "" {
+		return err
+	}
+	_, err = tx.Exec(createIndexSQL)
+	return err
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/pbribeiro/whatsmeow-mysql/proto/waAdv"
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
 	"github.com/pbribeiro/whatsmeow-mysql/types"
 	"github.com/pbribeiro/whatsmeow-mysql/util/keys"
 	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
@@ -126,6 +127,132 @@ type PrivacyTokenStore interface {
 	GetPrivacyToken(user types.JID) (*PrivacyToken, error)
 }
 
+type ReceivedStatus struct {
+	Sender    types.JID
+	ID        types.MessageID
+	PushName  string
+	Timestamp time.Time
+	Message   *waE2E.Message
+}
+
+type StatusStore interface {
+	PutReceivedStatus(status ReceivedStatus) error
+	DeleteReceivedStatus(sender types.JID, id types.MessageID) error
+	// GetReceivedStatuses returns the statuses posted after the given time, oldest first.
+	// If sender is empty, statuses from all users are returned.
+	GetReceivedStatuses(sender types.JID, since time.Time) ([]ReceivedStatus, error)
+	// DeleteReceivedStatusesBefore deletes the statuses of all users that were posted before the given time.
+	DeleteReceivedStatusesBefore(before time.Time) (deleted int64, err error)
+}
+
+type BroadcastList struct {
+	JID        types.JID
+	Name       string
+	Recipients []types.JID
+	CreatedAt  time.Time
+}
+
+type BroadcastListStore interface {
+	PutBroadcastList(list BroadcastList) error
+	RenameBroadcastList(list types.JID, name string) error
+	DeleteBroadcastList(list types.JID) error
+	AddBroadcastListRecipients(list types.JID, recipients []types.JID) error
+	RemoveBroadcastListRecipients(list types.JID, recipients []types.JID) error
+	GetBroadcastList(list types.JID) (*BroadcastList, error)
+	GetAllBroadcastLists() ([]BroadcastList, error)
+}
+
+type ScheduledMessage struct {
+	ID       types.MessageID
+	Chat     types.JID
+	Message  *waE2E.Message
+	SendAt   time.Time
+	Attempts int
+}
+
+type ScheduledMessageStore interface {
+	PutScheduledMessage(msg ScheduledMessage) error
+	DeleteScheduledMessage(id types.MessageID) error
+	GetScheduledMessage(id types.MessageID) (*ScheduledMessage, error)
+	GetScheduledMessagesBefore(until time.Time) ([]ScheduledMessage, error)
+	GetNextScheduledMessage() (*ScheduledMessage, error)
+	GetAllScheduledMessages() ([]ScheduledMessage, error)
+}
+
+type Poll struct {
+	Chat            types.JID
+	Sender          types.JID
+	ID              types.MessageID
+	Name            string
+	Options         []string
+	SelectableCount int
+	CreatedAt       time.Time
+}
+
+type PollVote struct {
+	Voter           types.JID
+	SelectedOptions [][]byte
+	Timestamp       time.Time
+}
+
+type PollStore interface {
+	PutPoll(poll Poll) error
+	GetPoll(chat, sender types.JID, id types.MessageID) (*Poll, error)
+	// PutPollVote stores the vote if it's newer than the voter's previous vote in the same poll.
+	PutPollVote(chat, sender types.JID, id types.MessageID, vote PollVote) (updated bool, err error)
+	GetPollVotes(chat, sender types.JID, id types.MessageID) ([]PollVote, error)
+}
+
+type MessageStateStore interface {
+	// PutMessageEdit stores the edited text if it's newer than the previous edit of the same message.
+	PutMessageEdit(chat, sender types.JID, id types.MessageID, text string, timestamp time.Time) (updated bool, err error)
+	// PutMessageReaction stores the reaction if it's newer than the reactor's previous reaction to the same message.
+	// Reactions with an empty emoji are stored as removals and aren't returned by GetMessageState.
+	PutMessageReaction(chat, sender types.JID, id types.MessageID, reaction types.MessageReaction) (updated bool, err error)
+	PutMessageRevoked(chat, sender types.JID, id types.MessageID) error
+	PutMessageDeletedForMe(chat, sender types.JID, id types.MessageID) error
+	GetMessageState(chat, sender types.JID, id types.MessageID) (*types.MessageState, error)
+}
+
+type DecryptionFailureStore interface {
+	PutDecryptionFailure(failure types.DecryptionFailure) error
+	DeleteDecryptionFailure(chat, sender types.JID, id types.MessageID) error
+	GetAllDecryptionFailures() ([]types.DecryptionFailure, error)
+	IncrementDecryptionStats(cause types.DecryptionFailureCause, delta types.DecryptionRecoveryStats) error
+	GetDecryptionStats() (map[types.DecryptionFailureCause]types.DecryptionRecoveryStats, error)
+}
+
+type MessageStatusStore interface {
+	// PutMessageRecipients stores the recipients of a sent message. Recipients that are already stored are left unchanged.
+	PutMessageRecipients(chat types.JID, id types.MessageID, recipients []types.JID, sentAt time.Time) error
+	// PutMessageReceipt marks the message as delivered, read or played by the given recipient if it wasn't already.
+	// Read and played receipts also fill in the earlier states if they're missing.
+	PutMessageReceipt(id types.MessageID, recipient types.JID, receiptType types.ReceiptType, timestamp time.Time) (updated bool, err error)
+	// GetMessageStatus returns the receipts of a sent message, or nil if the message isn't tracked.
+	GetMessageStatus(id types.MessageID) (*types.MessageStatus, error)
+	// DeleteMessageStatusesBefore deletes the recipients and receipts of messages sent before the given time.
+	DeleteMessageStatusesBefore(before time.Time) (deleted int64, err error)
+}
+
+type PresenceHistoryStore interface {
+	PutPresenceHistory(presence types.UserPresence) error
+	// GetPresenceHistory returns the stored presence updates of the given user between since and until, oldest first.
+	GetPresenceHistory(user types.JID, since, until time.Time) ([]types.UserPresence, error)
+	// DeletePresenceHistoryBefore deletes the presence updates of all users that were received before the given time.
+	DeletePresenceHistoryBefore(before time.Time) (deleted int64, err error)
+}
+
+type DisappearingMessageStore interface {
+	// PutDisappearingTimer stores the disappearing message timer of a chat if the setting is newer than the stored one.
+	PutDisappearingTimer(chat types.JID, timer time.Duration, settingTimestamp time.Time) (updated bool, err error)
+	// GetDisappearingTimer returns the disappearing message timer of a chat, or zero if it's not known or disabled.
+	GetDisappearingTimer(chat types.JID) (time.Duration, error)
+	PutExpiringMessage(msg types.ExpiringMessage) error
+	// GetExpiredMessages returns up to limit messages that expired before the given time, oldest first.
+	GetExpiredMessages(before time.Time, limit int) ([]types.ExpiringMessage, error)
+	DeleteExpiringMessage(chat, sender types.JID, id types.MessageID) error
+}
+
 type AllStores interface {
 	IdentityStore
 	SessionStore
@@ -137,6 +264,15 @@ type AllStores interface {
 	ChatSettingsStore
 	MsgSecretStore
 	PrivacyTokenStore
+	StatusStore
+	BroadcastListStore
+	ScheduledMessageStore
+	PollStore
+	MessageStateStore
+	DecryptionFailureStore
+	MessageStatusStore
+	PresenceHistoryStore
+	DisappearingMessageStore
 }
 
 type Device struct {
@@ -157,18 +293,27 @@ type Device struct {
 
 	FacebookUUID uuid.UUID
 
-	Initialized   bool
-	Identities    IdentityStore
-	Sessions      SessionStore
-	PreKeys       PreKeyStore
-	SenderKeys    SenderKeyStore
-	AppStateKeys  AppStateSyncKeyStore
-	AppState      AppStateStore
-	Contacts      ContactStore
-	ChatSettings  ChatSettingsStore
-	MsgSecrets    MsgSecretStore
-	PrivacyTokens PrivacyTokenStore
-	Container     DeviceContainer
+	Initialized          bool
+	Identities           IdentityStore
+	Sessions             SessionStore
+	PreKeys              PreKeyStore
+	SenderKeys           SenderKeyStore
+	AppStateKeys         AppStateSyncKeyStore
+	AppState             AppStateStore
+	Contacts             ContactStore
+	ChatSettings         ChatSettingsStore
+	MsgSecrets           MsgSecretStore
+	PrivacyTokens        PrivacyTokenStore
+	Statuses             StatusStore
+	BroadcastLists       BroadcastListStore
+	ScheduledMessages    ScheduledMessageStore
+	Polls                PollStore
+	MessageStates        MessageStateStore
+	DecryptionFailures   DecryptionFailureStore
+	MessageStatuses      MessageStatusStore
+	PresenceHistory      PresenceHistoryStore
+	DisappearingMessages DisappearingMessageStore
+	Container            DeviceContainer
 
 	DatabaseErrorHandler func(device *Device, action string, attemptIndex int, err error) (retry bool)
 }
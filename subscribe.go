@@ -0,0 +1,202 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+// SubscribeOption is an option that can be passed to Subscribe. Both EventFilters and WithBuffer are options.
+type SubscribeOption interface {
+	applySubscribeOption(opts *subscribeOptions)
+}
+
+type subscribeOptions struct {
+	filters    []EventFilter
+	bufferSize int
+}
+
+// EventFilter is a function that decides whether an event should be passed to a subscription.
+//
+// Filters receive the raw event, so the same filter can be used for different event types.
+// Events that don't have the information a filter looks at (e.g. a chat filter on an event without a chat) are rejected.
+type EventFilter func(evt any) bool
+
+func (ef EventFilter) applySubscribeOption(opts *subscribeOptions) {
+	opts.filters = append(opts.filters, ef)
+}
+
+type bufferOption int
+
+func (bo bufferOption) applySubscribeOption(opts *subscribeOptions) {
+	opts.bufferSize = int(bo)
+}
+
+// WithBuffer makes the subscription deliver events through a buffered channel that's consumed in a separate goroutine.
+//
+// This means a slow handler will not block other event handlers. If the buffer is full,
+// new events for the subscription are dropped (and counted in Subscription.Dropped) instead of blocking.
+func WithBuffer(size int) SubscribeOption {
+	return bufferOption(size)
+}
+
+// GetEventMessageSource returns the chat and sender info of an event, if the event has such info.
+func GetEventMessageSource(evt any) (*types.MessageSource, bool) {
+	switch typedEvt := evt.(type) {
+	case *events.Message:
+		return &typedEvt.Info.MessageSource, true
+	case *events.UndecryptableMessage:
+		return &typedEvt.Info.MessageSource, true
+	case *events.Receipt:
+		return &typedEvt.MessageSource, true
+	case *events.ChatPresence:
+		return &typedEvt.MessageSource, true
+	case *events.FBMessage:
+		return &typedEvt.Info.MessageSource, true
+	default:
+		return nil, false
+	}
+}
+
+// FilterChat only allows events that happened in one of the given chats.
+func FilterChat(chats ...types.JID) EventFilter {
+	return func(evt any) bool {
+		source, ok := GetEventMessageSource(evt)
+		return ok && slices.Contains(chats, source.Chat.ToNonAD())
+	}
+}
+
+// FilterSender only allows events that were caused by one of the given users.
+//
+// The device part of the sender is ignored when comparing.
+func FilterSender(senders ...types.JID) EventFilter {
+	return func(evt any) bool {
+		source, ok := GetEventMessageSource(evt)
+		return ok && slices.Contains(senders, source.Sender.ToNonAD())
+	}
+}
+
+// FilterFromMe only allows events whose IsFromMe flag matches the given value.
+func FilterFromMe(fromMe bool) EventFilter {
+	return func(evt any) bool {
+		source, ok := GetEventMessageSource(evt)
+		return ok && source.IsFromMe == fromMe
+	}
+}
+
+// FilterMessageType only allows message events of the given types.
+//
+// The types can either be stanza types (text, media, reaction, poll)
+// or media types (image, video, audio, ptt, document, sticker, etc).
+func FilterMessageType(msgTypes ...string) EventFilter {
+	return func(evt any) bool {
+		var msgType, mediaType string
+		switch typedEvt := evt.(type) {
+		case *events.Message:
+			msgType = typedEvt.Info.Type
+			if msgType == "" && typedEvt.Message != nil {
+				msgType = getTypeFromMessage(typedEvt.Message)
+			}
+			mediaType = typedEvt.Info.MediaType
+			if mediaType == "" && typedEvt.Message != nil {
+				mediaType = getMediaTypeFromMessage(typedEvt.Message)
+			}
+		case *events.UndecryptableMessage:
+			msgType = typedEvt.Info.Type
+			mediaType = typedEvt.Info.MediaType
+		default:
+			return false
+		}
+		return slices.Contains(msgTypes, msgType) || (mediaType != "" && slices.Contains(msgTypes, mediaType))
+	}
+}
+
+// Subscription is a typed event handler registered with Subscribe.
+type Subscription struct {
+	cli       *Client
+	handlerID uint32
+	queue     chan any
+	closeOnce sync.Once
+	dropped   atomic.Uint64
+}
+
+// Dropped returns the number of events that were dropped because the subscription's buffer was full.
+func (sub *Subscription) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
+// Unsubscribe removes the subscription. Events that are already buffered will still be delivered.
+//
+// Like RemoveEventHandler, this must not be called directly from an unbuffered handler, as that would deadlock.
+func (sub *Subscription) Unsubscribe() {
+	sub.closeOnce.Do(func() {
+		sub.cli.RemoveEventHandler(sub.handlerID)
+		if sub.queue != nil {
+			close(sub.queue)
+		}
+	})
+}
+
+// Subscribe registers a handler that only receives events of type T which pass all the given filters.
+//
+//	sub := whatsmeow.Subscribe(cli, func(evt *events.Message) {
+//		fmt.Println("Received a message in the group!", evt.Message.GetConversation())
+//	}, whatsmeow.FilterChat(groupJID), whatsmeow.FilterFromMe(false), whatsmeow.WithBuffer(100))
+//	defer sub.Unsubscribe()
+//
+// Panics in the handler are recovered and logged, so they won't prevent other handlers from receiving the event.
+func Subscribe[T any](cli *Client, fn func(T), opts ...SubscribeOption) *Subscription {
+	var options subscribeOptions
+	for _, opt := range opts {
+		opt.applySubscribeOption(&options)
+	}
+	sub := &Subscription{cli: cli}
+	call := func(evt T) {
+		defer func() {
+			if err := recover(); err != nil {
+				cli.Log.Errorf("Subscription handler panicked while handling a %T: %v\n%s", evt, err, debug.Stack())
+			}
+		}()
+		fn(evt)
+	}
+	if options.bufferSize > 0 {
+		sub.queue = make(chan any, options.bufferSize)
+		go func() {
+			for evt := range sub.queue {
+				call(evt.(T))
+			}
+		}()
+	}
+	sub.handlerID = cli.AddEventHandler(func(rawEvt any) {
+		evt, ok := rawEvt.(T)
+		if !ok {
+			return
+		}
+		for _, filter := range options.filters {
+			if !filter(rawEvt) {
+				return
+			}
+		}
+		if sub.queue == nil {
+			call(evt)
+			return
+		}
+		select {
+		case sub.queue <- evt:
+		default:
+			sub.dropped.Add(1)
+			cli.Log.Warnf("Subscription buffer for %T is full, dropping event", evt)
+		}
+	})
+	return sub
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
+)
+
+// TrafficDirection specifies whether a recorded node was received from or sent to the server.
+type TrafficDirection string
+
+const (
+	TrafficIncoming TrafficDirection = "in"
+	TrafficOutgoing TrafficDirection = "out"
+)
+
+// RecordedNode is a single entry in a traffic recording.
+type RecordedNode struct {
+	Time      time.Time        `json:"time"`
+	Direction TrafficDirection `json:"direction"`
+	// The decrypted frame, i.e. the node encoded in WhatsApp's binary XML format with the compression flag byte prefix.
+	Data []byte `json:"data"`
+	// A human-readable version of the node. Only present if TrafficRecorderOptions.IncludeXML was set.
+	XML string `json:"xml,omitempty"`
+}
+
+// Node decodes the binary XML data in the recorded entry.
+func (rn *RecordedNode) Node() (*waBinary.Node, error) {
+	if len(rn.Data) == 0 {
+		return nil, fmt.Errorf("entry doesn't contain any data")
+	}
+	unpacked, err := waBinary.Unpack(rn.Data)
+	if err != nil {
+		return nil, err
+	}
+	return waBinary.Unmarshal(unpacked)
+}
+
+// TrafficRecorderOptions contains options for NewTrafficRecorder.
+type TrafficRecorderOptions struct {
+	// If true, the content of encrypted payloads (message ciphertexts, encrypted media retry data, etc) is zeroed out.
+	RedactCiphertext bool
+	// If true, the content of key material (identity keys, prekeys, signatures, etc) is zeroed out.
+	RedactKeys bool
+	// If true, a human-readable XML version of each node is included in addition to the binary data.
+	IncludeXML bool
+}
+
+var ciphertextTags = map[string]struct{}{
+	"enc":       {},
+	"enc_p":     {},
+	"enc_iv":    {},
+	"plaintext": {},
+}
+
+// keyMaterialTags contains the tags whose content is redacted with RedactKeys.
+// Generic tags like value and signature are only redacted inside prekey nodes, which is written as parent/child.
+var keyMaterialTags = map[string]struct{}{
+	"identity":        {},
+	"registration":    {},
+	"key/value":       {},
+	"skey/value":      {},
+	"skey/signature":  {},
+	"device-identity": {},
+	"adv-secret":      {},
+	"link_code_pairing_wrapped_companion_ephemeral_pub": {},
+	"link_code_pairing_wrapped_key_bundle":              {},
+	"companion_server_auth_key_pub":                     {},
+	"companion_platform_id":                             {},
+	"companion_platform_display":                        {},
+	"primary_identity_pub":                              {},
+}
+
+// TrafficRecorder writes decrypted nodes sent and received by a Client into a JSON lines file.
+//
+// Recordings can be fed back into a client with Client.ReplayTraffic, which makes it possible to reproduce
+// exact server sequences in tests and bug reports.
+type TrafficRecorder struct {
+	opts       TrafficRecorderOptions
+	redactTags map[string]struct{}
+
+	lock sync.Mutex
+	enc  *json.Encoder
+}
+
+// NewTrafficRecorder creates a new recorder that writes to the given writer.
+//
+//	file, err := os.Create("traffic.jsonl")
+//	if err != nil {
+//		panic(err)
+//	}
+//	cli.SetTrafficRecorder(whatsmeow.NewTrafficRecorder(file, whatsmeow.TrafficRecorderOptions{RedactKeys: true}))
+func NewTrafficRecorder(w io.Writer, opts TrafficRecorderOptions) *TrafficRecorder {
+	redactTags := make(map[string]struct{})
+	if opts.RedactCiphertext {
+		for tag := range ciphertextTags {
+			redactTags[tag] = struct{}{}
+		}
+	}
+	if opts.RedactKeys {
+		for tag := range keyMaterialTags {
+			redactTags[tag] = struct{}{}
+		}
+	}
+	return &TrafficRecorder{
+		opts:       opts,
+		redactTags: redactTags,
+		enc:        json.NewEncoder(w),
+	}
+}
+
+func redactNode(node waBinary.Node, parent string, tags map[string]struct{}) waBinary.Node {
+	switch content := node.Content.(type) {
+	case []byte:
+		_, redact := tags[node.Tag]
+		if !redact {
+			_, redact = tags[parent+"/"+node.Tag]
+		}
+		if redact {
+			node.Content = make([]byte, len(content))
+		}
+	case []waBinary.Node:
+		children := make([]waBinary.Node, len(content))
+		for i, child := range content {
+			children[i] = redactNode(child, node.Tag, tags)
+		}
+		node.Content = children
+	}
+	return node
+}
+
+func (tr *TrafficRecorder) record(dir TrafficDirection, node *waBinary.Node, data []byte) error {
+	entry := RecordedNode{
+		Time:      time.Now(),
+		Direction: dir,
+		Data:      data,
+	}
+	if len(tr.redactTags) > 0 {
+		redacted := redactNode(*node, "", tr.redactTags)
+		node = &redacted
+		var err error
+		entry.Data, err = waBinary.Marshal(redacted)
+		if err != nil {
+			return fmt.Errorf("failed to marshal node: %w", err)
+		}
+	}
+	if tr.opts.IncludeXML {
+		entry.XML = node.XMLString()
+	}
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	return tr.enc.Encode(&entry)
+}
+
+// SetTrafficRecorder sets the recorder that all incoming and outgoing nodes are written to.
+// Set to nil to stop recording.
+func (cli *Client) SetTrafficRecorder(rec *TrafficRecorder) {
+	cli.trafficRecorder.Store(rec)
+}
+
+func (cli *Client) recordTraffic(dir TrafficDirection, node *waBinary.Node, data []byte) {
+	rec := cli.trafficRecorder.Load()
+	if rec == nil {
+		return
+	}
+	err := rec.record(dir, node, data)
+	if err != nil {
+		cli.Log.Warnf("Failed to record %s node %s: %v", dir, node.Tag, err)
+	}
+}
+
+// ReadTrafficRecording reads all entries from a recording made with a TrafficRecorder.
+func ReadTrafficRecording(r io.Reader) ([]RecordedNode, error) {
+	var entries []RecordedNode
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var entry RecordedNode
+		err := dec.Decode(&entry)
+		if err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return entries, fmt.Errorf("failed to decode entry #%d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// ReplayOptions contains options for Client.ReplayTraffic.
+type ReplayOptions struct {
+	// If true, the delays between incoming nodes in the recording are reproduced. By default, nodes are replayed as fast as possible.
+	RealTime bool
+}
+
+// ReplayTraffic feeds the incoming nodes in a recording into the client's node handlers without using the network.
+//
+// Nodes are handled synchronously in the order they were recorded, so all events caused by the recording
+// have been dispatched when this returns. Outgoing nodes in the recording are skipped. The client should not
+// be connected while replaying: anything the handlers try to send will fail with ErrNotConnected.
+func (cli *Client) ReplayTraffic(ctx context.Context, r io.Reader, opts ReplayOptions) error {
+	if cli == nil {
+		return ErrClientIsNil
+	} else if cli.IsConnected() {
+		return ErrAlreadyConnected
+	}
+	entries, err := ReadTrafficRecording(r)
+	if err != nil {
+		return err
+	}
+	var prevTime time.Time
+	for i, entry := range entries {
+		if entry.Direction != TrafficIncoming {
+			continue
+		}
+		if opts.RealTime && !prevTime.IsZero() {
+			select {
+			case <-time.After(entry.Time.Sub(prevTime)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		prevTime = entry.Time
+		node, err := entry.Node()
+		if err != nil {
+			return fmt.Errorf("failed to decode node in entry #%d: %w", i+1, err)
+		}
+		cli.recvLog.Debugf("(replay) %s", node.XMLString())
+		if node.Tag == "xmlstreamend" || cli.receiveResponse(node) {
+			continue
+		} else if handler, ok := cli.nodeHandlers[node.Tag]; ok {
+			handler(node)
+		} else if node.Tag != "ack" {
+			cli.Log.Debugf("Didn't handle replayed WhatsApp node %s", node.Tag)
+		}
+	}
+	return nil
+}
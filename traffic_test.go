@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	waBinary "github.com/pbribeiro/whatsmeow-mysql/binary"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+func recordTestNode(t *testing.T, cli *Client, dir TrafficDirection, node waBinary.Node) {
+	t.Helper()
+	data, err := waBinary.Marshal(node)
+	if err != nil {
+		t.Fatalf("failed to marshal node: %v", err)
+	}
+	cli.recordTraffic(dir, &node, data)
+}
+
+func TestTrafficRecordReplayRoundTrip(t *testing.T) {
+	sender := types.NewJID("1111", types.DefaultUserServer)
+	incoming := []waBinary.Node{
+		{Tag: "message", Attrs: waBinary.Attrs{"id": "3EB0A", "from": sender, "t": "1700000000"}, Content: []waBinary.Node{
+			{Tag: "enc", Attrs: waBinary.Attrs{"v": "2", "type": "msg"}, Content: []byte("ciphertext")},
+		}},
+		{Tag: "notification", Attrs: waBinary.Attrs{"id": "123", "type": "privacy_token"}, Content: []waBinary.Node{
+			{Tag: "value", Content: []byte("not a key")},
+		}},
+	}
+	outgoing := waBinary.Node{Tag: "iq", Attrs: waBinary.Attrs{"id": "1", "type": "set"}, Content: []waBinary.Node{
+		{Tag: "identity", Content: []byte("identity key")},
+		{Tag: "skey", Content: []waBinary.Node{
+			{Tag: "id", Content: []byte{0, 0, 1}},
+			{Tag: "value", Content: []byte("signed prekey")},
+			{Tag: "signature", Content: []byte("prekey signature")},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	recorder := &Client{Log: waLog.Noop}
+	recorder.SetTrafficRecorder(NewTrafficRecorder(&buf, TrafficRecorderOptions{RedactKeys: true}))
+	recordTestNode(t, recorder, TrafficIncoming, incoming[0])
+	recordTestNode(t, recorder, TrafficOutgoing, outgoing)
+	recordTestNode(t, recorder, TrafficIncoming, incoming[1])
+
+	entries, err := ReadTrafficRecording(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to read recording: %v", err)
+	} else if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	redacted, err := entries[1].Node()
+	if err != nil {
+		t.Fatalf("failed to decode outgoing node: %v", err)
+	}
+	skey := redacted.GetChildByTag("skey")
+	isZeroed := func(node waBinary.Node) bool {
+		content, _ := node.Content.([]byte)
+		return len(content) > 0 && bytes.Count(content, []byte{0}) == len(content)
+	}
+	if !isZeroed(redacted.GetChildByTag("identity")) || !isZeroed(skey.GetChildByTag("value")) || !isZeroed(skey.GetChildByTag("signature")) {
+		t.Errorf("expected key material to be redacted: %s", redacted.XMLString())
+	} else if isZeroed(skey.GetChildByTag("id")) {
+		t.Errorf("expected prekey ID not to be redacted: %s", redacted.XMLString())
+	}
+
+	var replayed []*waBinary.Node
+	replayer := &Client{Log: waLog.Noop, recvLog: waLog.Noop}
+	handler := func(node *waBinary.Node) {
+		replayed = append(replayed, node)
+	}
+	replayer.nodeHandlers = map[string]nodeHandler{"message": handler, "notification": handler, "iq": handler}
+	err = replayer.ReplayTraffic(context.Background(), bytes.NewReader(buf.Bytes()), ReplayOptions{})
+	if err != nil {
+		t.Fatalf("failed to replay recording: %v", err)
+	}
+	if len(replayed) != len(incoming) {
+		t.Fatalf("expected %d replayed nodes, got %d", len(incoming), len(replayed))
+	}
+	for i, node := range replayed {
+		if node.XMLString() != incoming[i].XMLString() {
+			t.Errorf("replayed node #%d doesn't match recorded node:\n%s\n%s", i+1, node.XMLString(), incoming[i].XMLString())
+		}
+	}
+}
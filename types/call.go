@@ -6,7 +6,10 @@
 
 package types
 
-import "time"
+import (
+	"net/netip"
+	"time"
+)
 
 type BasicCallMeta struct {
 	From        JID
@@ -19,3 +22,64 @@ type CallRemoteMeta struct {
 	RemotePlatform string // The platform of the caller's WhatsApp client
 	RemoteVersion  string // Version of the caller's WhatsApp client
 }
+
+// CallAudioCodec is an audio codec supported in a call.
+type CallAudioCodec struct {
+	Encoding string // The codec name, usually "opus"
+	Rate     int    // The sample rate in Hz
+}
+
+// CallVideoCodec is the video codec used in a video call.
+type CallVideoCodec struct {
+	Encoding string
+	Decoding string
+}
+
+// CallRelayEndpoint is a relay server that can be used to transport the media of a call.
+type CallRelayEndpoint struct {
+	RelayID int
+	TokenID int
+	// The address of the relay. This is the zero value if the address couldn't be parsed, in which case RawAddress is set.
+	Address    netip.AddrPort
+	RawAddress []byte
+	// The latency to the relay as reported in relaylatency messages. This is zero in offers.
+	Latency uint32
+}
+
+// CallRelay contains the relay servers and credentials for a call.
+type CallRelay struct {
+	UUID       string
+	Key        []byte
+	HopByHop   []byte         // The hop-by-hop key (hbh_key)
+	Tokens     map[int][]byte // Relay tokens by ID
+	AuthTokens map[int][]byte // Relay auth tokens by ID
+	Endpoints  []CallRelayEndpoint
+}
+
+// CallOfferData contains the parsed contents of a call offer.
+type CallOfferData struct {
+	// The decrypted key that the media encryption keys are derived from.
+	// This is only set if Client.DecryptCallKeys is enabled. It's nil if the offer didn't contain a key for this device
+	// or decrypting it failed.
+	CallKey []byte
+
+	Audio []CallAudioCodec
+	// The video codec, or nil if this is an audio call.
+	Video *CallVideoCodec
+
+	NetMedium         int
+	KeyGenVersion     int // The keygen attribute of the encopt element
+	Capability        []byte
+	CapabilityVersion int
+
+	// The relays offered for the call. This may be nil, in which case the relays are sent later.
+	Relay *CallRelay
+
+	Joinable bool
+	GroupJID JID
+}
+
+// IsVideo returns true if the offer is for a video call.
+func (cod *CallOfferData) IsVideo() bool {
+	return cod.Video != nil
+}
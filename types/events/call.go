@@ -16,7 +16,8 @@ type CallOffer struct {
 	types.BasicCallMeta
 	types.CallRemoteMeta
 
-	Data *waBinary.Node // The call offer data
+	Data  *waBinary.Node       // The call offer data
+	Offer *types.CallOfferData // The parsed contents of Data
 }
 
 // CallAccept is emitted when a call is accepted on WhatsApp.
@@ -55,7 +56,8 @@ type CallOfferNotice struct {
 // CallRelayLatency is emitted slightly after the user receives a call on WhatsApp.
 type CallRelayLatency struct {
 	types.BasicCallMeta
-	Data *waBinary.Node
+	Endpoints []types.CallRelayEndpoint // The relays and their measured latencies
+	Data      *waBinary.Node
 }
 
 // CallTerminate is emitted when the other party terminates a call on WhatsApp.
@@ -71,6 +73,36 @@ type CallReject struct {
 	Data *waBinary.Node
 }
 
+type CallPolicyAction string
+
+const (
+	CallPolicyAllowed  CallPolicyAction = "allowed"  // The call was left to ring.
+	CallPolicyRejected CallPolicyAction = "rejected" // The call was rejected automatically.
+)
+
+type CallPolicyReason string
+
+const (
+	CallPolicyReasonAllowlisted          CallPolicyReason = "allowlisted"            // The caller is in the allowlist.
+	CallPolicyReasonBusinessHours        CallPolicyReason = "business_hours"         // The call was received during business hours.
+	CallPolicyReasonOutsideBusinessHours CallPolicyReason = "outside_business_hours" // The call was received outside business hours.
+	CallPolicyReasonAlways               CallPolicyReason = "always"                 // No business hours are configured, so all calls are rejected.
+)
+
+// CallPolicyApplied is emitted after a call offer is handled according to Client.CallPolicy.
+type CallPolicyApplied struct {
+	types.BasicCallMeta
+	Action CallPolicyAction
+	Reason CallPolicyReason
+
+	// Whether the automatic text reply was sent.
+	Replied bool
+	// Whether the automatic reply was skipped, because another one was sent to the same caller recently.
+	ReplyRateLimited bool
+	// The error that occurred when checking business hours, rejecting the call or sending the reply, if any.
+	Error error
+}
+
 // UnknownCallEvent is emitted when a call element with unknown content is received.
 type UnknownCallEvent struct {
 	Node *waBinary.Node
@@ -263,6 +263,7 @@ const (
 // and it's decryptable, then it will be emitted as a normal Message event.
 //
 // The UndecryptableMessage event may also be repeated if the resent message is also undecryptable.
+// The outcome of the recovery is emitted as DecryptionRecovered or DecryptionGaveUp.
 type UndecryptableMessage struct {
 	Info types.MessageInfo
 
@@ -598,3 +599,117 @@ type NewsletterLiveUpdate struct {
 	Time     time.Time
 	Messages []*types.NewsletterMessage
 }
+
+// ScheduledMessageSent is emitted when a message scheduled with Client.ScheduleMessage has been sent successfully.
+type ScheduledMessageSent struct {
+	ID        types.MessageID
+	Chat      types.JID
+	Message   *waE2E.Message
+	Timestamp time.Time // The timestamp of the message from the server.
+}
+
+// ScheduledMessageFailed is emitted when sending a scheduled message fails permanently,
+// either because of an unrecoverable error or because it failed too many times.
+// The message is removed from the schedule after this event.
+type ScheduledMessageFailed struct {
+	ID       types.MessageID
+	Chat     types.JID
+	Message  *waE2E.Message
+	Attempts int
+	Error    error
+}
+
+// PollTallyChanged is emitted when a vote in a tracked poll is received and changes the results of the poll.
+//
+// Polls are tracked automatically when they're sent or received. Votes for polls that were created
+// before tracking was available can't be decrypted and don't emit this event.
+type PollTallyChanged struct {
+	types.PollTally
+	Voter    types.JID
+	Selected []string // The option names that the voter currently has selected. Empty if the voter removed their vote.
+	Vote     *Message // The poll update message that changed the tally.
+}
+
+type MessageStateChange string
+
+const (
+	MessageStateChangeEdit        MessageStateChange = "edit"
+	MessageStateChangeReaction    MessageStateChange = "reaction"
+	MessageStateChangeRevoke      MessageStateChange = "revoke"
+	MessageStateChangeDeleteForMe MessageStateChange = "delete_for_me"
+)
+
+// MessageStateChanged is emitted after a message is edited, reacted to, revoked or deleted for us,
+// and contains the new aggregated state of the message. See also Client.GetMessageState.
+type MessageStateChanged struct {
+	types.MessageState
+	Change MessageStateChange
+	// The message that caused the change. This is nil for deletions for us, which come from app state.
+	Source *Message
+}
+
+type DecryptionRecoveryMethod string
+
+const (
+	DecryptionRecoveredByResend DecryptionRecoveryMethod = "resend" // The sender resent the message after a retry receipt.
+	DecryptionRecoveredByPhone  DecryptionRecoveryMethod = "phone"  // The primary device sent a copy of the message.
+)
+
+// DecryptionRecovered is emitted when a message that previously failed to decrypt is received successfully.
+// The message itself is dispatched as a normal *Message before this event.
+type DecryptionRecovered struct {
+	types.DecryptionFailure
+	Method DecryptionRecoveryMethod
+}
+
+type DecryptionGaveUpReason string
+
+const (
+	DecryptionGaveUpRetriesExhausted DecryptionGaveUpReason = "retries_exhausted" // All retry receipts were sent and requesting from the phone is disabled.
+	DecryptionGaveUpTimeout          DecryptionGaveUpReason = "timeout"           // The message wasn't recovered within whatsmeow.DecryptionRecoveryTimeout.
+)
+
+// DecryptionGaveUp is emitted when a message that failed to decrypt (see UndecryptableMessage) won't be recovered.
+type DecryptionGaveUp struct {
+	types.DecryptionFailure
+	Reason DecryptionGaveUpReason
+}
+
+// MessageStatusChanged is emitted when a message sent by us is delivered to, read or played by a recipient
+// for the first time, and contains the new aggregated receipts of the message. See also Client.GetMessageStatus.
+type MessageStatusChanged struct {
+	types.MessageStatus
+	Recipient types.JID
+	Type      types.ReceiptType
+	// The receipt that caused the change.
+	Receipt *Receipt
+}
+
+// MessageExpired is emitted when the disappearing timer of a received or sent message runs out.
+// Clients should delete the message from their own storage when receiving this event.
+type MessageExpired struct {
+	types.ExpiringMessage
+}
+
+type ViewOnceMediaAction string
+
+const (
+	ViewOnceMediaDownloaded ViewOnceMediaAction = "downloaded" // The media was downloaded into memory.
+	ViewOnceMediaOpened     ViewOnceMediaAction = "opened"     // The media was handed out to the app.
+	ViewOnceMediaExpired    ViewOnceMediaAction = "expired"    // The media was discarded without being opened, because it wasn't opened in time.
+	ViewOnceMediaDiscarded  ViewOnceMediaAction = "discarded"  // The media was discarded by the app without being opened.
+)
+
+// ViewOnceMediaAccess is emitted whenever view-once media downloaded with Client.DownloadViewOnce is accessed.
+// It is meant for compliance logging, so it only contains metadata and never the media itself.
+type ViewOnceMediaAccess struct {
+	Info     types.MessageInfo
+	Action   ViewOnceMediaAction
+	MimeType string
+	Size     int
+
+	// The receipt type sent after the media was opened, or empty if no receipt was sent.
+	Receipt types.ReceiptType
+	// The error that occurred when sending the receipt, if any.
+	Error error
+}
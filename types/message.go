@@ -96,3 +96,128 @@ func (ms *MessageSource) SourceString() string {
 		return ms.Chat.String()
 	}
 }
+
+// PollOptionTally contains the users who currently have a poll option selected.
+type PollOptionTally struct {
+	Name   string
+	Voters []JID
+}
+
+// PollTally contains the current results of a poll.
+type PollTally struct {
+	Chat    JID
+	Sender  JID
+	ID      MessageID
+	Name    string
+	Options []PollOptionTally
+}
+
+// MessageReaction is the current reaction of a single user to a message.
+type MessageReaction struct {
+	Reactor   JID
+	Emoji     string
+	Timestamp time.Time
+}
+
+// MessageState contains the changes that have been made to a message after it was sent.
+type MessageState struct {
+	Chat   JID
+	Sender JID
+	ID     MessageID
+
+	// The latest text or caption of the message, if it has been edited.
+	EditedText string
+	EditedAt   time.Time
+
+	Reactions []MessageReaction
+
+	Revoked      bool // Whether the message was deleted for everyone.
+	DeletedForMe bool // Whether the message was deleted for us on another device.
+}
+
+// IsEdited returns true if the message has been edited.
+func (ms *MessageState) IsEdited() bool {
+	return !ms.EditedAt.IsZero()
+}
+
+// DecryptionFailureCause is the reason why an incoming message couldn't be decrypted.
+type DecryptionFailureCause string
+
+const (
+	DecryptionFailureUnavailable       DecryptionFailureCause = "unavailable"        // The sender didn't include a ciphertext for this device.
+	DecryptionFailureNoSession         DecryptionFailureCause = "no_session"         // There's no Signal session with the sender device.
+	DecryptionFailureNoSenderKey       DecryptionFailureCause = "no_sender_key"      // The sender key for the group hasn't been received.
+	DecryptionFailureBadMAC            DecryptionFailureCause = "bad_mac"            // The ciphertext failed verification, usually because the session is out of sync.
+	DecryptionFailureUntrustedIdentity DecryptionFailureCause = "untrusted_identity" // The sender's identity key changed.
+	DecryptionFailureOther             DecryptionFailureCause = "other"
+)
+
+// DecryptionFailure contains the recovery state of an incoming message that failed to decrypt.
+type DecryptionFailure struct {
+	Chat   JID
+	Sender JID
+	ID     MessageID
+
+	// The cause of the first failure.
+	Cause DecryptionFailureCause
+	// When the message first failed to decrypt.
+	FailedAt time.Time
+	// How many times the message failed to decrypt, including resent copies.
+	Failures int
+	// How many retry receipts were sent to the sender.
+	RetryReceipts int
+	// Whether the message was requested from our primary device.
+	PhoneRequested bool
+}
+
+// DecryptionRecoveryStats contains counters of undecryptable messages with a single failure cause.
+type DecryptionRecoveryStats struct {
+	Failed    int // The number of messages that failed to decrypt.
+	Recovered int // The number of messages that were received successfully after failing.
+	GaveUp    int // The number of messages that won't be recovered.
+}
+
+// MessageRecipientStatus contains the receipts of a sent message from a single recipient.
+// Each timestamp is zero until the corresponding receipt is received from any of the recipient's devices.
+type MessageRecipientStatus struct {
+	User        JID
+	DeliveredAt time.Time
+	ReadAt      time.Time
+	PlayedAt    time.Time
+}
+
+// MessageStatus contains the aggregated receipts of a message sent by us.
+type MessageStatus struct {
+	Chat   JID
+	ID     MessageID
+	SentAt time.Time
+
+	// The users the message was sent to, not including ourselves.
+	Recipients []MessageRecipientStatus
+}
+
+// Counts returns the number of recipients who the message has been delivered to, who have read it and who have played it.
+//
+// Read and played receipts imply delivery, so read recipients are also counted as delivered.
+func (ms *MessageStatus) Counts() (delivered, read, played int) {
+	for _, recipient := range ms.Recipients {
+		if !recipient.DeliveredAt.IsZero() {
+			delivered++
+		}
+		if !recipient.ReadAt.IsZero() {
+			read++
+		}
+		if !recipient.PlayedAt.IsZero() {
+			played++
+		}
+	}
+	return
+}
+
+// ExpiringMessage is a reference to a disappearing message and the time when it disappears.
+type ExpiringMessage struct {
+	Chat      JID
+	Sender    JID
+	ID        MessageID
+	ExpiresAt time.Time
+}
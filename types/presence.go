@@ -8,6 +8,7 @@ package types
 
 import (
 	"fmt"
+	"time"
 )
 
 type Presence string
@@ -31,6 +32,24 @@ const (
 	ChatPresenceMediaAudio ChatPresenceMedia = "audio"
 )
 
+// UserPresence contains the latest known presence of a user.
+type UserPresence struct {
+	JID    JID
+	Online bool
+	// The time when the user was last online. This is zero if the user has hidden their last seen time.
+	LastSeen time.Time
+	// The time when the presence update was received.
+	UpdatedAt time.Time
+}
+
+// TypingState contains the chat presence of a user who is currently typing or recording audio in a chat.
+type TypingState struct {
+	Chat      JID
+	Sender    JID
+	Media     ChatPresenceMedia
+	UpdatedAt time.Time
+}
+
 // ReceiptType represents the type of a Receipt event.
 type ReceiptType string
 
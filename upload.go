@@ -65,11 +65,23 @@ type UploadResponse struct {
 //	// handle error again
 //
 // The same applies to the other message types like DocumentMessage, just replace the struct type and Message field name.
+//
+// If Client.MediaCache is set, uploading the same file again reuses the previous upload as long as it hasn't expired.
 func (cli *Client) Upload(ctx context.Context, plaintext []byte, appInfo MediaType) (resp UploadResponse, err error) {
+	plaintextSHA256 := sha256.Sum256(plaintext)
+	if cli.MediaCache != nil {
+		if cached := cli.getCachedUpload(ctx, appInfo, plaintextSHA256[:]); cached != nil {
+			return cached.UploadResponse, nil
+		}
+		defer func() {
+			if err == nil {
+				cli.cacheUpload(ctx, appInfo, resp)
+			}
+		}()
+	}
+
 	resp.FileLength = uint64(len(plaintext))
 	resp.MediaKey = random.Bytes(32)
-
-	plaintextSHA256 := sha256.Sum256(plaintext)
 	resp.FileSHA256 = plaintextSHA256[:]
 
 	iv, cipherKey, macKey, _ := getMediaKeys(resp.MediaKey, appInfo)
@@ -100,6 +112,8 @@ func (cli *Client) Upload(ctx context.Context, plaintext []byte, appInfo MediaTy
 // and deleted after the upload.
 //
 // To use only one file, pass the same file as both plaintext and tempFile. This will cause the file to be overwritten with encrypted data.
+//
+// If the plaintext is seekable, [UploadStream] can be used instead to avoid the temporary file entirely.
 func (cli *Client) UploadReader(ctx context.Context, plaintext io.Reader, tempFile io.ReadWriteSeeker, appInfo MediaType) (resp UploadResponse, err error) {
 	resp.MediaKey = random.Bytes(32)
 	iv, cipherKey, macKey, _ := getMediaKeys(resp.MediaKey, appInfo)
@@ -173,6 +187,7 @@ func (cli *Client) UploadNewsletter(ctx context.Context, data []byte, appInfo Me
 // This is otherwise identical to [UploadNewsletter], but it reads the plaintext from an [io.Reader] instead of a byte slice.
 // Unlike [UploadReader], this does not require a temporary file. However, the data needs to be hashed first,
 // so an [io.ReadSeeker] is required to be able to read the data twice.
+// See [UploadNewsletterStream] for a variant with progress reporting and retries.
 func (cli *Client) UploadNewsletterReader(ctx context.Context, data io.ReadSeeker, appInfo MediaType) (resp UploadResponse, err error) {
 	hasher := sha256.New()
 	var fileLength int64
@@ -0,0 +1,175 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"go.mau.fi/util/random"
+
+	"github.com/pbribeiro/whatsmeow-mysql/util/cbcutil"
+)
+
+// ErrUploadSourceChanged is returned by UploadStream if the data read from the source was different on the upload pass than on the hashing pass.
+var ErrUploadSourceChanged = errors.New("upload source changed while uploading")
+
+// UploadProgressFunc is called periodically while uploading with the number of bytes sent so far and the total upload size.
+type UploadProgressFunc func(sent, total uint64)
+
+// StreamUploadOptions contains optional parameters for UploadStream and UploadNewsletterStream.
+type StreamUploadOptions struct {
+	// Called as the upload progresses. If the upload is retried, the progress starts again from zero.
+	Progress UploadProgressFunc
+	// How many times to retry the upload after a failure. Defaults to 2 retries. Set to a negative value to disable retries.
+	MaxRetries int
+	// How long to wait before the first retry. The delay is doubled after each retry. Defaults to 2 seconds.
+	RetryDelay time.Duration
+}
+
+type progressReader struct {
+	r        io.Reader
+	sent     uint64
+	total    uint64
+	callback UploadProgressFunc
+}
+
+func (pr *progressReader) Read(p []byte) (n int, err error) {
+	n, err = pr.r.Read(p)
+	if n > 0 {
+		pr.sent += uint64(n)
+		pr.callback(pr.sent, pr.total)
+	}
+	return
+}
+
+// UploadStream uploads the given attachment to WhatsApp servers without holding the file in memory or writing it to disk.
+//
+// The result is the same as with [Upload] and [UploadReader]. The upload URL has to include the hash of the encrypted file
+// as the token, so the hash must be known before the first byte is sent, and it can't be sent after the body like the MAC.
+// Because of this, the plaintext is read twice: first to compute the hashes, then to encrypt it again on the fly while
+// it's sent. The reader must be seekable (e.g. an [os.File]) and must not change during the upload.
+// For non-seekable readers, use [UploadReader], which writes the encrypted file to a temporary file instead.
+//
+// If the upload fails, it's retried by seeking back to the start, re-encrypting and re-sending the file.
+func (cli *Client) UploadStream(ctx context.Context, plaintext io.ReadSeeker, appInfo MediaType, opts StreamUploadOptions) (resp UploadResponse, err error) {
+	resp.MediaKey = random.Bytes(32)
+	iv, cipherKey, macKey, _ := getMediaKeys(resp.MediaKey, appInfo)
+
+	encrypt := func() (*cbcutil.EncryptingReader, error) {
+		_, err := plaintext.Seek(0, io.SeekStart)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seek to start of file: %w", err)
+		}
+		return cbcutil.NewEncryptingReader(cipherKey, iv, macKey, plaintext)
+	}
+
+	hashPass, err := encrypt()
+	if err != nil {
+		return
+	}
+	_, err = io.Copy(io.Discard, hashPass)
+	if err != nil {
+		err = fmt.Errorf("failed to encrypt file: %w", err)
+		return
+	}
+	resp.FileSHA256 = hashPass.FileSHA256()
+	resp.FileEncSHA256 = hashPass.FileEncSHA256()
+	resp.FileLength = hashPass.FileLength()
+
+	var uploadPass *cbcutil.EncryptingReader
+	err = cli.uploadStreamWithRetries(ctx, appInfo, false, hashPass.EncryptedLength(), resp.FileEncSHA256, opts, &resp, func() (io.Reader, error) {
+		var encryptErr error
+		uploadPass, encryptErr = encrypt()
+		return uploadPass, encryptErr
+	}, func() []byte {
+		return uploadPass.FileEncSHA256()
+	})
+	return
+}
+
+// UploadNewsletterStream uploads the given attachment to WhatsApp servers without encrypting it first,
+// and without holding the file in memory.
+//
+// This is otherwise identical to [UploadNewsletterReader], but it reports progress and retries failed uploads
+// like [UploadStream]. The data is read twice, as the upload URL has to include the hash of the file.
+func (cli *Client) UploadNewsletterStream(ctx context.Context, data io.ReadSeeker, appInfo MediaType, opts StreamUploadOptions) (resp UploadResponse, err error) {
+	_, err = data.Seek(0, io.SeekStart)
+	if err != nil {
+		err = fmt.Errorf("failed to seek to start of file: %w", err)
+		return
+	}
+	hasher := sha256.New()
+	fileLength, err := io.Copy(hasher, data)
+	if err != nil {
+		err = fmt.Errorf("failed to hash file: %w", err)
+		return
+	}
+	resp.FileLength = uint64(fileLength)
+	resp.FileSHA256 = hasher.Sum(nil)
+
+	var uploadHasher hash.Hash
+	err = cli.uploadStreamWithRetries(ctx, appInfo, true, resp.FileLength, resp.FileSHA256, opts, &resp, func() (io.Reader, error) {
+		_, seekErr := data.Seek(0, io.SeekStart)
+		if seekErr != nil {
+			return nil, fmt.Errorf("failed to seek to start of file: %w", seekErr)
+		}
+		uploadHasher = sha256.New()
+		return io.TeeReader(io.LimitReader(data, fileLength), uploadHasher), nil
+	}, func() []byte {
+		return uploadHasher.Sum(nil)
+	})
+	return
+}
+
+// openUploadBodyFunc is called before every upload attempt to get a reader for the whole upload body from the start.
+type openUploadBodyFunc func() (io.Reader, error)
+
+// uploadStreamWithRetries uploads the body returned by open, retrying with a new body if the upload fails.
+// After a successful upload, the hash returned by uploadedHash must match fileHash, otherwise ErrUploadSourceChanged is returned.
+func (cli *Client) uploadStreamWithRetries(ctx context.Context, appInfo MediaType, newsletter bool, uploadSize uint64, fileHash []byte, opts StreamUploadOptions, resp *UploadResponse, open openUploadBodyFunc, uploadedHash func() []byte) (err error) {
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 2
+	} else if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = 2 * time.Second
+	}
+	retryDelay := opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		var body io.Reader
+		body, err = open()
+		if err != nil {
+			return
+		}
+		if opts.Progress != nil {
+			body = &progressReader{r: body, total: uploadSize, callback: opts.Progress}
+		}
+		err = cli.rawUpload(ctx, body, uploadSize, fileHash, appInfo, newsletter, resp)
+		if err == nil && !bytes.Equal(uploadedHash(), fileHash) {
+			err = ErrUploadSourceChanged
+		}
+		if err == nil || errors.Is(err, ErrUploadSourceChanged) || ctx.Err() != nil || attempt >= opts.MaxRetries {
+			return
+		}
+		cli.Log.Warnf("Streaming upload of %s failed (attempt #%d): %v, retrying in %s", appInfo, attempt+1, err, retryDelay)
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+		retryDelay *= 2
+	}
+}
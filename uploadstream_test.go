@@ -0,0 +1,137 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mau.fi/util/random"
+
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+// fakeMediaServer accepts uploads, but drops the connection after receiving cutAfter bytes of the first upload.
+type fakeMediaServer struct {
+	t        *testing.T
+	cutAfter int
+
+	lock      sync.Mutex
+	paths     []string
+	uploads   []int
+	received  []byte
+	hasCutOff bool
+}
+
+func (fms *fakeMediaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fms.lock.Lock()
+	defer fms.lock.Unlock()
+	fms.paths = append(fms.paths, r.URL.Path)
+	if !fms.hasCutOff && fms.cutAfter > 0 {
+		fms.hasCutOff = true
+		partial := make([]byte, fms.cutAfter)
+		_, err := io.ReadFull(r.Body, partial)
+		if err != nil {
+			fms.t.Errorf("failed to read first part of upload: %v", err)
+		}
+		fms.uploads = append(fms.uploads, len(partial))
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			fms.t.Fatalf("failed to hijack connection: %v", err)
+		}
+		_ = conn.Close()
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		fms.t.Errorf("failed to read upload: %v", err)
+	}
+	fms.received = body
+	fms.uploads = append(fms.uploads, len(body))
+	_ = json.NewEncoder(w).Encode(map[string]any{"url": "https://example.com/file", "direct_path": "/v/t62/file", "handle": "handle"})
+}
+
+func newFakeMediaClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	return &Client{
+		Log:  waLog.Noop,
+		http: server.Client(),
+		mediaConnCache: &MediaConn{
+			Auth:      "auth",
+			TTL:       3600,
+			FetchedAt: time.Now(),
+			Hosts:     []MediaConnHost{{Hostname: server.Listener.Addr().String()}},
+		},
+	}
+}
+
+func TestUploadStreamRetries(t *testing.T) {
+	fms := &fakeMediaServer{t: t, cutAfter: 30000}
+	cli := newFakeMediaClient(t, fms)
+	var lastSent, lastTotal uint64
+	resp, err := cli.UploadStream(context.Background(), bytes.NewReader(random.Bytes(100000)), MediaDocument, StreamUploadOptions{
+		RetryDelay: time.Millisecond,
+		Progress: func(sent, total uint64) {
+			lastSent, lastTotal = sent, total
+		},
+	})
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if resp.DirectPath != "/v/t62/file" {
+		t.Errorf("unexpected direct path %q", resp.DirectPath)
+	}
+	if hash := sha256.Sum256(fms.received); !bytes.Equal(hash[:], resp.FileEncSHA256) {
+		t.Error("data received by server doesn't match encrypted file hash")
+	}
+	if len(fms.uploads) != 2 || fms.uploads[1] != len(fms.received) {
+		t.Errorf("expected the whole file to be uploaded again after the failure, got upload sizes %v", fms.uploads)
+	}
+	if lastSent != lastTotal || lastTotal != uint64(len(fms.received)) {
+		t.Errorf("expected final progress to be complete, got %d/%d", lastSent, lastTotal)
+	}
+}
+
+func TestUploadNewsletterStream(t *testing.T) {
+	fms := &fakeMediaServer{t: t, cutAfter: 30000}
+	cli := newFakeMediaClient(t, fms)
+	data := random.Bytes(100000)
+	resp, err := cli.UploadNewsletterStream(context.Background(), bytes.NewReader(data), MediaImage, StreamUploadOptions{
+		RetryDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if !bytes.Equal(fms.received, data) {
+		t.Error("expected newsletter media to be uploaded unencrypted")
+	}
+	if hash := sha256.Sum256(data); !bytes.Equal(hash[:], resp.FileSHA256) || resp.FileLength != uint64(len(data)) {
+		t.Error("unexpected file hash or length in response")
+	}
+	if resp.MediaKey != nil || resp.FileEncSHA256 != nil {
+		t.Error("expected newsletter upload to not have encryption fields")
+	}
+	if resp.Handle != "handle" {
+		t.Errorf("expected media handle in response, got %q", resp.Handle)
+	}
+	for _, path := range fms.paths {
+		if !strings.HasPrefix(path, "/newsletter/newsletter-image/") {
+			t.Errorf("expected newsletter upload path, got %s", path)
+		}
+	}
+}
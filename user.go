@@ -163,7 +163,13 @@ func (cli *Client) SetStatusMessage(msg string) error {
 
 // IsOnWhatsApp checks if the given phone numbers are registered on WhatsApp.
 // The phone numbers should be in international format, including the `+` prefix.
+//
+// If Client.RateLimiter is set, this waits until it allows another query.
+// If that would take longer than RateLimitConfig.MaxQueryWait, ErrRateLimited is returned instead.
 func (cli *Client) IsOnWhatsApp(phones []string) ([]types.IsOnWhatsAppResponse, error) {
+	if err := cli.waitRateLimit(context.TODO()); err != nil {
+		return nil, err
+	}
 	jids := make([]types.JID, len(phones))
 	for i := range jids {
 		jids[i] = types.NewJID(phones[i], types.LegacyUserServer)
@@ -424,6 +430,10 @@ func (cli *Client) GetUserDevices(jids []types.JID) ([]types.JID, error) {
 	return cli.GetUserDevicesContext(context.Background(), jids)
 }
 
+// deviceListChunkSize is the maximum number of users whose devices are requested in a single usync query.
+// Larger lists (e.g. status broadcasts to all contacts) are split into multiple queries to avoid timeouts.
+const deviceListChunkSize = 500
+
 func (cli *Client) GetUserDevicesContext(ctx context.Context, jids []types.JID) ([]types.JID, error) {
 	if cli == nil {
 		return nil, ErrClientIsNil
@@ -445,8 +455,8 @@ func (cli *Client) GetUserDevicesContext(ctx context.Context, jids []types.JID)
 			jidsToSync = append(jidsToSync, jid)
 		}
 	}
-	if len(jidsToSync) > 0 {
-		list, err := cli.usync(ctx, jidsToSync, "query", "message", []waBinary.Node{
+	for chunk := range slices.Chunk(jidsToSync, deviceListChunkSize) {
+		list, err := cli.usync(ctx, chunk, "query", "message", []waBinary.Node{
 			{Tag: "devices", Attrs: waBinary.Attrs{"version": "2"}},
 		})
 		if err != nil {
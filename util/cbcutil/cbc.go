@@ -23,6 +23,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 )
@@ -215,3 +216,120 @@ func EncryptStream(key, iv, macKey []byte, plaintext io.Reader, ciphertext io.Wr
 	}
 	return plainHasher.Sum(nil), cipherHasher.Sum(nil), uint64(size), uint64(size + extraSize), nil
 }
+
+/*
+EncryptingReader encrypts data from a plaintext reader on the fly, in the same format as EncryptStream:
+the AES-256-CBC ciphertext followed by the first 10 bytes of the HMAC-SHA256 of the IV and ciphertext.
+
+The hashes and sizes are only available after the reader has returned io.EOF.
+*/
+type EncryptingReader struct {
+	plaintext io.Reader
+	cbc       cipher.BlockMode
+
+	plainHasher  hash.Hash
+	cipherHasher hash.Hash
+	cipherMAC    hash.Hash
+
+	buf     []byte
+	pending []byte
+	size    uint64
+	encSize uint64
+	done    bool
+	err     error
+}
+
+/*
+NewEncryptingReader creates a reader that returns the encrypted form of the given plaintext.
+*/
+func NewEncryptingReader(key, iv, macKey []byte, plaintext io.Reader) (*EncryptingReader, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	er := &EncryptingReader{
+		plaintext:    plaintext,
+		cbc:          cipher.NewCBCEncrypter(block, iv),
+		plainHasher:  sha256.New(),
+		cipherHasher: sha256.New(),
+		cipherMAC:    hmac.New(sha256.New, macKey),
+		buf:          make([]byte, 32*1024),
+	}
+	er.cipherMAC.Write(iv)
+	return er, nil
+}
+
+func (er *EncryptingReader) fill() error {
+	n, err := io.ReadFull(er.plaintext, er.buf)
+	er.plainHasher.Write(er.buf[:n])
+	er.size += uint64(n)
+	chunk := er.buf[:n]
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		padding := aes.BlockSize - int(er.size%aes.BlockSize)
+		chunk = append(chunk, bytes.Repeat([]byte{byte(padding)}, padding)...)
+		er.done = true
+	} else if err != nil {
+		return fmt.Errorf("failed to read plaintext: %w", err)
+	}
+	er.cbc.CryptBlocks(chunk, chunk)
+	er.cipherMAC.Write(chunk)
+	if er.done {
+		chunk = append(chunk, er.cipherMAC.Sum(nil)[:10]...)
+	}
+	er.cipherHasher.Write(chunk)
+	er.encSize += uint64(len(chunk))
+	er.pending = chunk
+	return nil
+}
+
+func (er *EncryptingReader) Read(p []byte) (int, error) {
+	if er.err != nil {
+		return 0, er.err
+	}
+	for len(er.pending) == 0 {
+		if er.done {
+			return 0, io.EOF
+		}
+		if er.err = er.fill(); er.err != nil {
+			return 0, er.err
+		}
+	}
+	n := copy(p, er.pending)
+	er.pending = er.pending[n:]
+	return n, nil
+}
+
+/*
+FileSHA256 returns the SHA-256 hash of the plaintext. Only valid after io.EOF.
+*/
+func (er *EncryptingReader) FileSHA256() []byte {
+	return er.plainHasher.Sum(nil)
+}
+
+/*
+FileEncSHA256 returns the SHA-256 hash of the ciphertext including the MAC. Only valid after io.EOF.
+*/
+func (er *EncryptingReader) FileEncSHA256() []byte {
+	return er.cipherHasher.Sum(nil)
+}
+
+/*
+FileLength returns the number of plaintext bytes read so far.
+*/
+func (er *EncryptingReader) FileLength() uint64 {
+	return er.size
+}
+
+/*
+EncryptedLength returns the number of ciphertext bytes produced so far, which is the full upload size after io.EOF.
+*/
+func (er *EncryptingReader) EncryptedLength() uint64 {
+	return er.encSize
+}
+
+/*
+EncryptedSize returns the size of the encrypted form of a plaintext of the given size, including padding and the MAC.
+*/
+func EncryptedSize(plaintextSize uint64) uint64 {
+	return plaintextSize + aes.BlockSize - plaintextSize%aes.BlockSize + 10
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+)
+
+// DefaultViewOnceMediaTTL is how long media downloaded with DownloadViewOnce is kept in memory
+// if ViewOncePolicy.MediaTTL is not set.
+const DefaultViewOnceMediaTTL = 5 * time.Minute
+
+// ViewOncePolicy configures how view-once media is handled by DownloadViewOnce. See Client.ViewOncePolicy.
+type ViewOncePolicy struct {
+	// How long downloaded media is kept in memory if it's not opened. Defaults to DefaultViewOnceMediaTTL.
+	MediaTTL time.Duration
+	// If true, opening media won't send a played receipt, so the sender won't see that the media was opened.
+	DisablePlayedReceipts bool
+}
+
+// ViewOnceMedia is a handle to view-once media that has been downloaded into memory.
+//
+// The media can only be read once with Open. If it isn't opened within the TTL of the policy, it's discarded.
+// The media is never written to disk or into Client.MediaCache.
+type ViewOnceMedia struct {
+	Info     types.MessageInfo
+	MimeType string
+	Size     int
+
+	cli    *Client
+	policy ViewOncePolicy
+
+	lock   sync.Mutex
+	data   []byte
+	opened bool
+	timer  *time.Timer
+}
+
+type viewOnceDownloadable interface {
+	GetViewOnce() bool
+}
+
+type downloadableMessageWithMimetype interface {
+	GetMimetype() string
+}
+
+// isViewOnceMedia checks if the given media is marked as view-once, which means it must not be cached.
+func isViewOnceMedia(msg DownloadableMessage) bool {
+	viewOnce, ok := msg.(viewOnceDownloadable)
+	return ok && viewOnce.GetViewOnce()
+}
+
+// markViewOnceMedia marks the media in a received view-once message as view-once. The view-once wrapper is removed
+// when unwrapping the event, so without this, downloading the inner media with Download, DownloadAny or
+// DownloadWithAutoRetry wouldn't know to bypass the media cache.
+func markViewOnceMedia(evt *events.Message) {
+	if !evt.IsViewOnce {
+		return
+	}
+	switch {
+	case evt.Message.GetImageMessage() != nil:
+		evt.Message.ImageMessage.ViewOnce = proto.Bool(true)
+	case evt.Message.GetVideoMessage() != nil:
+		evt.Message.VideoMessage.ViewOnce = proto.Bool(true)
+	case evt.Message.GetAudioMessage() != nil:
+		evt.Message.AudioMessage.ViewOnce = proto.Bool(true)
+	}
+}
+
+func getViewOnceDownloadable(msg *waE2E.Message) DownloadableMessage {
+	switch {
+	case msg.ImageMessage != nil:
+		return msg.ImageMessage
+	case msg.VideoMessage != nil:
+		return msg.VideoMessage
+	case msg.AudioMessage != nil:
+		return msg.AudioMessage
+	default:
+		return nil
+	}
+}
+
+// DownloadViewOnce downloads the media in a view-once message into an in-memory handle that can only be opened once.
+//
+// The media cache is bypassed both when reading and writing, so the media is only ever stored in the returned handle.
+// When the media is opened, a played receipt is sent to the sender like official clients do (unless disabled in
+// Client.ViewOncePolicy). An events.ViewOnceMediaAccess is dispatched when the media is downloaded, opened or discarded,
+// which can be used for compliance logging.
+func (cli *Client) DownloadViewOnce(evt *events.Message) (*ViewOnceMedia, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	} else if !evt.IsViewOnce {
+		return nil, ErrNotViewOnceMessage
+	}
+	msg := getViewOnceDownloadable(evt.Message)
+	if msg == nil {
+		return nil, ErrNothingDownloadableFound
+	}
+	data, err := cli.download(msg, GetMediaType(msg))
+	if err != nil {
+		return nil, err
+	}
+	var policy ViewOncePolicy
+	if cli.ViewOncePolicy != nil {
+		policy = *cli.ViewOncePolicy
+	}
+	if policy.MediaTTL == 0 {
+		policy.MediaTTL = DefaultViewOnceMediaTTL
+	}
+	media := &ViewOnceMedia{
+		Info:   evt.Info,
+		Size:   len(data),
+		cli:    cli,
+		policy: policy,
+		data:   data,
+	}
+	if mimetyped, ok := msg.(downloadableMessageWithMimetype); ok {
+		media.MimeType = mimetyped.GetMimetype()
+	}
+	media.startExpiryTimer()
+	media.dispatchAccess(events.ViewOnceMediaDownloaded, "", nil)
+	return media, nil
+}
+
+// Open returns the media and removes it from the handle. Subsequent calls will return ErrViewOnceMediaOpened.
+//
+// If the message wasn't sent by the current user, a played receipt is sent before returning.
+// Failing to send the receipt doesn't prevent the media from being returned, but it's included in the access event.
+func (vom *ViewOnceMedia) Open() ([]byte, error) {
+	vom.lock.Lock()
+	if vom.opened {
+		vom.lock.Unlock()
+		return nil, ErrViewOnceMediaOpened
+	} else if vom.data == nil {
+		vom.lock.Unlock()
+		return nil, ErrViewOnceMediaExpired
+	}
+	data := vom.data
+	vom.data = nil
+	vom.opened = true
+	vom.timer.Stop()
+	vom.lock.Unlock()
+
+	var receiptType types.ReceiptType
+	var err error
+	if !vom.Info.IsFromMe && !vom.policy.DisablePlayedReceipts {
+		receiptType = types.ReceiptTypePlayed
+		err = vom.cli.MarkRead([]types.MessageID{vom.Info.ID}, time.Now(), vom.Info.Chat, vom.Info.Sender, receiptType)
+		if err != nil {
+			vom.cli.Log.Warnf("Failed to send played receipt for view-once message %s: %v", vom.Info.ID, err)
+		}
+	}
+	vom.dispatchAccess(events.ViewOnceMediaOpened, receiptType, err)
+	return data, nil
+}
+
+// Discard wipes the media from memory without opening it. It's safe to call Discard after Open or multiple times.
+func (vom *ViewOnceMedia) Discard() {
+	if vom.wipe() {
+		vom.dispatchAccess(events.ViewOnceMediaDiscarded, "", nil)
+	}
+}
+
+func (vom *ViewOnceMedia) startExpiryTimer() {
+	vom.lock.Lock()
+	vom.timer = time.AfterFunc(vom.policy.MediaTTL, vom.expire)
+	vom.lock.Unlock()
+}
+
+func (vom *ViewOnceMedia) expire() {
+	if vom.wipe() {
+		vom.dispatchAccess(events.ViewOnceMediaExpired, "", nil)
+	}
+}
+
+// wipe zeroes the in-memory copy of the media and returns true if there was any media left.
+func (vom *ViewOnceMedia) wipe() bool {
+	vom.lock.Lock()
+	defer vom.lock.Unlock()
+	if vom.data == nil {
+		return false
+	}
+	clear(vom.data)
+	vom.data = nil
+	vom.timer.Stop()
+	return true
+}
+
+func (vom *ViewOnceMedia) dispatchAccess(action events.ViewOnceMediaAction, receipt types.ReceiptType, err error) {
+	vom.cli.dispatchEvent(&events.ViewOnceMediaAccess{
+		Info:     vom.Info,
+		Action:   action,
+		MimeType: vom.MimeType,
+		Size:     vom.Size,
+		Receipt:  receipt,
+		Error:    err,
+	})
+}
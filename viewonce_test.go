@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pbribeiro/whatsmeow-mysql/proto/waE2E"
+	"github.com/pbribeiro/whatsmeow-mysql/types"
+	"github.com/pbribeiro/whatsmeow-mysql/types/events"
+	waLog "github.com/pbribeiro/whatsmeow-mysql/util/log"
+)
+
+type viewOnceActionLog struct {
+	lock    sync.Mutex
+	actions []events.ViewOnceMediaAction
+}
+
+func (val *viewOnceActionLog) get() []events.ViewOnceMediaAction {
+	val.lock.Lock()
+	defer val.lock.Unlock()
+	return slices.Clone(val.actions)
+}
+
+func newTestViewOnceMedia(t *testing.T) (*ViewOnceMedia, *viewOnceActionLog) {
+	t.Helper()
+	cli := &Client{Log: waLog.Noop}
+	var log viewOnceActionLog
+	cli.AddEventHandler(func(evt any) {
+		access := evt.(*events.ViewOnceMediaAccess)
+		if access.Size != 5 || access.MimeType != "image/jpeg" {
+			t.Errorf("unexpected access event metadata: %+v", access)
+		}
+		log.lock.Lock()
+		log.actions = append(log.actions, access.Action)
+		log.lock.Unlock()
+	})
+	media := &ViewOnceMedia{
+		Info:     types.MessageInfo{MessageSource: types.MessageSource{IsFromMe: true}, ID: "3EB0ABCDEF"},
+		MimeType: "image/jpeg",
+		Size:     5,
+		cli:      cli,
+		policy:   ViewOncePolicy{MediaTTL: time.Minute},
+		data:     []byte("hello"),
+	}
+	media.startExpiryTimer()
+	return media, &log
+}
+
+func TestViewOnceMediaOpenOnce(t *testing.T) {
+	media, actions := newTestViewOnceMedia(t)
+	data, err := media.Open()
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("expected media on first open, got %q / %v", data, err)
+	}
+	if _, err = media.Open(); !errors.Is(err, ErrViewOnceMediaOpened) {
+		t.Errorf("expected ErrViewOnceMediaOpened on second open, got %v", err)
+	}
+	media.Discard()
+	if expected := []events.ViewOnceMediaAction{events.ViewOnceMediaOpened}; !slices.Equal(actions.get(), expected) {
+		t.Errorf("expected actions %v, got %v", expected, actions.get())
+	}
+}
+
+func TestViewOnceMediaExpiry(t *testing.T) {
+	media, actions := newTestViewOnceMedia(t)
+	data := media.data
+	media.expire()
+	if _, err := media.Open(); !errors.Is(err, ErrViewOnceMediaExpired) {
+		t.Errorf("expected ErrViewOnceMediaExpired, got %v", err)
+	}
+	if string(data) != "\x00\x00\x00\x00\x00" {
+		t.Errorf("expected media to be wiped, got %q", data)
+	}
+	if expected := []events.ViewOnceMediaAction{events.ViewOnceMediaExpired}; !slices.Equal(actions.get(), expected) {
+		t.Errorf("expected actions %v, got %v", expected, actions.get())
+	}
+}
+
+func TestMarkViewOnceMedia(t *testing.T) {
+	evt := (&events.Message{RawMessage: &waE2E.Message{ViewOnceMessageV2: &waE2E.FutureProofMessage{
+		Message: &waE2E.Message{ImageMessage: &waE2E.ImageMessage{}},
+	}}}).UnwrapRaw()
+	markViewOnceMedia(evt)
+	if !isViewOnceMedia(evt.Message.GetImageMessage()) {
+		t.Error("expected media from a view-once wrapper to be marked as view-once")
+	}
+	normal := (&events.Message{RawMessage: &waE2E.Message{ImageMessage: &waE2E.ImageMessage{}}}).UnwrapRaw()
+	markViewOnceMedia(normal)
+	if isViewOnceMedia(normal.Message.GetImageMessage()) {
+		t.Error("expected normal media not to be marked as view-once")
+	}
+}
+
+func TestIsViewOnceMedia(t *testing.T) {
+	if !isViewOnceMedia(&waE2E.ImageMessage{ViewOnce: proto.Bool(true)}) {
+		t.Error("expected view-once image to be detected")
+	}
+	if isViewOnceMedia(&waE2E.ImageMessage{}) || isViewOnceMedia(&waE2E.DocumentMessage{}) {
+		t.Error("expected normal media not to be detected as view-once")
+	}
+}